@@ -0,0 +1,118 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package archive
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const fileSuffix = ".json.gz"
+
+// blocks per sub directory
+const dirBlocks = 10000
+
+// BlockArchive everything fetched from the node for one block
+type BlockArchive struct {
+	Block    *xycommon.RpcBlock     `json:"block"`
+	Logs     []xycommon.RpcLog      `json:"logs"`
+	Receipts []*xycommon.RpcReceipt `json:"receipts"`
+}
+
+func blockPath(dir string, blockNum uint64) string {
+	return filepath.Join(dir, strconv.FormatUint(blockNum/dirBlocks, 10), strconv.FormatUint(blockNum, 10)+fileSuffix)
+}
+
+// parseBlockNum get block number from the archive file name
+func parseBlockNum(path string) (uint64, bool) {
+	name := filepath.Base(path)
+	if !strings.HasSuffix(name, fileSuffix) {
+		return 0, false
+	}
+
+	num, err := strconv.ParseUint(strings.TrimSuffix(name, fileSuffix), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return num, true
+}
+
+// readArchive load the block archive, returns nil if not exists
+func readArchive(dir string, blockNum uint64) (*BlockArchive, error) {
+	f, err := os.Open(blockPath(dir, blockNum))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("block[%d] archive gzip err:%v", blockNum, err)
+	}
+	defer zr.Close()
+
+	a := &BlockArchive{}
+	if err = json.NewDecoder(zr).Decode(a); err != nil {
+		return nil, fmt.Errorf("block[%d] archive decode err:%v", blockNum, err)
+	}
+	return a, nil
+}
+
+// writeArchive write the block archive into a temp file then rename it
+func writeArchive(dir string, blockNum uint64, a *BlockArchive) error {
+	path := blockPath(dir, blockNum)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(f)
+	if err = json.NewEncoder(zw).Encode(a); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err = zw.Close(); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package archive
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"testing"
+)
+
+func init() {
+	xylog.InitLog(logrus.DebugLevel, "")
+}
+
+type mockClient struct {
+	xycommon.IRPCClient
+	block   *xycommon.RpcBlock
+	logs    []xycommon.RpcLog
+	receipt *xycommon.RpcReceipt
+}
+
+func (m *mockClient) BlockByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcBlock, error) {
+	return m.block, nil
+}
+
+func (m *mockClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]xycommon.RpcLog, error) {
+	return m.logs, nil
+}
+
+func (m *mockClient) TransactionReceipt(ctx context.Context, txHash string) (*xycommon.RpcReceipt, error) {
+	return m.receipt, nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	txHash := common.HexToHash("0x01")
+	topic := common.HexToHash("0xaa")
+
+	mock := &mockClient{
+		block: &xycommon.RpcBlock{
+			Number: big.NewInt(100),
+			Hash:   common.HexToHash("0x64").String(),
+			Transactions: []*xycommon.RpcTransaction{
+				{Hash: txHash.String(), Input: "0x646174613a"},
+			},
+		},
+		logs: []xycommon.RpcLog{
+			{TxHash: txHash, Topics: []common.Hash{topic}, BlockNumber: (*hexutil.Big)(big.NewInt(100))},
+		},
+		receipt: &xycommon.RpcReceipt{TxHash: txHash, Status: big.NewInt(1), BlockNumber: big.NewInt(100)},
+	}
+
+	recorder, err := NewRecorder(mock, dir)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = recorder.BlockByNumber(ctx, big.NewInt(100))
+	assert.NoError(t, err)
+	_, err = recorder.FilterLogs(ctx, ethereum.FilterQuery{})
+	assert.NoError(t, err)
+	_, err = recorder.TransactionReceipt(ctx, txHash.String())
+	assert.NoError(t, err)
+
+	// nothing written till the block indexed
+	_, err = Open(dir)
+	assert.Error(t, err)
+	recorder.FinishBlock(100)
+
+	replay, err := Open(dir)
+	assert.NoError(t, err)
+
+	latest, _ := replay.BlockNumber(ctx)
+	assert.Equal(t, uint64(100), latest)
+
+	block, err := replay.BlockByNumber(ctx, big.NewInt(100))
+	assert.NoError(t, err)
+	assert.Equal(t, mock.block.Hash, block.Hash)
+	assert.Equal(t, "0x646174613a", block.Transactions[0].Input)
+
+	logs, err := replay.FilterLogs(ctx, ethereum.FilterQuery{
+		Topics:    [][]common.Hash{{topic}},
+		FromBlock: big.NewInt(99),
+		ToBlock:   big.NewInt(100),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1)
+
+	logs, err = replay.FilterLogs(ctx, ethereum.FilterQuery{
+		Topics:    [][]common.Hash{{common.HexToHash("0xbb")}},
+		FromBlock: big.NewInt(100),
+		ToBlock:   big.NewInt(100),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, logs, 0)
+
+	r, err := replay.TransactionReceipt(ctx, txHash.String())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), r.Status.Int64())
+
+	_, err = replay.BlockByNumber(ctx, big.NewInt(101))
+	assert.ErrorIs(t, err, xycommon.ErrNotFound)
+}
+
+func TestRecorderRollback(t *testing.T) {
+	dir := t.TempDir()
+	mock := &mockClient{
+		block: &xycommon.RpcBlock{Number: big.NewInt(100), Hash: common.HexToHash("0x64").String()},
+		receipt: &xycommon.RpcReceipt{
+			TxHash:      common.HexToHash("0x01"),
+			BlockHash:   common.HexToHash("0x65"),
+			BlockNumber: big.NewInt(100),
+		},
+	}
+
+	recorder, err := NewRecorder(mock, dir)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = recorder.BlockByNumber(ctx, big.NewInt(100))
+	assert.NoError(t, err)
+
+	// receipts of another block at the same height are dropped
+	_, err = recorder.TransactionReceipt(ctx, "0x01")
+	assert.NoError(t, err)
+	recorder.FinishBlock(100)
+
+	a, err := readArchive(dir, 100)
+	assert.NoError(t, err)
+	assert.Len(t, a.Receipts, 0)
+
+	recorder.RollbackBlocks(99)
+	a, err = readArchive(dir, 100)
+	assert.NoError(t, err)
+	assert.Nil(t, a)
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package archive
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Recorder
+/*****************************************************
+ * Wraps a live rpc client and records every fetched
+ * block, logs & receipts into compressed block files.
+ * the data of a block is buffered in memory and written
+ * once the block is indexed, see FinishBlock
+ ****************************************************/
+type Recorder struct {
+	xycommon.IRPCClient
+	dir     string
+	mu      sync.Mutex
+	pending map[uint64]*BlockArchive // blocks fetched but not yet indexed
+	written uint64                   // the last block archive written
+}
+
+func NewRecorder(c xycommon.IRPCClient, dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Recorder{IRPCClient: c, dir: dir, pending: make(map[uint64]*BlockArchive, 100)}, nil
+}
+
+// record apply the update to the buffered block archive
+func (r *Recorder) record(blockNum uint64, update func(a *BlockArchive)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.pending[blockNum]
+	if !ok {
+		a = &BlockArchive{}
+		r.pending[blockNum] = a
+	}
+	update(a)
+}
+
+// sameBlock check the data fetched belongs to the buffered block, empty hashes are unknown
+func sameBlock(a *BlockArchive, hash string) bool {
+	if a.Block == nil || hash == "" || hash == (common.Hash{}).String() {
+		return true
+	}
+	return strings.EqualFold(a.Block.Hash, hash)
+}
+
+// FinishBlock write the buffered archive of the indexed block into file
+func (r *Recorder) FinishBlock(blockNum uint64) {
+	r.mu.Lock()
+	a, ok := r.pending[blockNum]
+	for num := range r.pending {
+		// blocks before were dropped by a reorg or never indexed
+		if num <= blockNum {
+			delete(r.pending, num)
+		}
+	}
+	if ok && blockNum > r.written {
+		r.written = blockNum
+	}
+	r.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	if err := writeArchive(r.dir, blockNum, a); err != nil {
+		xylog.Logger.Errorf("record block[%d] archive, write err:%v", blockNum, err)
+	}
+}
+
+// RollbackBlocks drop the buffered & written archives above the block
+func (r *Recorder) RollbackBlocks(blockNum uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for num := range r.pending {
+		if num > blockNum {
+			delete(r.pending, num)
+		}
+	}
+
+	for num := blockNum + 1; num <= r.written; num++ {
+		if err := os.Remove(blockPath(r.dir, num)); err != nil && !os.IsNotExist(err) {
+			xylog.Logger.Errorf("remove block[%d] archive err:%v", num, err)
+		}
+	}
+	if r.written > blockNum {
+		r.written = blockNum
+	}
+}
+
+func (r *Recorder) BlockByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcBlock, error) {
+	block, err := r.IRPCClient.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	r.record(block.Number.Uint64(), func(a *BlockArchive) {
+		// fetched again after a reorg, the data of the orphaned block is dropped
+		if !sameBlock(a, block.Hash) {
+			*a = BlockArchive{}
+		}
+		a.Block = block
+	})
+	return block, nil
+}
+
+func (r *Recorder) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]xycommon.RpcLog, error) {
+	logs, err := r.IRPCClient.FilterLogs(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	blockLogs := make(map[uint64][]xycommon.RpcLog, 10)
+	for _, l := range logs {
+		num := l.BlockNumber.ToInt().Uint64()
+		blockLogs[num] = append(blockLogs[num], l)
+	}
+
+	for num, items := range blockLogs {
+		r.record(num, func(a *BlockArchive) {
+			if sameBlock(a, items[0].BlockHash.String()) {
+				a.Logs = items
+			}
+		})
+	}
+	return logs, nil
+}
+
+func (r *Recorder) TransactionReceipt(ctx context.Context, txHash string) (*xycommon.RpcReceipt, error) {
+	receipt, err := r.IRPCClient.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if receipt == nil || receipt.BlockNumber == nil {
+		return receipt, nil
+	}
+
+	r.record(receipt.BlockNumber.Uint64(), func(a *BlockArchive) {
+		if !sameBlock(a, receipt.BlockHash.String()) {
+			return
+		}
+		for i, item := range a.Receipts {
+			if item.TxHash == receipt.TxHash {
+				a.Receipts[i] = receipt
+				return
+			}
+		}
+		a.Receipts = append(a.Receipts, receipt)
+	})
+	return receipt, nil
+}
+
+func (r *Recorder) BlockReceipts(ctx context.Context, blockHash string) ([]*xycommon.RpcReceipt, error) {
+	receipts, err := r.IRPCClient.BlockReceipts(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(receipts) < 1 || receipts[0].BlockNumber == nil {
+		return receipts, nil
+	}
+
+	r.record(receipts[0].BlockNumber.Uint64(), func(a *BlockArchive) {
+		if sameBlock(a, blockHash) {
+			a.Receipts = receipts
+		}
+	})
+	return receipts, nil
+}
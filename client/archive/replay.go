@@ -0,0 +1,241 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package archive
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"io/fs"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var ErrNotSupported = errors.New("not supported by block archive")
+
+// Client
+/*****************************************************
+ * File backed rpc client replaying the blocks
+ * recorded by Recorder, used for offline re-indexing
+ ****************************************************/
+type Client struct {
+	dir      string
+	latest   uint64
+	txBlocks *sync.Map // tx hash => block number
+	blocks   *sync.Map // block hash => block number
+}
+
+// Open load the archive directory
+func Open(dir string) (*Client, error) {
+	c := &Client{
+		dir:      dir,
+		txBlocks: &sync.Map{},
+		blocks:   &sync.Map{},
+	}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if num, ok := parseBlockNum(path); ok && !d.IsDir() && num > c.latest {
+			c.latest = num
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if c.latest <= 0 {
+		return nil, errors.New("no block found in archive dir " + dir)
+	}
+	return c, nil
+}
+
+func (c *Client) load(blockNum uint64) (*BlockArchive, error) {
+	a, err := readArchive(c.dir, blockNum)
+	if err != nil {
+		return nil, err
+	}
+
+	if a == nil {
+		return nil, xycommon.ErrNotFound
+	}
+	return a, nil
+}
+
+func (c *Client) blockNum(number *big.Int) uint64 {
+	if number == nil || number.Sign() < 0 {
+		return c.latest
+	}
+	return number.Uint64()
+}
+
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.latest, nil
+}
+
+func (c *Client) BlockNumberByTag(ctx context.Context, tag string) (uint64, error) {
+	return c.latest, nil
+}
+
+func (c *Client) BlockByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcBlock, error) {
+	num := c.blockNum(number)
+	a, err := c.load(num)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.Block == nil {
+		return nil, xycommon.ErrNotFound
+	}
+
+	// index block & tx hashes for receipts query
+	c.blocks.Store(strings.ToLower(a.Block.Hash), num)
+	for _, tx := range a.Block.Transactions {
+		c.txBlocks.Store(strings.ToLower(tx.Hash), num)
+	}
+	return a.Block, nil
+}
+
+func (c *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcHeader, error) {
+	block, err := c.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	return &xycommon.RpcHeader{
+		ParentHash: block.ParentHash,
+		Number:     block.Number,
+		Time:       block.Time,
+		TxHash:     block.TxHash,
+		Hash:       block.Hash,
+	}, nil
+}
+
+func (c *Client) TransactionSender(ctx context.Context, txHash, blockHash string, txIndex uint) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (c *Client) TransactionReceipt(ctx context.Context, txHash string) (*xycommon.RpcReceipt, error) {
+	num, ok := c.txBlocks.Load(strings.ToLower(txHash))
+	if !ok {
+		return nil, xycommon.ErrNotFound
+	}
+
+	a, err := c.load(num.(uint64))
+	if err != nil {
+		return nil, err
+	}
+
+	hash := common.HexToHash(txHash)
+	for _, r := range a.Receipts {
+		if r.TxHash == hash {
+			return r, nil
+		}
+	}
+	return nil, xycommon.ErrNotFound
+}
+
+func (c *Client) BlockReceipts(ctx context.Context, blockHash string) ([]*xycommon.RpcReceipt, error) {
+	num, ok := c.blocks.Load(strings.ToLower(blockHash))
+	if !ok {
+		return nil, xycommon.ErrNotFound
+	}
+
+	a, err := c.load(num.(uint64))
+	if err != nil {
+		return nil, err
+	}
+	return a.Receipts, nil
+}
+
+func (c *Client) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]xycommon.RpcLog, error) {
+	if q.BlockHash != nil {
+		return nil, ErrNotSupported
+	}
+
+	from, to := c.blockNum(q.FromBlock), c.blockNum(q.ToBlock)
+	logs := make([]xycommon.RpcLog, 0, 10)
+	for num := from; num <= to; num++ {
+		a, err := c.load(num)
+		if err != nil {
+			if errors.Is(err, xycommon.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, l := range a.Logs {
+			if matchLog(&l, q) {
+				logs = append(logs, l)
+			}
+		}
+	}
+	return logs, nil
+}
+
+// matchLog check the log matches the query addresses & topics
+func matchLog(l *xycommon.RpcLog, q ethereum.FilterQuery) bool {
+	if len(q.Addresses) > 0 {
+		found := false
+		for _, addr := range q.Addresses {
+			if addr == l.Address {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	for i, topics := range q.Topics {
+		if len(topics) < 1 {
+			continue
+		}
+
+		if i >= len(l.Topics) {
+			return false
+		}
+
+		found := false
+		for _, topic := range topics {
+			if topic == l.Topics[i] {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+	return true
+}
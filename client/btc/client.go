@@ -0,0 +1,232 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package btc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/uxuycom/indexer/xylog"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNoResult the node returned an empty result
+var ErrNoResult = errors.New("no result in JSON-RPC response")
+
+// RawClient defines typed wrappers for the bitcoind JSON-RPC API.
+type RawClient struct {
+	url      string
+	user     string
+	password string
+	c        *http.Client
+	id       atomic.Uint64
+}
+
+// NewClient creates a client of the bitcoind compatible node, basic auth is used if user is not empty
+func NewClient(url, user, password string) *RawClient {
+	return &RawClient{
+		url:      url,
+		user:     user,
+		password: password,
+		c:        &http.Client{},
+	}
+}
+
+// Close closes idle connections of the http client.
+func (bc *RawClient) Close() {
+	bc.c.CloseIdleConnections()
+}
+
+func (bc *RawClient) doCallContext(retry int, result interface{}, method string, args ...interface{}) (err error) {
+	timeCtx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	t1 := time.Now()
+	err = bc.call(timeCtx, result, method, args...)
+
+	//build logs
+	msg := fmt.Sprintf("JSONRPC-CALL, method:%s, args[%v], cost[%v]", method, args, time.Since(t1))
+	if retry > 0 {
+		msg += fmt.Sprintf(", retry[%d]", retry)
+	}
+
+	if err != nil {
+		msg += fmt.Sprintf(", err[%v]", err)
+	}
+	xylog.Logger.Debug(msg)
+	return
+}
+
+func (bc *RawClient) call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if args == nil {
+		args = []interface{}{}
+	}
+
+	req, err := btcjson.NewRequest(btcjson.RpcVersion1, bc.id.Add(1), method, args)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, bc.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if bc.user != "" {
+		httpReq.SetBasicAuth(bc.user, bc.password)
+	}
+
+	httpResp, err := bc.c.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return err
+	}
+
+	// bitcoind replies errors with http status 404/500 and a json body
+	resp := &btcjson.Response{}
+	if err = json.Unmarshal(data, resp); err != nil {
+		return fmt.Errorf("status[%s], invalid response[%s]", httpResp.Status, string(data))
+	}
+
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	if len(resp.Result) == 0 || string(resp.Result) == "null" {
+		return ErrNoResult
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+func (bc *RawClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) (err error) {
+	retry := 10
+	for i := 0; i < retry; i++ {
+		//call
+		err = bc.doCallContext(i, result, method, args...)
+		if err == nil {
+			return nil
+		}
+
+		if errors.Is(err, ErrNoResult) {
+			return ErrNoResult
+		}
+
+		// rpc errors are returned by the node, retrying doesn't help
+		var rpcErr *btcjson.RPCError
+		if errors.As(err, &rpcErr) {
+			return err
+		}
+
+		select {
+		case <-time.After(time.Millisecond * 100):
+			//do nothing
+		case <-ctx.Done():
+			return errors.New("ctx done quit")
+		}
+	}
+	return err
+}
+
+// GetBlockCount returns the height of the most-work fully-validated chain
+func (bc *RawClient) GetBlockCount(ctx context.Context) (int64, error) {
+	var result int64
+	err := bc.CallContext(ctx, &result, "getblockcount")
+	return result, err
+}
+
+// GetBestBlockHash returns the hash of the best block
+func (bc *RawClient) GetBestBlockHash(ctx context.Context) (string, error) {
+	var result string
+	err := bc.CallContext(ctx, &result, "getbestblockhash")
+	return result, err
+}
+
+// GetBlockHash returns the hash of the block at the height of the best chain
+func (bc *RawClient) GetBlockHash(ctx context.Context, height int64) (string, error) {
+	var result string
+	err := bc.CallContext(ctx, &result, "getblockhash", height)
+	return result, err
+}
+
+// blockPrevouts the previous outputs spent by the block txs, getblock verbosity 3
+type blockPrevouts struct {
+	Tx []struct {
+		Vin []struct {
+			Prevout *btcjson.Vout `json:"prevout"`
+		} `json:"vin"`
+	} `json:"tx"`
+}
+
+// GetBlockVerboseTx returns the block with decoded transactions and the outputs spent by
+// their inputs, getblock verbosity 3. Nodes before v23 reply verbosity 2 without prevouts,
+// the prevouts are nil then.
+func (bc *RawClient) GetBlockVerboseTx(ctx context.Context, hash string) (*btcjson.GetBlockVerboseTxResult, [][]*btcjson.Vout, error) {
+	var raw json.RawMessage
+	if err := bc.CallContext(ctx, &raw, "getblock", hash, 3); err != nil {
+		return nil, nil, err
+	}
+
+	var result btcjson.GetBlockVerboseTxResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, nil, err
+	}
+
+	var prevouts blockPrevouts
+	if err := json.Unmarshal(raw, &prevouts); err != nil {
+		return nil, nil, err
+	}
+
+	items := make([][]*btcjson.Vout, len(prevouts.Tx))
+	for idx, tx := range prevouts.Tx {
+		items[idx] = make([]*btcjson.Vout, len(tx.Vin))
+		for i, in := range tx.Vin {
+			items[idx][i] = in.Prevout
+		}
+	}
+	return &result, items, nil
+}
+
+// GetBlockHeaderVerbose returns the decoded header of the block
+func (bc *RawClient) GetBlockHeaderVerbose(ctx context.Context, hash string) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	var result btcjson.GetBlockHeaderVerboseResult
+	if err := bc.CallContext(ctx, &result, "getblockheader", hash, true); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
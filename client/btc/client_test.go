@@ -0,0 +1,163 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package btc
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBClient(t *testing.T) {
+	xylog.InitLog(logrus.DebugLevel, "")
+
+	block := &btcjson.GetBlockVerboseTxResult{
+		Hash:         "00000000000000000002a7c4c1e48d76c5a37902165a270156b7a8d72728a054",
+		Height:       800000,
+		MerkleRoot:   "5e049f4030e0ab2debb92378f53c0a6e09548aea083f3ab25e1d94ea1155e29d",
+		Time:         1690168629,
+		PreviousHash: "00000000000000000001b2505c11119fcf29be733ec379f686518bf1090a522a",
+		Tx: []btcjson.TxRawResult{
+			{
+				Txid: "b75ca3106ed100521aa50e3ec267a06431c6319538898b25e1b757a5736f5fb4",
+				Vin:  []btcjson.Vin{{Coinbase: "0300350c"}},
+				Vout: []btcjson.Vout{{Value: 6.39090348, ScriptPubKey: btcjson.ScriptPubKeyResult{Address: "1BM1sAcrfV6d4zPKytzziu4McLQDsFC2Qc"}}},
+			},
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		req := &btcjson.Request{}
+		_ = json.NewDecoder(r.Body).Decode(req)
+
+		var result interface{}
+		var rpcErr *btcjson.RPCError
+		switch req.Method {
+		case "getblockcount":
+			result = block.Height
+		case "getblockhash":
+			var height int64
+			_ = json.Unmarshal(req.Params[0], &height)
+			if height != block.Height {
+				rpcErr = btcjson.NewRPCError(btcjson.ErrRPCInvalidParameter, "Block height out of range")
+				break
+			}
+			result = block.Hash
+		case "getblock":
+			result = block
+		case "getblockheader":
+			result = &btcjson.GetBlockHeaderVerboseResult{
+				Hash:         block.Hash,
+				Height:       int32(block.Height),
+				MerkleRoot:   block.MerkleRoot,
+				Time:         block.Time,
+				PreviousHash: block.PreviousHash,
+			}
+		}
+
+		data, _ := json.Marshal(result)
+		_ = json.NewEncoder(w).Encode(&btcjson.Response{Result: data, Error: rpcErr, ID: &req.ID})
+	}))
+	defer srv.Close()
+
+	c, err := Dial(srv.URL, "user", "pass")
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	num, err := c.BlockNumber(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(800000), num)
+
+	b, err := c.BlockByNumber(ctx, big.NewInt(800000))
+	assert.NoError(t, err)
+	assert.Equal(t, block.Hash, b.Hash)
+	assert.Equal(t, block.PreviousHash, b.ParentHash)
+	assert.Len(t, b.Transactions, 1)
+	assert.Equal(t, block.Tx[0].Txid, b.Transactions[0].Hash)
+	assert.Equal(t, "1BM1sAcrfV6d4zPKytzziu4McLQDsFC2Qc", b.Transactions[0].To)
+	assert.Equal(t, int64(639090348), b.Transactions[0].Value.Int64())
+	assert.Len(t, b.Transactions[0].Vin, 1)
+
+	header, err := c.HeaderByNumber(ctx, big.NewInt(800000))
+	assert.NoError(t, err)
+	assert.Equal(t, block.Hash, header.Hash)
+	assert.Equal(t, uint64(800000), header.Number.Uint64())
+
+	_, err = c.BlockByNumber(ctx, big.NewInt(800001))
+	assert.ErrorIs(t, err, xycommon.ErrNotFound)
+}
+
+func TestBlockPrevouts(t *testing.T) {
+	xylog.InitLog(logrus.DebugLevel, "")
+
+	// getblock verbosity 3, the coinbase input spends no output
+	raw := `{"hash":"aa","height":1,"tx":[
+		{"txid":"01","vin":[{"coinbase":"00"}],"vout":[{"value":1,"n":0,"scriptPubKey":{"address":"bc1pminer"}}]},
+		{"txid":"02","vin":[{"txid":"0a","vout":0,"prevout":{"value":0.000006,"scriptPubKey":{"address":"bc1pdummy"}}},{"txid":"0b","vout":1,"prevout":{"value":0.00000546}}],
+		 "vout":[{"value":0.00001146,"n":0,"scriptPubKey":{"address":"bc1preceiver"}}]}
+	]}`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &btcjson.Request{}
+		_ = json.NewDecoder(r.Body).Decode(req)
+
+		var result json.RawMessage
+		switch req.Method {
+		case "getblockhash":
+			result = json.RawMessage(`"aa"`)
+		case "getblock":
+			assert.Equal(t, "3", string(req.Params[1]))
+			result = json.RawMessage(raw)
+		}
+		_ = json.NewEncoder(w).Encode(&btcjson.Response{Result: result, ID: &req.ID})
+	}))
+	defer srv.Close()
+
+	c, err := Dial(srv.URL, "", "")
+	assert.NoError(t, err)
+
+	b, err := c.BlockByNumber(context.Background(), big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Len(t, b.Transactions, 2)
+	assert.Equal(t, []*btcjson.Vout{nil}, b.Transactions[0].Prevouts)
+
+	prevouts := b.Transactions[1].Prevouts
+	assert.Len(t, prevouts, 2)
+	assert.Equal(t, 0.000006, prevouts[0].Value)
+	assert.Equal(t, "bc1pdummy", prevouts[0].ScriptPubKey.Address)
+	assert.Equal(t, 0.00000546, prevouts[1].Value)
+}
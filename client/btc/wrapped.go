@@ -0,0 +1,203 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package btc
+
+import (
+	"context"
+	"errors"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/ethereum/go-ethereum"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"math"
+	"math/big"
+)
+
+// ErrNotSupported the api has no equivalent on bitcoind compatible nodes
+var ErrNotSupported = errors.New("not supported by btc client")
+
+// BClient implements xycommon.IRPCClient for bitcoind compatible nodes
+type BClient struct {
+	rawClient *RawClient
+}
+
+// Dial creates a client of the node, basic auth is used if user is not empty
+func Dial(rawurl, user, password string) (*BClient, error) {
+	if rawurl == "" {
+		return nil, errors.New("btc rpc url empty")
+	}
+	return &BClient{rawClient: NewClient(rawurl, user, password)}, nil
+}
+
+// Close closes the underlying RPC connection.
+func (bc *BClient) Close() {
+	bc.rawClient.Close()
+}
+
+func convertErr(err error) error {
+	if errors.Is(err, ErrNoResult) {
+		return xycommon.ErrNotFound
+	}
+
+	var rpcErr *btcjson.RPCError
+	if errors.As(err, &rpcErr) {
+		// block height out of range / block not found
+		if rpcErr.Code == btcjson.ErrRPCInvalidParameter || rpcErr.Code == btcjson.ErrRPCBlockNotFound {
+			return xycommon.ErrNotFound
+		}
+	}
+	return err
+}
+
+// BlockNumber returns the most recent block number
+func (bc *BClient) BlockNumber(ctx context.Context) (uint64, error) {
+	num, err := bc.rawClient.GetBlockCount(ctx)
+	if err != nil {
+		return 0, convertErr(err)
+	}
+	return uint64(num), nil
+}
+
+// BlockNumberByTag bitcoin has no safe / finalized block tags
+func (bc *BClient) BlockNumberByTag(ctx context.Context, tag string) (uint64, error) {
+	return 0, ErrNotSupported
+}
+
+func (bc *BClient) blockHash(ctx context.Context, number *big.Int) (string, error) {
+	if number == nil {
+		return bc.rawClient.GetBestBlockHash(ctx)
+	}
+	return bc.rawClient.GetBlockHash(ctx, number.Int64())
+}
+
+// BlockByNumber returns the block with all verbose transactions. If number is nil, the
+// best block is returned.
+func (bc *BClient) BlockByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcBlock, error) {
+	hash, err := bc.blockHash(ctx, number)
+	if err != nil {
+		return nil, convertErr(err)
+	}
+
+	block, prevouts, err := bc.rawClient.GetBlockVerboseTx(ctx, hash)
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	return bc.convertBlock(block, prevouts), nil
+}
+
+func (bc *BClient) HeaderByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcHeader, error) {
+	hash, err := bc.blockHash(ctx, number)
+	if err != nil {
+		return nil, convertErr(err)
+	}
+
+	head, err := bc.rawClient.GetBlockHeaderVerbose(ctx, hash)
+	if err != nil {
+		return nil, convertErr(err)
+	}
+
+	header := &xycommon.RpcHeader{
+		ParentHash: head.PreviousHash,
+		Number:     big.NewInt(int64(head.Height)),
+		Time:       uint64(head.Time),
+		TxHash:     head.MerkleRoot,
+		Hash:       head.Hash,
+	}
+	return header, nil
+}
+
+func (bc *BClient) convertBlock(block *btcjson.GetBlockVerboseTxResult, prevouts [][]*btcjson.Vout) *xycommon.RpcBlock {
+	number := big.NewInt(block.Height)
+	cBlock := &xycommon.RpcBlock{
+		ParentHash:   block.PreviousHash,
+		Number:       number,
+		GasLimit:     big.NewInt(0),
+		GasUsed:      big.NewInt(0),
+		Time:         uint64(block.Time),
+		TxHash:       block.MerkleRoot,
+		Hash:         block.Hash,
+		Transactions: make([]*xycommon.RpcTransaction, 0, len(block.Tx)),
+	}
+
+	for idx, tx := range block.Tx {
+		cTx := bc.convertTransaction(block.Hash, number, idx, &tx)
+		if idx < len(prevouts) && len(prevouts[idx]) == len(tx.Vin) {
+			cTx.Prevouts = prevouts[idx]
+		}
+		cBlock.Transactions = append(cBlock.Transactions, cTx)
+	}
+	return cBlock
+}
+
+// convertTransaction the sender is unknown without the previous outputs, the first output
+// address is used as the receiver
+func (bc *BClient) convertTransaction(blockHash string, number *big.Int, idx int, tx *btcjson.TxRawResult) *xycommon.RpcTransaction {
+	value := int64(0)
+	for _, out := range tx.Vout {
+		value += toSatoshi(out.Value)
+	}
+
+	toAddr := ""
+	if len(tx.Vout) > 0 {
+		toAddr = tx.Vout[0].ScriptPubKey.Address
+		if toAddr == "" && len(tx.Vout[0].ScriptPubKey.Addresses) > 0 {
+			toAddr = tx.Vout[0].ScriptPubKey.Addresses[0]
+		}
+	}
+
+	return &xycommon.RpcTransaction{
+		BlockHash:   blockHash,
+		BlockNumber: number,
+		TxIndex:     big.NewInt(int64(idx)),
+		Hash:        tx.Txid,
+		To:          toAddr,
+		Value:       big.NewInt(value),
+		Vin:         tx.Vin,
+		Vout:        tx.Vout,
+		Status:      1,
+	}
+}
+
+// toSatoshi converts the btc amount of a json response
+func toSatoshi(value float64) int64 {
+	return int64(math.Round(value * 1e8))
+}
+
+// TransactionSender not supported, inputs reference previous outputs instead of a sender
+func (bc *BClient) TransactionSender(ctx context.Context, txHash, blockHash string, txIndex uint) (string, error) {
+	return "", ErrNotSupported
+}
+
+// TransactionReceipt not supported, bitcoin txs in a block are always valid
+func (bc *BClient) TransactionReceipt(ctx context.Context, txHash string) (*xycommon.RpcReceipt, error) {
+	return nil, ErrNotSupported
+}
+
+// BlockReceipts not supported, bitcoin txs in a block are always valid
+func (bc *BClient) BlockReceipts(ctx context.Context, blockHash string) ([]*xycommon.RpcReceipt, error) {
+	return nil, ErrNotSupported
+}
+
+// FilterLogs not supported, bitcoin has no event logs
+func (bc *BClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]xycommon.RpcLog, error) {
+	return nil, ErrNotSupported
+}
@@ -128,6 +128,20 @@ func (ec *RawClient) BlockNumber(ctx context.Context) (uint64, error) {
 	return uint64(result), err
 }
 
+// BlockNumberByTag returns the number of the block with the given tag, e.g. "safe", "finalized"
+func (ec *RawClient) BlockNumberByTag(ctx context.Context, tag string) (uint64, error) {
+	// the header only, the txs are not needed
+	var header RpcHeader
+	err := ec.CallContext(ctx, &header, "eth_getBlockByNumber", tag, false)
+	if err != nil {
+		return 0, err
+	}
+	if header.Number == nil {
+		return 0, rpc.ErrNoResult
+	}
+	return header.Number.ToInt().Uint64(), nil
+}
+
 func (ec *RawClient) HeaderByNumber(ctx context.Context, number *big.Int) (*RpcHeader, error) {
 	var result RpcHeader
 	err := ec.CallContext(ctx, &result, "eth_getBlockByNumber", toBlockNumArg(number), true)
@@ -245,6 +259,17 @@ func (ec *RawClient) TransactionReceipt(ctx context.Context, txHash common.Hash)
 	return r, err
 }
 
+// BlockReceipts returns all the receipts of the given block by eth_getBlockReceipts.
+// The block is queried by hash, so the receipts never belong to a reorged block of the same height.
+func (ec *RawClient) BlockReceipts(ctx context.Context, blockHash common.Hash) ([]*RpcReceipt, error) {
+	var r []*RpcReceipt
+	err := ec.CallContext(ctx, &r, "eth_getBlockReceipts", blockHash)
+	if err == nil && r == nil {
+		return nil, ethereum.NotFound
+	}
+	return r, err
+}
+
 func (ec *RawClient) TransactionByHash(ctx context.Context, hash common.Hash) (tx *RpcTransaction, isPending bool, err error) {
 	tx = &RpcTransaction{}
 	err = ec.CallContext(ctx, tx, "eth_getTransactionByHash", hash)
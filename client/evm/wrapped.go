@@ -28,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/uxuycom/indexer/client/xycommon"
 	"math/big"
@@ -83,6 +84,15 @@ func (ec *EClient) BlockNumber(ctx context.Context) (uint64, error) {
 	return ec.rawClient.BlockNumber(ctx)
 }
 
+// BlockNumberByTag returns the number of the block with the given tag, e.g. "safe", "finalized"
+func (ec *EClient) BlockNumberByTag(ctx context.Context, tag string) (uint64, error) {
+	num, err := ec.rawClient.BlockNumberByTag(ctx, tag)
+	if errors.Is(err, rpc.ErrNoResult) {
+		return 0, xycommon.ErrNotFound
+	}
+	return num, err
+}
+
 func (ec *EClient) convertHeader(head *RpcHeader, err error) (*xycommon.RpcHeader, error) {
 	if err != nil {
 		if errors.Is(err, rpc.ErrNoResult) {
@@ -96,6 +106,7 @@ func (ec *EClient) convertHeader(head *RpcHeader, err error) (*xycommon.RpcHeade
 		Number:     head.Number.ToInt(),
 		Time:       uint64(head.Time),
 		TxHash:     head.TxHash.String(),
+		Hash:       head.Hash.String(),
 	}
 	return header, nil
 }
@@ -196,8 +207,37 @@ func (ec *EClient) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQue
 
 // SubscribeNewHead subscribes to notifications about the current blockchain head
 // on the given channel.
-func (ec *EClient) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header, ct chan<- *xycommon.RpcBlock) (ethereum.Subscription, error) {
-	return ec.rawClient.SubscribeNewHead(ctx, ch)
+func (ec *EClient) SubscribeNewHead(ctx context.Context, ch chan<- *xycommon.RpcHeader) (ethereum.Subscription, error) {
+	heads := make(chan *types.Header, 16)
+	sub, err := ec.rawClient.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return nil, err
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case head := <-heads:
+				header := &xycommon.RpcHeader{
+					ParentHash: head.ParentHash.String(),
+					Number:     head.Number,
+					Time:       head.Time,
+					TxHash:     head.TxHash.String(),
+					Hash:       head.Hash().String(),
+				}
+				select {
+				case ch <- header:
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
 }
 
 // TransactionSender returns the sender address of the given transaction. The transaction
@@ -216,6 +256,23 @@ func (ec *EClient) TransactionSender(ctx context.Context, txHashStr, blockHashSt
 	return addr.String(), nil
 }
 
+// BlockReceipts returns all the receipts of the given block.
+func (ec *EClient) BlockReceipts(ctx context.Context, blockHash string) ([]*xycommon.RpcReceipt, error) {
+	rs, err := ec.rawClient.BlockReceipts(ctx, common.HexToHash(blockHash))
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) || errors.Is(err, rpc.ErrNoResult) {
+			return nil, xycommon.ErrNotFound
+		}
+		return nil, err
+	}
+
+	receipts := make([]*xycommon.RpcReceipt, 0, len(rs))
+	for _, r := range rs {
+		receipts = append(receipts, ec.convertReceipt(r))
+	}
+	return receipts, nil
+}
+
 func (ec *EClient) convertReceipt(r *RpcReceipt) *xycommon.RpcReceipt {
 	c := &xycommon.RpcReceipt{
 		Type:              r.Type.ToInt(),
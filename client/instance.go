@@ -23,11 +23,38 @@
 package client
 
 import (
+	"fmt"
+	"github.com/uxuycom/indexer/client/archive"
+	"github.com/uxuycom/indexer/client/btc"
 	"github.com/uxuycom/indexer/client/evm"
 	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
 	"github.com/uxuycom/indexer/model"
 )
 
-func NewRPCClient(rpc string, proto model.ChainGroup) (xycommon.IRPCClient, error) {
-	return evm.Dial(rpc)
+func NewRPCClient(c *config.ChainConfig) (xycommon.IRPCClient, error) {
+	switch c.ChainGroup {
+	case model.BtcChainGroup:
+		return btc.Dial(c.Rpc, c.UserName, c.PassWord)
+	}
+	return evm.Dial(c.Rpc)
+}
+
+func NewHeadSubscriber(ws string, proto model.ChainGroup) (xycommon.IHeadSubscriber, error) {
+	switch proto {
+	case model.BtcChainGroup:
+		return nil, fmt.Errorf("new head subscription not supported by chain group[%s]", proto)
+	}
+	return evm.Dial(ws)
+}
+
+// NewArchiveClient record blocks fetched by the rpc client, or replay recorded blocks instead of it
+func NewArchiveClient(c xycommon.IRPCClient, cfg *config.ArchiveConfig) (xycommon.IRPCClient, error) {
+	switch cfg.Mode {
+	case config.ArchiveModeRecord:
+		return archive.NewRecorder(c, cfg.Dir)
+	case config.ArchiveModeReplay:
+		return archive.Open(cfg.Dir)
+	}
+	return nil, fmt.Errorf("invalid archive mode[%s]", cfg.Mode)
 }
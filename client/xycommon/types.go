@@ -38,6 +38,8 @@ var ErrNotFound = errors.New("not found")
 type IRPCClient interface {
 	BlockNumber(ctx context.Context) (uint64, error)
 
+	BlockNumberByTag(ctx context.Context, tag string) (uint64, error)
+
 	BlockByNumber(ctx context.Context, number *big.Int) (*RpcBlock, error)
 
 	HeaderByNumber(ctx context.Context, number *big.Int) (*RpcHeader, error)
@@ -46,14 +48,33 @@ type IRPCClient interface {
 
 	TransactionReceipt(ctx context.Context, txHash string) (*RpcReceipt, error)
 
+	BlockReceipts(ctx context.Context, blockHash string) ([]*RpcReceipt, error)
+
 	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]RpcLog, error)
 }
 
+// IBlockArchiver optional hooks of the rpc clients archiving the fetched blocks
+type IBlockArchiver interface {
+	// FinishBlock the block is indexed & all its data fetched
+	FinishBlock(blockNum uint64)
+
+	// RollbackBlocks drop the archives of the blocks above the block
+	RollbackBlocks(blockNum uint64)
+}
+
+// IHeadSubscriber push based notifications about new chain heads
+type IHeadSubscriber interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *RpcHeader) (ethereum.Subscription, error)
+
+	Close()
+}
+
 type RpcHeader struct {
 	ParentHash string   `json:"parentHash"       gencodec:"required"`
 	Number     *big.Int `json:"number"           gencodec:"required"`
 	Time       uint64   `json:"timestamp"`
 	TxHash     string   `json:"transactionsRoot" gencodec:"required"`
+	Hash       string   `json:"hash"`
 }
 
 type RpcBlock struct {
@@ -70,23 +91,24 @@ type RpcBlock struct {
 }
 
 type RpcTransaction struct {
-	BlockHash   string         `json:"blockHash"`
-	BlockNumber *big.Int       `json:"blockNumber"`
-	TxIndex     *big.Int       `json:"transactionIndex"`
-	Type        *big.Int       `json:"type"`
-	Hash        string         `json:"hash"`
-	ChainID     *big.Int       `json:"chainId"`
-	From        string         `json:"from"`
-	To          string         `json:"to"`
-	Input       string         `json:"input"`
-	Value       *big.Int       `json:"value"`
-	Gas         *big.Int       `json:"gas"`
-	GasPrice    *big.Int       `json:"gasPrice"`
-	Vin         []btcjson.Vin  `json:"vin"`
-	Vout        []btcjson.Vout `json:"vout"`
-	Events      []RpcLog       `json:"events"`
-	Receipt     []RpcReceipt   `json:"receipt"`
-	Status      int64          `json:"status"`
+	BlockHash   string          `json:"blockHash"`
+	BlockNumber *big.Int        `json:"blockNumber"`
+	TxIndex     *big.Int        `json:"transactionIndex"`
+	Type        *big.Int        `json:"type"`
+	Hash        string          `json:"hash"`
+	ChainID     *big.Int        `json:"chainId"`
+	From        string          `json:"from"`
+	To          string          `json:"to"`
+	Input       string          `json:"input"`
+	Value       *big.Int        `json:"value"`
+	Gas         *big.Int        `json:"gas"`
+	GasPrice    *big.Int        `json:"gasPrice"`
+	Vin         []btcjson.Vin   `json:"vin"`
+	Vout        []btcjson.Vout  `json:"vout"`
+	Prevouts    []*btcjson.Vout `json:"prevouts"` // outputs spent by the inputs, nil items if unknown
+	Events      []RpcLog        `json:"events"`
+	Receipt     []RpcReceipt    `json:"receipt"`
+	Status      int64           `json:"status"`
 }
 
 type RpcLog struct {
@@ -24,10 +24,12 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
 	"github.com/uxuycom/indexer/client"
+	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/config"
 	"github.com/uxuycom/indexer/dcache"
 	"github.com/uxuycom/indexer/devents"
@@ -53,6 +55,12 @@ func main() {
 	// init
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
+	// sub commands
+	if len(os.Args) > 1 && os.Args[1] == "reindex" {
+		reindex(os.Args[2:])
+		return
+	}
+
 	// init args
 	initArgs()
 
@@ -73,41 +81,129 @@ func main() {
 	}
 
 	// set log debug level
-	if lv, err := logrus.ParseLevel(cfg.LogLevel); err == nil {
-		xylog.InitLog(lv, cfg.LogPath)
-	}
+	initLog()
+
+	// protocol rule changes & marketplace events
+	initRules()
 
+	// db pool shared by all chains
 	dbClient, err := storage.NewDbClient(&cfg.Database)
 	if err != nil {
 		xylog.Logger.Fatalf("db init err:%v", err)
 	}
-	rpcClient, err := client.NewRPCClient(cfg.Chain.Rpc, cfg.Chain.ChainGroup)
-	if err != nil {
-		xylog.Logger.Fatalf("initialize rpc client err:%v", err)
+
+	// Listen for SIGINT and SIGTERM signals, stopping all chains
+	quit := make(chan os.Signal, 1)
+
+	// start an isolated explorer for every chain
+	chains := make(map[string]struct{})
+	explorers := make([]*explorer.Explorer, 0, len(cfg.Chains))
+	for _, chainCfg := range cfg.ChainConfigs() {
+		if _, ok := chains[chainCfg.Chain.ChainName]; ok {
+			xylog.Logger.Fatalf("duplicated chain[%s] config", chainCfg.Chain.ChainName)
+		}
+		chains[chainCfg.Chain.ChainName] = struct{}{}
+		explorers = append(explorers, startChain(dbClient, chainCfg))
+	}
+
+	// expose chains status
+	if cfg.Status != nil && cfg.Status.Enabled {
+		go serveStatus(cfg.Status.Listen, explorers)
 	}
 
-	dCache := dcache.NewManager(dbClient, cfg.Chain.ChainName)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	// notify service stopped
+	for _, exp := range explorers {
+		exp.Stop()
+	}
+	xylog.Logger.Infof("service stopped")
+}
 
-	// init protocols
-	protocol.InitProtocols(dCache)
+// startChain start scanning & indexing of the chain
+func startChain(dbClient *storage.DBClient, chainCfg *config.Config) *explorer.Explorer {
+	xylog.Logger.Infof("start chain[%s]", chainCfg.Chain.ChainName)
+	rpcClient := initRPCClient(chainCfg)
+	snapshotPath := ""
+	if chainCfg.Snapshot != nil && chainCfg.Snapshot.Dir != "" {
+		snapshotPath = dcache.SnapshotPath(chainCfg.Snapshot.Dir, chainCfg.Chain.ChainName)
+	}
+	dCache := dcache.NewSnapshotManager(dbClient, chainCfg.Chain.ChainName, chainCfg.Scan.BalanceCacheSize, snapshotPath)
 
 	// init task
-	task.InitTask(dbClient, &cfg)
+	task.InitTask(dbClient, chainCfg)
 
-	// Listen for SIGINT and SIGTERM signals
+	ctx, cancel := context.WithCancel(context.Background())
+	dEvent := devents.NewDEvents(ctx, dbClient)
+
+	// only this chain is stopped once its scan quits
 	quit := make(chan os.Signal, 1)
-	dEvent := devents.NewDEvents(context.TODO(), dbClient)
-	exp := explorer.NewExplorer(rpcClient, dbClient, &cfg, dCache, dEvent, quit)
+	exp := explorer.NewExplorer(rpcClient, dbClient, chainCfg, dCache, dEvent, quit)
 	go exp.Scan()
 	go exp.Index()
 	go exp.FlushDB()
+	go func() {
+		<-quit
+		exp.Stop()
+		cancel()
+		xylog.Logger.Warnf("chain[%s] stopped", chainCfg.Chain.ChainName)
+	}()
+	return exp
+}
 
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+// serveStatus serve the indexing status of all chains
+func serveStatus(listen string, explorers []*explorer.Explorer) {
+	if listen == "" {
+		listen = ":6070"
+	}
 
-	// notify service stopped
-	exp.Stop()
-	xylog.Logger.Infof("service stopped")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		status := make([]*explorer.Status, 0, len(explorers))
+		for _, exp := range explorers {
+			status = append(status, exp.Status())
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(status)
+	})
+
+	if err := http.ListenAndServe(listen, mux); err != nil {
+		xylog.Logger.Errorf("start status server err:%v", err)
+	}
+}
+
+func initLog() {
+	if lv, err := logrus.ParseLevel(cfg.LogLevel); err == nil {
+		xylog.InitLog(lv, cfg.LogPath)
+	}
+}
+
+// initRules rule changes & marketplace events must be the same for indexing & reindexing
+func initRules() {
+	if err := protocol.LoadRules(cfg.Rules); err != nil {
+		xylog.Logger.Fatalf("rules init err:%v", err)
+	}
+	if err := protocol.LoadMarkets(cfg.Markets); err != nil {
+		xylog.Logger.Fatalf("markets init err:%v", err)
+	}
+}
+
+func initRPCClient(cfg *config.Config) xycommon.IRPCClient {
+	rpcClient, err := client.NewRPCClient(&cfg.Chain)
+	if err != nil {
+		xylog.Logger.Fatalf("initialize rpc client err:%v", err)
+	}
+
+	// record blocks to disk or replay them offline
+	if cfg.Archive != nil && cfg.Archive.Mode != "" {
+		rpcClient, err = client.NewArchiveClient(rpcClient, cfg.Archive)
+		if err != nil {
+			xylog.Logger.Fatalf("initialize block archive err:%v", err)
+		}
+	}
+	return rpcClient
 }
 
 func initArgs() {
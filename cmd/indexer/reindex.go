@@ -0,0 +1,171 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package main
+
+import (
+	"context"
+	"github.com/spf13/pflag"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/explorer"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/storage"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// reindex
+/***************************************
+ * indexer reindex --from N [--to M] [--tick X] [--chain C]
+ * truncate the indexed data from block N, then replay blocks [N, M]
+ * through the protocol parsers. the indexer must be stopped while reindexing.
+ * --tick limits both truncating & replaying to the tick, the synced block
+ * is kept so M must be the last synced block
+ * --chain selects the chain when multiple chains are configured
+ ***************************************/
+func reindex(args []string) {
+	var from, to uint64
+	var tick, chain string
+
+	flags := pflag.NewFlagSet("reindex", pflag.ExitOnError)
+	flags.StringVarP(&flagConfig, "config", "c", "config.json", "config file")
+	flags.Uint64Var(&from, "from", 0, "first block to reindex")
+	flags.Uint64Var(&to, "to", 0, "last block to reindex, defaults to the last synced block")
+	flags.StringVar(&tick, "tick", "", "only reindex the tick")
+	flags.StringVar(&chain, "chain", "", "chain to reindex, required when multiple chains are configured")
+	_ = flags.Parse(args)
+
+	// load configs
+	config.LoadConfig(&cfg, flagConfig)
+	initLog()
+	initRules()
+
+	chainCfg := selectChain(chain)
+	dbClient, err := storage.NewDbClient(&cfg.Database)
+	if err != nil {
+		xylog.Logger.Fatalf("db init err:%v", err)
+	}
+	rpcClient := initRPCClient(chainCfg)
+
+	chain = chainCfg.Chain.ChainName
+	status, err := dbClient.QueryLastBlockStatus(chain)
+	if err != nil {
+		xylog.Logger.Fatalf("load last block status err:%v", err)
+	}
+
+	if status == nil {
+		xylog.Logger.Fatalf("chain[%s] has not been indexed yet", chain)
+	}
+
+	if to <= 0 {
+		to = status.BlockNumber
+	}
+
+	if from <= 0 || from > to || to > status.BlockNumber {
+		xylog.Logger.Fatalf("invalid reindex range[%d-%d], last synced block[%d]", from, to, status.BlockNumber)
+	}
+
+	if tick != "" && to != status.BlockNumber {
+		xylog.Logger.Fatalf("tick reindex must replay up to the last synced block[%d]", status.BlockNumber)
+	}
+
+	// truncate indexed data to the parent block
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	parent, err := rpcClient.HeaderByNumber(ctx, new(big.Int).SetUint64(from-1))
+	cancel()
+	if err != nil {
+		xylog.Logger.Fatalf("get block[%d] header err:%v", from-1, err)
+	}
+
+	// the cache snapshot is stale once truncated
+	if chainCfg.Snapshot != nil && chainCfg.Snapshot.Dir != "" {
+		if err = dcache.RemoveSnapshot(dcache.SnapshotPath(chainCfg.Snapshot.Dir, chain)); err != nil {
+			xylog.Logger.Fatalf("remove cache snapshot err:%v", err)
+		}
+	}
+
+	dEvent := devents.NewDEvents(context.TODO(), dbClient)
+	_, err = dEvent.Rollback(&model.BlockStatus{
+		Chain:       chain,
+		BlockHash:   parent.Hash,
+		BlockNumber: from - 1,
+		BlockTime:   time.Unix(int64(parent.Time), 0),
+	}, tick)
+	if err != nil {
+		xylog.Logger.Fatalf("truncate indexed data from block[%d] err:%v", from, err)
+	}
+	xylog.Logger.Infof("truncate indexed data success, chain[%s], from block[%d], tick[%s]", chain, from, tick)
+
+	// cache loaded after truncated
+	dCache := dcache.NewBoundedManager(dbClient, chain, chainCfg.Scan.BalanceCacheSize)
+
+	if tick != "" {
+		filters := &config.IndexFilter{}
+		if chainCfg.Filters != nil {
+			*filters = *chainCfg.Filters
+		}
+		whitelist := &config.Whitelist{Ticks: []string{tick}}
+		if filters.Whitelist != nil {
+			whitelist.Protocols = filters.Whitelist.Protocols
+		}
+		filters.Whitelist = whitelist
+		chainCfg.Filters = filters
+	}
+
+	quit := make(chan os.Signal, 1)
+	exp := explorer.NewExplorer(rpcClient, dbClient, chainCfg, dCache, dEvent, quit)
+	exp.SetScanRange(from, to)
+	go exp.Scan()
+	go exp.Index()
+	go exp.FlushDB()
+
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	exp.Stop()
+	xylog.Logger.Infof("reindex blocks[%d-%d] stopped", from, to)
+}
+
+// selectChain pick the config of the chain to reindex
+func selectChain(chain string) *config.Config {
+	chains := cfg.ChainConfigs()
+	if chain == "" {
+		if len(chains) > 1 {
+			xylog.Logger.Fatalf("multiple chains configured, --chain is required")
+		}
+		return chains[0]
+	}
+
+	for _, c := range chains {
+		if c.Chain.ChainName == chain {
+			return c
+		}
+	}
+	xylog.Logger.Fatalf("chain[%s] is not configured", chain)
+	return nil
+}
@@ -29,16 +29,26 @@ import (
 	"path/filepath"
 )
 
+const (
+	ConfirmationModeDelayed   = "delayed"   // latest block minus delayed_block_num
+	ConfirmationModeSafe      = "safe"      // the node's "safe" block
+	ConfirmationModeFinalized = "finalized" // the node's "finalized" block
+)
+
 type ScanConfig struct {
 	StartBlock        uint64 `json:"start_block" mapstructure:"start_block"`
 	BlockBatchWorkers uint64 `json:"block_batch_workers" mapstructure:"block_batch_workers"`
 	TxBatchWorkers    uint64 `json:"tx_batch_workers" mapstructure:"tx_batch_workers"`
 	DelayedBlockNum   uint64 `json:"delayed_block_num" mapstructure:"delayed_block_num"`
+	ReorgWindow       uint64 `json:"reorg_window" mapstructure:"reorg_window"` // recent block hashes kept for reorg detection
+	ConfirmationMode  string `json:"confirmation_mode" mapstructure:"confirmation_mode"`
+	BalanceCacheSize  int    `json:"balance_cache_size" mapstructure:"balance_cache_size"` // max balances cached, all loaded at startup if 0
 }
 
 type ChainConfig struct {
 	ChainName  string           `json:"chain_name" mapstructure:"chain_name"`
 	Rpc        string           `json:"rpc"`
+	Ws         string           `json:"ws"` // websocket endpoint for new head subscription, polling is used if empty
 	UserName   string           `json:"username"`
 	PassWord   string           `json:"password"`
 	ChainGroup model.ChainGroup `json:"chain_group" mapstructure:"chain_group"`
@@ -49,12 +59,14 @@ type StatConfig struct {
 	BalanceStartId uint64 `json:"balance_start_id" mapstructure:"balance_start_id"`
 }
 
+type Whitelist struct {
+	Ticks     []string `json:"ticks"`
+	Protocols []string `json:"protocols"`
+}
+
 type IndexFilter struct {
-	Whitelist *struct {
-		Ticks     []string `json:"ticks"`
-		Protocols []string `json:"protocols"`
-	} `json:"whitelist"`
-	EventTopics []string `json:"event_topics" mapstructure:"event_topics"`
+	Whitelist   *Whitelist `json:"whitelist"`
+	EventTopics []string   `json:"event_topics" mapstructure:"event_topics"`
 }
 
 // DatabaseConfig database config
@@ -64,20 +76,126 @@ type DatabaseConfig struct {
 	EnableLog bool   `json:"enable_log" mapstructure:"enable_log"`
 }
 
+const (
+	ArchiveModeRecord = "record"
+	ArchiveModeReplay = "replay"
+)
+
+// ArchiveConfig record fetched blocks to disk, or replay them instead of a live node
+type ArchiveConfig struct {
+	Mode string `json:"mode"`
+	Dir  string `json:"dir"`
+}
+
 type ProfileConfig struct {
 	Enabled bool   `json:"enabled"`
 	Listen  string `json:"listen"`
 }
 
+// StatusConfig http endpoint exposing the indexing status of chains
+type StatusConfig struct {
+	Enabled bool   `json:"enabled"`
+	Listen  string `json:"listen"`
+}
+
+// SnapshotConfig cache snapshots of every chain written into dir, loaded at startup
+type SnapshotConfig struct {
+	Dir      string `json:"dir"`
+	Interval uint64 `json:"interval"` // seconds between snapshots
+}
+
+// RuleConfig protocol rule change applied to blocks >= height, unset rules are kept
+type RuleConfig struct {
+	Protocol      string   `json:"protocol"`
+	Chains        []string `json:"chains"` // all chains if empty
+	Height        uint64   `json:"height"`
+	MaxDataLength *int     `json:"max_data_length" mapstructure:"max_data_length"`
+	MaxSupply     *string  `json:"max_supply" mapstructure:"max_supply"`
+	Minter        *string  `json:"minter"` // to / from
+	SelfMint      *bool    `json:"self_mint" mapstructure:"self_mint"`
+	PartialMint   *bool    `json:"partial_mint" mapstructure:"partial_mint"`
+	Precision     *string  `json:"precision"` // unchecked / strict / truncate
+	Decimals      *int     `json:"decimals"`  // tick decimals of deploys without dec
+}
+
+// MarketConfig marketplace event logs turned into transfers of the protocol, e.g.
+// "Sold(address indexed seller,address indexed buyer,string tick,uint256 amount)"
+type MarketConfig struct {
+	Chain     string       `json:"chain"`
+	Protocol  string       `json:"protocol"`
+	Contracts []string     `json:"contracts"` // contracts emitting the event, any if empty & from is $contract
+	Event     string       `json:"event"`     // event signature with argument names
+	Operate   string       `json:"op"`        // exchange if empty
+	Fields    MarketFields `json:"fields"`
+}
+
+// MarketFields the event argument names of the transfer fields,
+// MarketFieldContract refers to the contract emitting the event
+type MarketFields struct {
+	From           string `json:"from"`
+	To             string `json:"to"`
+	Tick           string `json:"tick"` // string, or the keccak256 hash of indexed strings
+	Amount         string `json:"amount"`
+	AmountDecimals int32  `json:"amount_decimals" mapstructure:"amount_decimals"` // the raw amount is divided by 10^decimals
+}
+
+const MarketFieldContract = "$contract"
+
+// ChainInstanceConfig one isolated indexing pipeline of a chain,
+// optional sections fall back to the top level ones
+type ChainInstanceConfig struct {
+	Scan    ScanConfig     `json:"scan"`
+	Chain   ChainConfig    `json:"chain"`
+	Filters *IndexFilter   `json:"filters"`
+	Stat    *StatConfig    `json:"stat"`
+	Archive *ArchiveConfig `json:"archive"`
+}
+
 type Config struct {
-	Scan     ScanConfig     `json:"scan"`
-	Chain    ChainConfig    `json:"chain"`
-	LogLevel string         `json:"log_level" mapstructure:"log_level"`
-	LogPath  string         `json:"log_path" mapstructure:"log_path"`
-	Filters  *IndexFilter   `json:"filters"`
-	Database DatabaseConfig `json:"database"`
-	Profile  *ProfileConfig `json:"profile"`
-	Stat     *StatConfig    `json:"stat"`
+	Scan     ScanConfig             `json:"scan"`
+	Chain    ChainConfig            `json:"chain"`
+	Chains   []*ChainInstanceConfig `json:"chains"`
+	LogLevel string                 `json:"log_level" mapstructure:"log_level"`
+	LogPath  string                 `json:"log_path" mapstructure:"log_path"`
+	Filters  *IndexFilter           `json:"filters"`
+	Database DatabaseConfig         `json:"database"`
+	Profile  *ProfileConfig         `json:"profile"`
+	Stat     *StatConfig            `json:"stat"`
+	Archive  *ArchiveConfig         `json:"archive"`
+	Status   *StatusConfig          `json:"status"`
+	Snapshot *SnapshotConfig        `json:"snapshot"`
+	Rules    []*RuleConfig          `json:"rules"`
+	Markets  []*MarketConfig        `json:"markets"`
+}
+
+// ChainConfigs returns the config of every chain to index,
+// the top level scan & chain sections are used if chains is empty
+func (c *Config) ChainConfigs() []*Config {
+	if len(c.Chains) < 1 {
+		return []*Config{c}
+	}
+
+	cfgs := make([]*Config, 0, len(c.Chains))
+	for _, item := range c.Chains {
+		cc := *c
+		cc.Chains = nil
+		cc.Scan = item.Scan
+		cc.Chain = item.Chain
+
+		if item.Filters != nil {
+			cc.Filters = item.Filters
+		}
+
+		if item.Stat != nil {
+			cc.Stat = item.Stat
+		}
+
+		if item.Archive != nil {
+			cc.Archive = item.Archive
+		}
+		cfgs = append(cfgs, &cc)
+	}
+	return cfgs
 }
 
 type RpcConfig struct {
@@ -0,0 +1,10 @@
+Use
+tap_indexer;
+
+-- ierc-20 pow mint tx hash prefix declared at deploy
+ALTER TABLE `inscriptions`
+    ADD COLUMN `workc` varchar(66) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT 'pow mint tx hash prefix';
+
+-- ierc-20 freeze platform allowed to spend the frozen utxo
+ALTER TABLE `utxos`
+    ADD COLUMN `spender` varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT 'utxo spender besides the owner';
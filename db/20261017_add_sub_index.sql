@@ -0,0 +1,13 @@
+Use
+tap_indexer;
+
+-- ops of the same tx, e.g. the fills of a batch exchange, are kept apart by sub_index
+ALTER TABLE `txs`
+    ADD COLUMN `sub_index` int unsigned NOT NULL DEFAULT 0 COMMENT 'op index in the tx' AFTER `tx_hash`,
+    ADD UNIQUE KEY `uk_chain_tx_hash_sub_index` (`chain`, `tx_hash`, `sub_index`);
+
+ALTER TABLE `address_txs`
+    ADD COLUMN `sub_index` int unsigned NOT NULL DEFAULT 0 COMMENT 'op index in the tx' AFTER `tx_hash`;
+
+ALTER TABLE `balance_txn`
+    ADD COLUMN `sub_index` int unsigned NOT NULL DEFAULT 0 COMMENT 'op index in the tx' AFTER `tx_hash`;
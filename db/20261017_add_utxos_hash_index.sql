@@ -0,0 +1,5 @@
+Use
+tap_indexer;
+
+CREATE INDEX idx_chain_root_hash ON utxos(chain, root_hash);
+CREATE INDEX idx_chain_tx_hash ON utxos(chain, tx_hash);
@@ -0,0 +1,7 @@
+Use
+tap_indexer;
+
+-- brc-20 transferable inscription location in the outputs of the inscribe tx
+ALTER TABLE `utxos`
+    ADD COLUMN `vout`       int unsigned    NOT NULL DEFAULT 0 COMMENT 'output holding the inscribed sat',
+    ADD COLUMN `sat_offset` bigint unsigned NOT NULL DEFAULT 0 COMMENT 'offset of the inscribed sat in the output';
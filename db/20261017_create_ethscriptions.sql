@@ -0,0 +1,46 @@
+Use
+tap_indexer;
+
+CREATE TABLE `ethscriptions`
+(
+    `id`              bigint unsigned NOT NULL AUTO_INCREMENT,
+    `chain`           varchar(32)     NOT NULL COMMENT 'chain name',
+    `ethscription_id` varchar(66)     NOT NULL COMMENT 'creation tx hash',
+    `creator`         varchar(128)    NOT NULL COMMENT 'creator address',
+    `initial_owner`   varchar(128)    NOT NULL COMMENT 'initial owner address',
+    `owner`           varchar(128)    NOT NULL COMMENT 'current owner address',
+    `previous_owner`  varchar(128)    NOT NULL COMMENT 'previous owner address',
+    `content_sha`     varchar(64)     NOT NULL COMMENT 'sha256 of the content uri',
+    `mimetype`        varchar(255)    NOT NULL COMMENT 'content mimetype',
+    `content_uri`     mediumtext      NOT NULL COMMENT 'data uri',
+    `block_height`    bigint unsigned NOT NULL COMMENT 'creation block height',
+    `block_time`      timestamp       NOT NULL COMMENT 'creation block time',
+    `created_at`      timestamp       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`      timestamp       NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uqx_chain_ethscription_id` (`chain`, `ethscription_id`),
+    UNIQUE KEY `uqx_chain_content_sha` (`chain`, `content_sha`),
+    KEY `idx_owner_chain` (`owner`, `chain`),
+    KEY `idx_chain_block_height` (`chain`, `block_height`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+CREATE TABLE `ethscription_transfers`
+(
+    `id`              bigint unsigned NOT NULL AUTO_INCREMENT,
+    `chain`           varchar(32)     NOT NULL COMMENT 'chain name',
+    `ethscription_id` varchar(66)     NOT NULL COMMENT 'creation tx hash',
+    `tx_hash`         varbinary(128)  NOT NULL COMMENT 'tx hash',
+    `from`            varchar(128)    NOT NULL COMMENT 'previous owner, the creator for creations',
+    `to`              varchar(128)    NOT NULL COMMENT 'new owner',
+    `log_index`       bigint          NOT NULL COMMENT 'event log index, -1 for calldata',
+    `block_height`    bigint unsigned NOT NULL COMMENT 'block height',
+    `block_time`      timestamp       NOT NULL COMMENT 'block time',
+    `created_at`      timestamp       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_chain_ethscription_id` (`chain`, `ethscription_id`),
+    KEY `idx_chain_block_height` (`chain`, `block_height`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
@@ -0,0 +1,42 @@
+Use
+tap_indexer;
+
+CREATE TABLE `block_gas`
+(
+    `id`               bigint unsigned NOT NULL AUTO_INCREMENT,
+    `chain`            varchar(32)     NOT NULL COMMENT 'chain name',
+    `block_height`     bigint unsigned NOT NULL COMMENT 'block height',
+    `block_time`       timestamp       NOT NULL COMMENT 'block time',
+    `tx_cnt`           bigint unsigned NOT NULL COMMENT 'inscription txs count',
+    `gas_used`         bigint unsigned NOT NULL COMMENT 'gas used',
+    `fee`              DECIMAL(38, 18) NOT NULL COMMENT 'effective fee in native units',
+    `median_gas_price` DECIMAL(38, 18) NOT NULL COMMENT 'median effective gas price',
+    `created_at`       timestamp       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       timestamp       NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uqx_chain_block` (`chain`, `block_height`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+CREATE TABLE `tick_gas`
+(
+    `id`               bigint unsigned NOT NULL AUTO_INCREMENT,
+    `chain`            varchar(32)     NOT NULL COMMENT 'chain name',
+    `protocol`         varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_bin NOT NULL COMMENT 'protocol name',
+    `tick`             varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_bin NOT NULL COMMENT 'inscription code',
+    `op`               varchar(32)     NOT NULL COMMENT 'op code',
+    `block_height`     bigint unsigned NOT NULL COMMENT 'block height',
+    `block_time`       timestamp       NOT NULL COMMENT 'block time',
+    `tx_cnt`           bigint unsigned NOT NULL COMMENT 'txs count',
+    `gas_used`         bigint unsigned NOT NULL COMMENT 'gas used',
+    `fee`              DECIMAL(38, 18) NOT NULL COMMENT 'effective fee in native units',
+    `median_gas_price` DECIMAL(38, 18) NOT NULL COMMENT 'median effective gas price',
+    `created_at`       timestamp       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`       timestamp       NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uqx_chain_block_tick_op` (`chain`, `block_height`, `protocol`, `tick`, `op`),
+    KEY `idx_chain_protocol_tick` (`chain`, `protocol`, `tick`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
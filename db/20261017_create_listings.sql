@@ -0,0 +1,31 @@
+Use
+tap_indexer;
+
+CREATE TABLE `listings`
+(
+    `id`                 bigint unsigned NOT NULL AUTO_INCREMENT,
+    `chain`              varchar(32)     NOT NULL COMMENT 'chain name',
+    `protocol`           varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_bin NOT NULL COMMENT 'protocol name',
+    `tick`               varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_bin NOT NULL COMMENT 'inscription code',
+    `list_id`            varchar(66)     NOT NULL COMMENT 'list tx hash',
+    `seller`             varchar(128)    NOT NULL COMMENT 'seller address',
+    `market`             varchar(128)    NOT NULL COMMENT 'marketplace contract address',
+    `amount`             DECIMAL(38, 18) NOT NULL COMMENT 'listed amount',
+    `price`              DECIMAL(65, 18) NOT NULL DEFAULT 0 COMMENT 'total price in wei',
+    `unit_price`         DECIMAL(65, 18) NOT NULL DEFAULT 0 COMMENT 'price per unit in wei',
+    `status`             tinyint(1)      NOT NULL COMMENT '1 open, 2 cancelled, 3 filled',
+    `buyer`              varchar(128)    NOT NULL DEFAULT '' COMMENT 'buyer address',
+    `block_height`       bigint unsigned NOT NULL COMMENT 'list block height',
+    `close_tx_hash`      varchar(66)     NOT NULL DEFAULT '' COMMENT 'fill / cancel tx hash',
+    `close_block_height` bigint unsigned NOT NULL DEFAULT 0 COMMENT 'fill / cancel block height',
+    `created_at`         timestamp       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`         timestamp       NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uqx_chain_list_id` (`chain`, `list_id`),
+    KEY `idx_chain_tick_status` (`chain`, `protocol`, `tick`, `status`),
+    KEY `idx_seller_chain_status` (`seller`, `chain`, `status`),
+    KEY `idx_chain_block_height` (`chain`, `block_height`),
+    KEY `idx_chain_close_block_height` (`chain`, `close_block_height`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
@@ -0,0 +1,26 @@
+Use
+tap_indexer;
+
+CREATE TABLE `rejected_txs`
+(
+    `id`           bigint unsigned NOT NULL AUTO_INCREMENT,
+    `chain`        varchar(32)     NOT NULL COMMENT 'chain name',
+    `protocol`     varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_bin NOT NULL COMMENT 'protocol name',
+    `tick`         varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_bin NOT NULL COMMENT 'inscription code',
+    `op`           varchar(32)     NOT NULL COMMENT 'op code',
+    `block_height` bigint unsigned NOT NULL COMMENT 'block height',
+    `block_time`   timestamp       NOT NULL COMMENT 'block time',
+    `tx_hash`      varbinary(128)  NOT NULL COMMENT 'tx hash',
+    `address`      varchar(128)    NOT NULL COMMENT 'tx sender',
+    `to`           varchar(128)    NOT NULL COMMENT 'to address',
+    `code`         int             NOT NULL COMMENT 'reject reason code',
+    `message`      varchar(512)    NOT NULL COMMENT 'reject reason',
+    `created_at`   timestamp       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`   timestamp       NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_tx_hash_chain` (`tx_hash`(12), `chain`(4)),
+    KEY `idx_address_chain` (`address`, `chain`),
+    KEY `idx_chain_block_height` (`chain`, `block_height`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
@@ -0,0 +1,25 @@
+Use
+tap_indexer;
+
+CREATE TABLE `trades`
+(
+    `id`           bigint unsigned NOT NULL AUTO_INCREMENT,
+    `chain`        varchar(32)     NOT NULL COMMENT 'chain name',
+    `protocol`     varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_bin NOT NULL COMMENT 'protocol name',
+    `tick`         varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_bin NOT NULL COMMENT 'inscription code',
+    `list_id`      varchar(66)     NOT NULL COMMENT 'list tx hash',
+    `tx_hash`      varchar(66)     NOT NULL COMMENT 'fill tx hash',
+    `seller`       varchar(128)    NOT NULL COMMENT 'seller address',
+    `buyer`        varchar(128)    NOT NULL COMMENT 'buyer address',
+    `amount`       DECIMAL(38, 18) NOT NULL COMMENT 'traded amount',
+    `price`        DECIMAL(65, 18) NOT NULL COMMENT 'total price in wei',
+    `unit_price`   DECIMAL(65, 18) NOT NULL COMMENT 'price per unit in wei',
+    `block_height` bigint unsigned NOT NULL COMMENT 'block height',
+    `block_time`   timestamp       NOT NULL COMMENT 'block time',
+    `created_at`   timestamp       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_chain_tick_block_time` (`chain`, `protocol`, `tick`, `block_time`),
+    KEY `idx_chain_block_height` (`chain`, `block_height`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
@@ -23,8 +23,10 @@
 package dcache
 
 import (
+	"container/list"
 	"fmt"
 	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/xylog"
 	"strings"
 	"sync"
 )
@@ -33,10 +35,21 @@ import (
 /*****************************************************
  * Build cache for all ticks all address balance
  * Mainly used for real-time verification of data
+ * Bounded by the least recently used eviction if size > 0,
+ * missing balances are read through the loader then.
+ * a failed load is reported by LoadErr, the block must be handled again
  ****************************************************/
 type Balance struct {
-	sid   uint64
-	ticks *sync.Map
+	mu        sync.Mutex
+	sid       uint64
+	items     map[string]*list.Element
+	lru       *list.List // front is the most recently used
+	size      int
+	loader    BalanceLoader
+	block     uint64 // block being handled
+	committed uint64 // last block flushed into db
+	loadErr   error  // first load error of the block being handled
+	stats     BalanceStats
 }
 
 type BalanceItem struct {
@@ -45,9 +58,39 @@ type BalanceItem struct {
 	Overall   decimal.Decimal
 }
 
+// BalanceLoader load the balance from db, nil if not exist
+type BalanceLoader func(protocol, tick, addr string) (*BalanceItem, error)
+
+// BalanceStats cache metrics for sizing
+type BalanceStats struct {
+	Size      int    `json:"size"`
+	Capacity  int    `json:"capacity"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Loads     uint64 `json:"loads"`
+	Evictions uint64 `json:"evictions"`
+}
+
+type balanceEntry struct {
+	idx   string
+	block uint64 // last changed block, can not be evicted till flushed into db
+	item  *BalanceItem
+}
+
 func NewBalance() *Balance {
+	return NewLRUBalance(0, nil)
+}
+
+// NewLRUBalance cache at most size balances, unlimited if size <= 0
+func NewLRUBalance(size int, loader BalanceLoader) *Balance {
+	if size < 0 {
+		size = 0
+	}
 	return &Balance{
-		ticks: &sync.Map{},
+		items:  make(map[string]*list.Element, size),
+		lru:    list.New(),
+		size:   size,
+		loader: loader,
 	}
 }
 
@@ -69,8 +112,14 @@ func (d *Balance) Update(protocol, tick string, addr string, b *BalanceItem) *Ba
 		return nil
 	}
 
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	balanceItem.Available = b.Available
 	balanceItem.Overall = b.Overall
+	if el, ok := d.items[d.idx(protocol, tick, addr)]; ok {
+		el.Value.(*balanceEntry).block = d.block
+	}
 	return balanceItem
 }
 
@@ -79,6 +128,9 @@ func (d *Balance) Update(protocol, tick string, addr string, b *BalanceItem) *Ba
  * create addr tick's balance
  ***************************************/
 func (d *Balance) Create(protocol, tick string, addr string, b *BalanceItem) *BalanceItem {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	if b.SID <= 0 {
 		d.sid++
 		b.SID = d.sid
@@ -89,29 +141,156 @@ func (d *Balance) Create(protocol, tick string, addr string, b *BalanceItem) *Ba
 		Available: b.Available,
 		Overall:   b.Overall,
 	}
+	d.store(d.idx(protocol, tick, addr), balanceItem, d.block)
+	return balanceItem
+}
+
+// store add or replace the item as the most recently used, evict the least recently used ones if full
+func (d *Balance) store(idx string, item *BalanceItem, block uint64) {
+	if el, ok := d.items[idx]; ok {
+		el.Value = &balanceEntry{idx: idx, block: block, item: item}
+		d.lru.MoveToFront(el)
+		return
+	}
+	d.items[idx] = d.lru.PushFront(&balanceEntry{idx: idx, block: block, item: item})
+
+	if d.size <= 0 {
+		return
+	}
+
+	// balances changed by the blocks not flushed are kept, db data is stale for them
+	for checked, n := 0, d.lru.Len(); checked < n && d.lru.Len() > d.size; checked++ {
+		el := d.lru.Back()
+		entry := el.Value.(*balanceEntry)
+		if entry.block > d.committed {
+			d.lru.MoveToFront(el)
+			continue
+		}
+		d.lru.Remove(el)
+		delete(d.items, entry.idx)
+		d.stats.Evictions++
+	}
+}
+
+// Delete
+/***************************************
+ * delete addr tick's balance
+ ***************************************/
+func (d *Balance) Delete(protocol, tick string, addr string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 
 	idx := d.idx(protocol, tick, addr)
-	d.ticks.Store(idx, balanceItem)
-	return balanceItem
+	if el, ok := d.items[idx]; ok {
+		d.lru.Remove(el)
+		delete(d.items, idx)
+	}
+}
+
+// DeleteTick
+/***************************************
+ * delete all address balances of the tick
+ ***************************************/
+func (d *Balance) DeleteTick(protocol, tick string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	prefix := d.idx(protocol, tick, "")
+	for idx, el := range d.items {
+		if strings.HasPrefix(idx, prefix) {
+			d.lru.Remove(el)
+			delete(d.items, idx)
+		}
+	}
 }
 
 // SetSid set auto_increment id
 func (d *Balance) SetSid(sid uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	if sid > d.sid {
 		d.sid = sid
 	}
 }
 
+// SetBlock the block being handled, balances changed are kept in cache till the block committed
+func (d *Balance) SetBlock(num uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.block = num
+	d.loadErr = nil
+}
+
+// LoadErr the first load error since the block began, the balances read are not reliable then
+func (d *Balance) LoadErr() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.loadErr
+}
+
+// Commit blocks <= num have been flushed into db, their balances can be evicted
+func (d *Balance) Commit(num uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if num > d.committed {
+		d.committed = num
+	}
+}
+
+// Stats cache metrics
+func (d *Balance) Stats() BalanceStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := d.stats
+	stats.Size = d.lru.Len()
+	stats.Capacity = d.size
+	return stats
+}
+
 // Get
 /***************************************
- * get addr tick's balance
+ * get addr tick's balance, read through the loader if not cached
  ***************************************/
 func (d *Balance) Get(protocol, tick string, addr string) (ok bool, val *BalanceItem) {
 	idx := d.idx(protocol, tick, addr)
-	balances, ok := d.ticks.Load(idx)
-	if !ok {
+
+	d.mu.Lock()
+	if el, ok := d.items[idx]; ok {
+		d.lru.MoveToFront(el)
+		d.stats.Hits++
+		d.mu.Unlock()
+		return true, el.Value.(*balanceEntry).item
+	}
+	d.stats.Misses++
+	d.mu.Unlock()
+
+	if d.loader == nil {
 		return false, nil
 	}
-	//addr = strings.ToLower(addr)
-	return true, balances.(*BalanceItem)
+
+	item, err := d.loader(protocol, tick, addr)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err != nil {
+		// missing balances can not be told from db errors, the block is reverted & handled again
+		xylog.Logger.Errorf("load balance[%s-%s-%s] err:%v", protocol, tick, addr, err)
+		if d.loadErr == nil {
+			d.loadErr = fmt.Errorf("load balance[%s-%s-%s] err:%w", protocol, tick, addr, err)
+		}
+		return false, nil
+	}
+	if item == nil {
+		return false, nil
+	}
+
+	// cached while loading
+	if el, ok := d.items[idx]; ok {
+		return true, el.Value.(*balanceEntry).item
+	}
+	d.stats.Loads++
+	d.store(idx, item, 0)
+	return true, item
 }
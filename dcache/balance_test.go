@@ -0,0 +1,112 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package dcache
+
+import (
+	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/xylog"
+	"testing"
+)
+
+func TestLRUBalance(t *testing.T) {
+	db := map[string]*BalanceItem{
+		"0xa1": {SID: 1, Available: decimal.NewFromInt(10), Overall: decimal.NewFromInt(10)},
+		"0xa2": {SID: 2, Available: decimal.NewFromInt(20), Overall: decimal.NewFromInt(20)},
+		"0xa3": {SID: 3, Available: decimal.NewFromInt(30), Overall: decimal.NewFromInt(30)},
+	}
+	loads := 0
+	b := NewLRUBalance(2, func(protocol, tick, addr string) (*BalanceItem, error) {
+		loads++
+		if item, ok := db[addr]; ok {
+			v := *item
+			return &v, nil
+		}
+		return nil, nil
+	})
+	b.SetSid(3)
+
+	// read through & evict the least recently used
+	ok, item := b.Get("brc-20", "ordi", "0xa1")
+	assert.True(t, ok)
+	assert.True(t, item.Overall.Equal(decimal.NewFromInt(10)))
+	b.Get("brc-20", "ordi", "0xa2")
+	b.Get("brc-20", "ordi", "0xa1")
+	b.Get("brc-20", "ordi", "0xa3")
+	assert.Equal(t, 3, loads)
+
+	stats := b.Stats()
+	assert.Equal(t, 2, stats.Size)
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Evictions)
+
+	ok, _ = b.Get("brc-20", "ordi", "0xb0")
+	assert.False(t, ok)
+
+	// balances changed by uncommitted blocks are kept
+	b.SetBlock(5)
+	b.Update("brc-20", "ordi", "0xa1", &BalanceItem{Available: decimal.NewFromInt(5), Overall: decimal.NewFromInt(5)})
+	created := b.Create("brc-20", "ordi", "0xb0", &BalanceItem{Available: decimal.NewFromInt(5), Overall: decimal.NewFromInt(5)})
+	assert.Equal(t, uint64(4), created.SID)
+	assert.Equal(t, 2, b.Stats().Size)
+
+	loads = 0
+	ok, item = b.Get("brc-20", "ordi", "0xa1")
+	assert.True(t, ok)
+	assert.True(t, item.Overall.Equal(decimal.NewFromInt(5)))
+	assert.Equal(t, 0, loads)
+
+	// evictable once flushed
+	b.Commit(5)
+	b.Get("brc-20", "ordi", "0xa2")
+	assert.Equal(t, 2, b.Stats().Size)
+	assert.Equal(t, 1, loads)
+}
+
+func TestBalanceLoadErr(t *testing.T) {
+	xylog.InitLog(logrus.DebugLevel, "")
+
+	var loadErr error
+	b := NewLRUBalance(2, func(protocol, tick, addr string) (*BalanceItem, error) {
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		return &BalanceItem{SID: 1, Available: decimal.NewFromInt(10), Overall: decimal.NewFromInt(10)}, nil
+	})
+
+	// failed loading is reported till the next block begins
+	b.SetBlock(5)
+	loadErr = errors.New("connection refused")
+	ok, _ := b.Get("brc-20", "ordi", "0xa1")
+	assert.False(t, ok)
+	assert.ErrorIs(t, b.LoadErr(), loadErr)
+
+	loadErr = nil
+	b.SetBlock(5)
+	assert.Nil(t, b.LoadErr())
+	ok, item := b.Get("brc-20", "ordi", "0xa1")
+	assert.True(t, ok)
+	assert.True(t, item.Overall.Equal(decimal.NewFromInt(10)))
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package dcache
+
+import (
+	"strings"
+	"sync"
+)
+
+// Ethscription
+/*****************************************************
+ * Build cache for all ethscriptions
+ * Mainly used for content uniqueness & owner checking
+ ****************************************************/
+type Ethscription struct {
+	ids  *sync.Map // ethscription id -> item
+	shas *sync.Map // content sha -> ethscription id
+}
+
+type EthscriptionItem struct {
+	Owner      string
+	ContentSha string
+}
+
+func NewEthscription() *Ethscription {
+	return &Ethscription{
+		ids:  &sync.Map{},
+		shas: &sync.Map{},
+	}
+}
+
+func (d *Ethscription) idx(id string) string {
+	return strings.ToLower(id)
+}
+
+// Create
+/***************************************
+ * Add new ethscription record
+ ***************************************/
+func (d *Ethscription) Create(id, contentSha, owner string) {
+	d.ids.Store(d.idx(id), &EthscriptionItem{
+		Owner:      owner,
+		ContentSha: contentSha,
+	})
+	d.shas.Store(contentSha, d.idx(id))
+}
+
+// Get
+/***************************************
+ * get ethscription record by id
+ ***************************************/
+func (d *Ethscription) Get(id string) (bool, *EthscriptionItem) {
+	item, ok := d.ids.Load(d.idx(id))
+	if !ok {
+		return false, nil
+	}
+	return true, item.(*EthscriptionItem)
+}
+
+// ContentExists whether the content has been ethscribed
+func (d *Ethscription) ContentExists(contentSha string) bool {
+	_, ok := d.shas.Load(contentSha)
+	return ok
+}
+
+// Transfer
+/***************************************
+ * update the owner of ethscription
+ ***************************************/
+func (d *Ethscription) Transfer(id, owner string) {
+	ok, item := d.Get(id)
+	if !ok {
+		return
+	}
+	d.ids.Store(d.idx(id), &EthscriptionItem{
+		Owner:      owner,
+		ContentSha: item.ContentSha,
+	})
+}
+
+// Delete
+/***************************************
+ * delete ethscription record with its content sha
+ ***************************************/
+func (d *Ethscription) Delete(id string) {
+	ok, item := d.Get(id)
+	if !ok {
+		return
+	}
+	d.shas.Delete(item.ContentSha)
+	d.ids.Delete(d.idx(id))
+}
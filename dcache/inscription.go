@@ -38,7 +38,7 @@ import (
 type Inscription struct {
 	sid       uint32
 	ticks     *sync.Map
-	tickNames *sync.Map // used for asc20
+	tickNames *sync.Map // keccak256(tick) -> tick, resolving the indexed tick strings of event logs
 }
 
 type Tick struct {
@@ -47,6 +47,7 @@ type Tick struct {
 	LimitPerMint decimal.Decimal
 	TotalSupply  decimal.Decimal
 	Decimals     int8
+	Workc        string
 }
 
 func NewInscription() *Inscription {
@@ -76,10 +77,29 @@ func (d *Inscription) Create(protocol, tick string, nt *Tick) {
 	idx := d.idx(protocol, tick)
 	d.ticks.Store(idx, nt)
 
-	// asc20 Add cache names
-	if protocol == "asc-20" {
+	// Add cache names, shared by the protocols deploying the same tick
+	key := utils.Keccak256(strings.ToLower(tick))
+	d.tickNames.Store(key, tick)
+}
+
+// Delete
+/***************************************
+ * delete tick's metadata
+ ***************************************/
+func (d *Inscription) Delete(protocol, tick string) {
+	idx := d.idx(protocol, tick)
+	d.ticks.Delete(idx)
+
+	// remove cache names if no other protocol deployed the tick
+	deployed := false
+	suffix := "_" + strings.ToLower(tick)
+	d.ticks.Range(func(k, _ any) bool {
+		deployed = strings.HasSuffix(k.(string), suffix)
+		return !deployed
+	})
+	if !deployed {
 		key := utils.Keccak256(strings.ToLower(tick))
-		d.tickNames.Store(key, tick)
+		d.tickNames.Delete(key)
 	}
 }
 
@@ -138,6 +138,15 @@ func (d *InscriptionStats) TxCnt(protocol, tick string, incr uint64) *InsStats {
 	return insStats
 }
 
+// Delete
+/***************************************
+ * delete tick's stats
+ ***************************************/
+func (d *InscriptionStats) Delete(protocol, tick string) {
+	idx := d.idx(protocol, tick)
+	d.ticks.Delete(idx)
+}
+
 // SetSid set auto_increment id
 func (d *InscriptionStats) SetSid(sid uint32) {
 	if sid > d.sid {
@@ -0,0 +1,187 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package dcache
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Journal
+/*****************************************************
+ * Record the cache entries before changed by each block,
+ * the changes of the blocks not flushed into db can be undone
+ ****************************************************/
+type Journal struct {
+	mu     sync.Mutex
+	blocks []*blockJournal // ordered by block number
+}
+
+type blockJournal struct {
+	num   uint64
+	saved map[string]struct{}
+	undo  []func()
+}
+
+func NewJournal() *Journal {
+	return &Journal{
+		blocks: make([]*blockJournal, 0, 16),
+	}
+}
+
+// Begin start recording the changes of the block
+func (j *Journal) Begin(num uint64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.blocks = append(j.blocks, &blockJournal{
+		num:   num,
+		saved: make(map[string]struct{}, 16),
+		undo:  make([]func(), 0, 16),
+	})
+}
+
+// save record the undo of the entry once per block, only the state before the block is kept
+func (j *Journal) save(key string, undo func()) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if len(j.blocks) < 1 {
+		return
+	}
+
+	b := j.blocks[len(j.blocks)-1]
+	if _, ok := b.saved[key]; ok {
+		return
+	}
+	b.saved[key] = struct{}{}
+	b.undo = append(b.undo, undo)
+}
+
+// Commit release the records of the blocks <= num, they have been flushed into db
+func (j *Journal) Commit(num uint64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	idx := 0
+	for idx < len(j.blocks) && j.blocks[idx].num <= num {
+		idx++
+	}
+	j.blocks = j.blocks[idx:]
+}
+
+// Revert undo the changes of the blocks > num in reverse order, returns the number of blocks reverted
+func (j *Journal) Revert(num uint64) int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	reverted := 0
+	for len(j.blocks) > 0 {
+		b := j.blocks[len(j.blocks)-1]
+		if b.num <= num {
+			break
+		}
+
+		for i := len(b.undo) - 1; i >= 0; i-- {
+			b.undo[i]()
+		}
+		j.blocks = j.blocks[:len(j.blocks)-1]
+		reverted++
+	}
+	return reverted
+}
+
+// Reset drop all records, e.g. the cache has been synced with db
+func (j *Journal) Reset() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.blocks = j.blocks[:0]
+}
+
+// SaveTick
+/***************************************
+ * record tick's metadata & stats before changed
+ ***************************************/
+func (h *Manager) SaveTick(protocol, tick string) {
+	key := fmt.Sprintf("tick_%s_%s", strings.ToLower(protocol), strings.ToLower(tick))
+	if ok, t := h.Inscription.Get(protocol, tick); ok {
+		item := *t
+		h.Journal.save(key, func() { h.Inscription.Create(protocol, tick, &item) })
+	} else {
+		h.Journal.save(key, func() { h.Inscription.Delete(protocol, tick) })
+	}
+
+	key = fmt.Sprintf("stats_%s_%s", strings.ToLower(protocol), strings.ToLower(tick))
+	if ok, s := h.InscriptionStats.Get(protocol, tick); ok {
+		item := *s
+		h.Journal.save(key, func() { h.InscriptionStats.Create(protocol, tick, &item) })
+	} else {
+		h.Journal.save(key, func() { h.InscriptionStats.Delete(protocol, tick) })
+	}
+}
+
+// SaveBalance
+/***************************************
+ * record addr tick's balance before changed
+ ***************************************/
+func (h *Manager) SaveBalance(protocol, tick, addr string) {
+	key := fmt.Sprintf("balance_%s", h.Balance.idx(protocol, tick, addr))
+	if ok, b := h.Balance.Get(protocol, tick, addr); ok {
+		item := *b
+		h.Journal.save(key, func() { h.Balance.Create(protocol, tick, addr, &item) })
+		return
+	}
+	h.Journal.save(key, func() { h.Balance.Delete(protocol, tick, addr) })
+}
+
+// SaveUTXO
+/***************************************
+ * record utxo before added or spent
+ ***************************************/
+func (h *Manager) SaveUTXO(txHash string) {
+	key := fmt.Sprintf("utxo_%s", h.UTXO.idx(txHash))
+	if ok, u := h.UTXO.Get(txHash); ok {
+		item := *u
+		h.Journal.save(key, func() {
+			h.UTXO.Add(item.Protocol, item.Tick, txHash, item.Owner, item.Amount, item.SN, item.Spender, item.Vout, item.Offset)
+		})
+		return
+	}
+	h.Journal.save(key, func() { h.UTXO.Delete(txHash) })
+}
+
+// SaveEthscription
+/***************************************
+ * record ethscription before created or transferred
+ ***************************************/
+func (h *Manager) SaveEthscription(id string) {
+	key := fmt.Sprintf("ethscription_%s", h.Ethscription.idx(id))
+	if ok, e := h.Ethscription.Get(id); ok {
+		item := *e
+		h.Journal.save(key, func() { h.Ethscription.Create(id, item.ContentSha, item.Owner) })
+		return
+	}
+	h.Journal.save(key, func() { h.Ethscription.Delete(id) })
+}
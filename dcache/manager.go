@@ -39,22 +39,52 @@ type Manager struct {
 	UTXO             *UTXO
 	Inscription      *Inscription
 	InscriptionStats *InscriptionStats
+	Ethscription     *Ethscription
+	Journal          *Journal // changes of the blocks not flushed into db yet
 }
 
 func NewManager(db *storage.DBClient, chain string) *Manager {
+	return NewBoundedManager(db, chain, 0)
+}
+
+// NewBoundedManager
+/***************************************
+ * at most balanceSize balances cached & the others read from db on demand,
+ * all balances are loaded at startup if balanceSize <= 0
+ ***************************************/
+func NewBoundedManager(db *storage.DBClient, chain string, balanceSize int) *Manager {
+	return NewSnapshotManager(db, chain, balanceSize, "")
+}
+
+// NewSnapshotManager
+/***************************************
+ * restore the cache from the snapshot file if taken at the last block in db,
+ * otherwise load it from db like NewBoundedManager
+ ***************************************/
+func NewSnapshotManager(db *storage.DBClient, chain string, balanceSize int, snapshotPath string) *Manager {
 	e := &Manager{
-		db:    db,
-		chain: chain,
+		db:      db,
+		chain:   chain,
+		Journal: NewJournal(),
 	}
 
 	if db == nil {
 		return e
 	}
 
+	if snapshotPath != "" && e.loadSnapshot(snapshotPath, balanceSize) {
+		return e
+	}
+
 	e.initInscriptionCache(chain)
 	e.initInscriptionStatsCache(chain)
-	e.initBalanceCache(chain)
-	e.initUtxoCache()
+	if balanceSize > 0 {
+		e.initBoundedBalanceCache(chain, balanceSize)
+	} else {
+		e.initBalanceCache(chain)
+	}
+	e.initUtxoCache(chain)
+	e.initEthscriptionCache(chain)
 	return e
 }
 
@@ -62,6 +92,22 @@ func (h *Manager) GetDataSource() *storage.DBClient {
 	return h.db
 }
 
+// Begin start handling the block, its cache changes are recorded till committed
+func (h *Manager) Begin(num uint64) {
+	h.Journal.Begin(num)
+	if h.Balance != nil {
+		h.Balance.SetBlock(num)
+	}
+}
+
+// Commit blocks <= num have been flushed into db
+func (h *Manager) Commit(num uint64) {
+	h.Journal.Commit(num)
+	if h.Balance != nil {
+		h.Balance.Commit(num)
+	}
+}
+
 func (h *Manager) initInscriptionCache(chain string) {
 	h.Inscription = NewInscription()
 
@@ -90,6 +136,7 @@ func (h *Manager) initInscriptionCache(chain string) {
 				LimitPerMint: v.LimitPerMint,
 				TotalSupply:  v.TotalSupply,
 				Decimals:     v.Decimals,
+				Workc:        v.Workc,
 			})
 
 			if v.SID > maxSid {
@@ -194,7 +241,28 @@ func (h *Manager) initBalanceCache(chain string) {
 	xylog.Logger.Infof("load balances data finished, cost ts:%v", time.Since(startTs))
 }
 
-func (h *Manager) initUtxoCache() {
+func (h *Manager) initBoundedBalanceCache(chain string, size int) {
+	h.Balance = NewLRUBalance(size, func(protocol, tick, addr string) (*BalanceItem, error) {
+		v, err := h.db.FindUserBalanceByTick(chain, protocol, tick, addr)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		return &BalanceItem{
+			SID:       v.SID,
+			Available: v.Available,
+			Overall:   v.Balance,
+		}, nil
+	})
+
+	maxSid, err := h.db.MaxBalanceSid(chain)
+	if err != nil {
+		xylog.Logger.Fatalf("failed to initialize balance cache sid. err:%v", err)
+	}
+	h.Balance.SetSid(maxSid)
+	xylog.Logger.Infof("balances loaded on demand, cache size[%d], max sid[%d]", size, maxSid)
+}
+
+func (h *Manager) initUtxoCache(chain string) {
 	h.UTXO = NewUTXO()
 
 	startTs := time.Now()
@@ -203,7 +271,7 @@ func (h *Manager) initUtxoCache() {
 	limit := 1000
 	xylog.Logger.Infof("load utxos data start...")
 	for {
-		utxos, err := h.db.GetUTXOsByIdLimit(start, limit)
+		utxos, err := h.db.GetUTXOsByIdLimit(chain, start, limit)
 		if err != nil {
 			xylog.Logger.Fatalf("failed to initialize utxos cache data. err:%v", err)
 		}
@@ -215,7 +283,7 @@ func (h *Manager) initUtxoCache() {
 		}
 
 		for _, v := range utxos {
-			h.UTXO.Add(v.Protocol, v.Tick, v.RootHash, v.Address, v.Amount, v.Sn)
+			h.UTXO.Add(v.Protocol, v.Tick, v.RootHash, v.Address, v.Amount, v.Sn, v.Spender, v.Vout, v.SatOffset)
 		}
 
 		//update id index
@@ -223,3 +291,33 @@ func (h *Manager) initUtxoCache() {
 	}
 	xylog.Logger.Infof("load utxos data finished, cost ts:%v", time.Since(startTs))
 }
+
+func (h *Manager) initEthscriptionCache(chain string) {
+	h.Ethscription = NewEthscription()
+
+	startTs := time.Now()
+	idx := 0
+	start := uint64(0)
+	limit := 10000
+	xylog.Logger.Infof("load ethscriptions data start...")
+	for {
+		items, err := h.db.GetEthscriptionsByIdLimit(chain, start, limit)
+		if err != nil {
+			xylog.Logger.Fatalf("failed to initialize ethscriptions cache data. err:%v", err)
+		}
+		idx++
+		xylog.Logger.Infof("load ethscriptions ret, items[%d], idx:%d", len(items), idx)
+
+		if len(items) <= 0 {
+			break
+		}
+
+		for _, v := range items {
+			h.Ethscription.Create(v.EthscriptionId, v.ContentSha, v.Owner)
+		}
+
+		//update id index
+		start = items[len(items)-1].ID
+	}
+	xylog.Logger.Infof("load ethscriptions data finished, cost ts:%v", time.Since(startTs))
+}
@@ -0,0 +1,266 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package dcache
+
+import (
+	"encoding/gob"
+	"fmt"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/xylog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// snapshotVersion snapshots of other versions are ignored, bump it once the snapshot data changed
+const snapshotVersion = 2
+
+// Snapshot
+/*****************************************************
+ * cache data equal to db at the block, restored at startup
+ * instead of loading all tables from db
+ ****************************************************/
+type Snapshot struct {
+	Version     int
+	Chain       string
+	BlockNumber uint64
+	BlockHash   string
+	CreatedAt   time.Time
+
+	InscriptionSid uint32
+	Inscriptions   map[string]Tick
+	TickNames      map[string]string
+	StatsSid       uint32
+	Stats          map[string]InsStats
+
+	// balances are loaded on demand by bounded caches, not included
+	AllBalances bool
+	BalanceSid  uint64
+	Balances    map[string]BalanceItem
+
+	UTXOs            map[string]UTXOItem
+	Ethscriptions    map[string]EthscriptionItem
+	EthscriptionShas map[string]string
+}
+
+// SnapshotPath the snapshot file of the chain in the dir
+func SnapshotPath(dir, chain string) string {
+	return filepath.Join(dir, fmt.Sprintf("%s.snapshot", chain))
+}
+
+// RemoveSnapshot remove the snapshot file, it must not be restored once the indexed data truncated
+func RemoveSnapshot(path string) error {
+	for _, item := range []string{path, path + ".tmp"} {
+		if err := os.Remove(item); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// TakeSnapshot
+/***************************************
+ * copy the cache tagged with the block status, the cache must not be changed
+ * while copying. it is only restored if db is at the same block later,
+ * so the block needs not be flushed yet
+ ***************************************/
+func (h *Manager) TakeSnapshot(status *model.BlockStatus) *Snapshot {
+	s := &Snapshot{
+		Version:     snapshotVersion,
+		Chain:       h.chain,
+		BlockNumber: status.BlockNumber,
+		BlockHash:   status.BlockHash,
+		CreatedAt:   time.Now(),
+
+		InscriptionSid: h.Inscription.sid,
+		Inscriptions:   make(map[string]Tick),
+		TickNames:      make(map[string]string),
+		StatsSid:       h.InscriptionStats.sid,
+		Stats:          make(map[string]InsStats),
+
+		UTXOs:            make(map[string]UTXOItem),
+		Ethscriptions:    make(map[string]EthscriptionItem),
+		EthscriptionShas: make(map[string]string),
+	}
+
+	h.Inscription.ticks.Range(func(k, v any) bool {
+		s.Inscriptions[k.(string)] = *v.(*Tick)
+		return true
+	})
+	h.Inscription.tickNames.Range(func(k, v any) bool {
+		s.TickNames[k.(string)] = v.(string)
+		return true
+	})
+	h.InscriptionStats.ticks.Range(func(k, v any) bool {
+		s.Stats[k.(string)] = *v.(*InsStats)
+		return true
+	})
+	h.UTXO.hashes.Range(func(k, v any) bool {
+		s.UTXOs[k.(string)] = *v.(*UTXOItem)
+		return true
+	})
+	if h.Ethscription != nil {
+		h.Ethscription.ids.Range(func(k, v any) bool {
+			s.Ethscriptions[k.(string)] = *v.(*EthscriptionItem)
+			return true
+		})
+		h.Ethscription.shas.Range(func(k, v any) bool {
+			s.EthscriptionShas[k.(string)] = v.(string)
+			return true
+		})
+	}
+
+	h.Balance.mu.Lock()
+	s.BalanceSid = h.Balance.sid
+	s.AllBalances = h.Balance.size <= 0
+	if s.AllBalances {
+		s.Balances = make(map[string]BalanceItem, len(h.Balance.items))
+		for idx, el := range h.Balance.items {
+			s.Balances[idx] = *el.Value.(*balanceEntry).item
+		}
+	}
+	h.Balance.mu.Unlock()
+	return s
+}
+
+// Write encode the snapshot into the file, the last snapshot is replaced once written completely
+func (s *Snapshot) Write(path string) error {
+	startTs := time.Now()
+	tmp := path + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err = gob.NewEncoder(f).Encode(s); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return err
+	}
+
+	xylog.Logger.Infof("write cache snapshot at block[%d] finished, cost ts:%v", s.BlockNumber, time.Since(startTs))
+	return nil
+}
+
+// readSnapshot read the snapshot file, nil if not usable at the block
+func (h *Manager) readSnapshot(path string, status *model.BlockStatus, allBalances bool) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	s := &Snapshot{}
+	if err = gob.NewDecoder(f).Decode(s); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case s.Version != snapshotVersion:
+		return nil, fmt.Errorf("version[%d] mismatched", s.Version)
+	case s.Chain != h.chain:
+		return nil, fmt.Errorf("chain[%s] mismatched", s.Chain)
+	case status == nil || s.BlockNumber != status.BlockNumber || s.BlockHash != status.BlockHash:
+		return nil, fmt.Errorf("block[%d-%s] mismatched with db", s.BlockNumber, s.BlockHash)
+	case allBalances && !s.AllBalances:
+		return nil, fmt.Errorf("balances not included")
+	}
+	return s, nil
+}
+
+// loadSnapshot
+/***************************************
+ * restore the cache from the snapshot file taken at the last block in db,
+ * returns false if not restored
+ ***************************************/
+func (h *Manager) loadSnapshot(path string, balanceSize int) bool {
+	startTs := time.Now()
+	status, err := h.db.QueryLastBlockStatus(h.chain)
+	if err != nil {
+		xylog.Logger.Fatalf("failed to load last block status. err:%v", err)
+	}
+
+	s, err := h.readSnapshot(path, status, balanceSize <= 0)
+	if err != nil {
+		xylog.Logger.Warnf("cache snapshot[%s] not loaded & load from db. err:%v", path, err)
+		return false
+	}
+
+	h.restoreSnapshot(s)
+	if balanceSize > 0 {
+		h.initBoundedBalanceCache(h.chain, balanceSize)
+	}
+	xylog.Logger.Infof("load cache snapshot at block[%d] finished, cost ts:%v", s.BlockNumber, time.Since(startTs))
+	return true
+}
+
+// restoreSnapshot replace the cache with the snapshot data
+func (h *Manager) restoreSnapshot(s *Snapshot) {
+	h.Inscription = NewInscription()
+	h.Inscription.sid = s.InscriptionSid
+	for idx, item := range s.Inscriptions {
+		t := item
+		h.Inscription.ticks.Store(idx, &t)
+	}
+	for key, name := range s.TickNames {
+		h.Inscription.tickNames.Store(key, name)
+	}
+
+	h.InscriptionStats = NewInscriptionStats()
+	h.InscriptionStats.sid = s.StatsSid
+	for idx, item := range s.Stats {
+		stats := item
+		h.InscriptionStats.ticks.Store(idx, &stats)
+	}
+
+	h.Balance = NewBalance()
+	h.Balance.sid = s.BalanceSid
+	for idx, item := range s.Balances {
+		balance := item
+		h.Balance.items[idx] = h.Balance.lru.PushFront(&balanceEntry{idx: idx, item: &balance})
+	}
+
+	h.UTXO = NewUTXO()
+	for idx, item := range s.UTXOs {
+		utxo := item
+		h.UTXO.hashes.Store(idx, &utxo)
+	}
+
+	h.Ethscription = NewEthscription()
+	for idx, item := range s.Ethscriptions {
+		e := item
+		h.Ethscription.ids.Store(idx, &e)
+	}
+	for sha, id := range s.EthscriptionShas {
+		h.Ethscription.shas.Store(sha, id)
+	}
+}
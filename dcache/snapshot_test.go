@@ -0,0 +1,107 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package dcache
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/utils"
+	"github.com/uxuycom/indexer/xylog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	xylog.InitLog(logrus.DebugLevel, "")
+
+	cache := NewManager(nil, "eth")
+	cache.Inscription = NewInscription()
+	cache.InscriptionStats = NewInscriptionStats()
+	cache.Balance = NewBalance()
+	cache.UTXO = NewUTXO()
+	cache.Ethscription = NewEthscription()
+
+	cache.Inscription.Create("erc-20", "Abcd", &Tick{TotalSupply: decimal.NewFromInt(1000)})
+	cache.InscriptionStats.Create("erc-20", "Abcd", &InsStats{Minted: decimal.NewFromInt(10), Holders: 1})
+	cache.Balance.Create("erc-20", "Abcd", "0xa1", &BalanceItem{Available: decimal.NewFromInt(10), Overall: decimal.NewFromInt(10)})
+	cache.UTXO.Add("erc-20", "Abcd", "0x01", "0xa1", decimal.NewFromInt(5), "sn", "", 0, 0)
+	cache.Ethscription.Create("0x02", "sha", "0xa1")
+
+	path := SnapshotPath(t.TempDir(), "eth")
+	status := &model.BlockStatus{Chain: "eth", BlockNumber: 10, BlockHash: "0x0a"}
+	assert.NoError(t, cache.TakeSnapshot(status).Write(path))
+	assert.Equal(t, "eth.snapshot", filepath.Base(path))
+
+	// mismatched db state
+	restored := NewManager(nil, "eth")
+	_, err := restored.readSnapshot(path, &model.BlockStatus{BlockNumber: 11, BlockHash: "0x0b"}, true)
+	assert.Error(t, err)
+	_, err = restored.readSnapshot(path, nil, true)
+	assert.Error(t, err)
+
+	s, err := restored.readSnapshot(path, status, true)
+	assert.NoError(t, err)
+	restored.restoreSnapshot(s)
+
+	ok, tick := restored.Inscription.Get("erc-20", "abcd")
+	assert.True(t, ok)
+	assert.Equal(t, uint32(1), tick.SID)
+	assert.True(t, tick.TotalSupply.Equal(decimal.NewFromInt(1000)))
+
+	ok, name := restored.Inscription.GetNameByIdx(utils.Keccak256("abcd"))
+	assert.True(t, ok)
+	assert.Equal(t, "Abcd", name)
+
+	_, stats := restored.InscriptionStats.Get("erc-20", "abcd")
+	assert.True(t, stats.Minted.Equal(decimal.NewFromInt(10)))
+
+	ok, balance := restored.Balance.Get("erc-20", "abcd", "0xA1")
+	assert.True(t, ok)
+	assert.True(t, balance.Overall.Equal(decimal.NewFromInt(10)))
+	assert.Equal(t, uint64(2), restored.Balance.Create("erc-20", "abcd", "0xb1", &BalanceItem{}).SID)
+
+	ok, utxo := restored.UTXO.Get("0x01")
+	assert.True(t, ok)
+	assert.Equal(t, "0xa1", utxo.Owner)
+
+	assert.True(t, restored.Ethscription.ContentExists("sha"))
+}
+
+func TestRemoveSnapshot(t *testing.T) {
+	path := SnapshotPath(t.TempDir(), "eth")
+	for _, item := range []string{path, path + ".tmp"} {
+		assert.NoError(t, os.WriteFile(item, []byte("stale"), 0o644))
+	}
+
+	assert.NoError(t, RemoveSnapshot(path))
+	for _, item := range []string{path, path + ".tmp"} {
+		_, err := os.Stat(item)
+		assert.True(t, os.IsNotExist(err))
+	}
+
+	// nothing to remove
+	assert.NoError(t, RemoveSnapshot(path))
+}
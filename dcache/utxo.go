@@ -43,6 +43,9 @@ type UTXOItem struct {
 	Amount   decimal.Decimal
 	Owner    string
 	SN       string
+	Spender  string
+	Vout     uint32 // output of the tx holding the inscribed sat, btc only
+	Offset   uint64 // offset of the inscribed sat in the output, btc only
 }
 
 func NewUTXO() *UTXO {
@@ -62,7 +65,7 @@ func (d *UTXO) idx(txHash string) string {
 /***************************************
  * Add new utxo record
  ***************************************/
-func (d *UTXO) Add(protocol, tick, txHash, address string, amount decimal.Decimal, sn string) {
+func (d *UTXO) Add(protocol, tick, txHash, address string, amount decimal.Decimal, sn, spender string, vout uint32, offset uint64) {
 	idx := d.idx(txHash)
 	d.hashes.Store(idx, &UTXOItem{
 		Protocol: protocol,
@@ -70,6 +73,9 @@ func (d *UTXO) Add(protocol, tick, txHash, address string, amount decimal.Decima
 		Amount:   amount,
 		Owner:    address,
 		SN:       sn,
+		Spender:  spender,
+		Vout:     vout,
+		Offset:   offset,
 	})
 }
 
@@ -85,3 +91,12 @@ func (d *UTXO) Get(txHash string) (bool, *UTXOItem) {
 	}
 	return true, item.(*UTXOItem)
 }
+
+// Delete
+/***************************************
+ * delete utxo record once spent
+ ***************************************/
+func (d *UTXO) Delete(txHash string) {
+	idx := d.idx(txHash)
+	d.hashes.Delete(idx)
+}
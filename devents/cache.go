@@ -25,6 +25,7 @@ package devents
 import (
 	"github.com/shopspring/decimal"
 	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/model"
 	"github.com/uxuycom/indexer/xylog"
 )
 
@@ -39,6 +40,13 @@ func NewTxResultHandler(cache *dcache.Manager) *TxResultHandler {
 }
 
 func (tc *TxResultHandler) UpdateCache(r *TxResult) {
+	tc.saveCache(r)
+
+	if r.Ethscription != nil {
+		tc.updateEthscriptionCache(r)
+		return
+	}
+
 	if r.Deploy != nil {
 		tc.updateDeployCache(r)
 	}
@@ -47,17 +55,58 @@ func (tc *TxResultHandler) UpdateCache(r *TxResult) {
 		tc.updateMintCache(r)
 	}
 
+	if r.Inscribe != nil {
+		tc.updateInscribeCache(r)
+	}
+
 	if r.Transfer != nil {
 		tc.updateTransferCache(r)
 	}
 }
 
+// saveCache record the cache entries to be changed by the result into the block journal
+func (tc *TxResultHandler) saveCache(r *TxResult) {
+	if r.Ethscription != nil {
+		tc.cache.SaveEthscription(r.Ethscription.Id)
+		return
+	}
+
+	tc.cache.SaveTick(r.MD.Protocol, r.MD.Tick)
+	if r.Mint != nil {
+		tc.cache.SaveBalance(r.MD.Protocol, r.MD.Tick, r.Mint.Minter)
+	}
+
+	if r.Inscribe != nil {
+		tc.cache.SaveBalance(r.MD.Protocol, r.MD.Tick, r.Inscribe.Owner)
+		tc.cache.SaveUTXO(r.Tx.Hash)
+	}
+
+	if r.Transfer != nil {
+		if r.Transfer.UTXO != "" {
+			tc.cache.SaveUTXO(r.Transfer.UTXO)
+		}
+
+		tc.cache.SaveBalance(r.MD.Protocol, r.MD.Tick, r.Transfer.Sender)
+		for _, item := range r.Transfer.Receives {
+			tc.cache.SaveBalance(r.MD.Protocol, r.MD.Tick, item.Address)
+		}
+	}
+}
+
+// lockable the tick's available balance is tracked apart from the overall balance, locked by inscribe-transfer
+func (tc *TxResultHandler) lockable(protocol, tick string) bool {
+	ok, t := tc.cache.Inscription.Get(protocol, tick)
+	return ok && t.TransferType == model.TransferTypeHash
+}
+
 func (tc *TxResultHandler) updateDeployCache(r *TxResult) {
 	//Add new tick
 	t := &dcache.Tick{
 		LimitPerMint: r.Deploy.MintLimit,
 		TotalSupply:  r.Deploy.MaxSupply,
 		Decimals:     r.Deploy.Decimal,
+		TransferType: r.Deploy.TransferType,
+		Workc:        r.Deploy.Workc,
 	}
 	tc.cache.Inscription.Create(r.MD.Protocol, r.MD.Tick, t)
 
@@ -73,11 +122,17 @@ func (tc *TxResultHandler) updateMintCache(r *TxResult) {
 	tc.cache.InscriptionStats.Mint(r.MD.Protocol, r.MD.Tick, r.Mint.Amount)
 	tc.cache.InscriptionStats.TxCnt(r.MD.Protocol, r.MD.Tick, 1)
 
+	available := decimal.Zero
+	if tc.lockable(r.MD.Protocol, r.MD.Tick) {
+		available = r.Mint.Amount
+	}
+
 	//Update minter balances
 	ok, balance := tc.cache.Balance.Get(r.MD.Protocol, r.MD.Tick, r.Mint.Minter)
 	if !ok {
 		tc.cache.Balance.Create(r.MD.Protocol, r.MD.Tick, r.Mint.Minter, &dcache.BalanceItem{
-			Overall: r.Mint.Amount,
+			Available: available,
+			Overall:   r.Mint.Amount,
 		})
 		tc.cache.InscriptionStats.Holders(r.MD.Protocol, r.MD.Tick, 1)
 
@@ -90,11 +145,30 @@ func (tc *TxResultHandler) updateMintCache(r *TxResult) {
 
 		amount := balance.Overall.Add(r.Mint.Amount)
 		tc.cache.Balance.Update(r.MD.Protocol, r.MD.Tick, r.Mint.Minter, &dcache.BalanceItem{
-			Overall: amount,
+			Available: balance.Available.Add(available),
+			Overall:   amount,
 		})
 	}
 }
 
+func (tc *TxResultHandler) updateInscribeCache(r *TxResult) {
+	//Update transfer stats
+	tc.cache.InscriptionStats.TxCnt(r.MD.Protocol, r.MD.Tick, 1)
+
+	//Lock owner available balances, the overall balance is moved once the utxo spent
+	ok, balance := tc.cache.Balance.Get(r.MD.Protocol, r.MD.Tick, r.Inscribe.Owner)
+	if !ok {
+		// failed loading, the block is handled again
+		xylog.Logger.Errorf("owner balance not found, tick[%s-%s], address[%s]", r.MD.Protocol, r.MD.Tick, r.Inscribe.Owner)
+		return
+	}
+	tc.cache.Balance.Update(r.MD.Protocol, r.MD.Tick, r.Inscribe.Owner, &dcache.BalanceItem{
+		Available: balance.Available.Sub(r.Inscribe.Amount),
+		Overall:   balance.Overall,
+	})
+	tc.cache.UTXO.Add(r.MD.Protocol, r.MD.Tick, r.Tx.Hash, r.Inscribe.Owner, r.Inscribe.Amount, r.Inscribe.Sn, r.Inscribe.Spender, r.Inscribe.Vout, r.Inscribe.Offset)
+}
+
 func (tc *TxResultHandler) updateTransferCache(r *TxResult) {
 	//Update transfer stats
 	tc.cache.InscriptionStats.TxCnt(r.MD.Protocol, r.MD.Tick, 1)
@@ -105,24 +179,47 @@ func (tc *TxResultHandler) updateTransferCache(r *TxResult) {
 		sendTotalAmount = sendTotalAmount.Add(item.Amount)
 	}
 
+	lockable := tc.lockable(r.MD.Protocol, r.MD.Tick)
+	if r.Transfer.UTXO != "" {
+		tc.cache.UTXO.Delete(r.Transfer.UTXO)
+	}
+
 	holders := int64(0)
-	_, senderBalance := tc.cache.Balance.Get(r.MD.Protocol, r.MD.Tick, r.Transfer.Sender)
+	ok, senderBalance := tc.cache.Balance.Get(r.MD.Protocol, r.MD.Tick, r.Transfer.Sender)
+	if !ok {
+		// failed loading, the block is handled again
+		xylog.Logger.Errorf("sender balance not found, tick[%s-%s], address[%s]", r.MD.Protocol, r.MD.Tick, r.Transfer.Sender)
+		return
+	}
 	senderAmount := senderBalance.Overall.Sub(sendTotalAmount)
 	if senderAmount.LessThanOrEqual(decimal.Zero) {
 		holders--
 	}
+
+	// available balance of spent utxo has been locked at inscribing
+	senderAvailable := senderBalance.Available
+	if lockable && r.Transfer.UTXO == "" {
+		senderAvailable = senderAvailable.Sub(sendTotalAmount)
+	}
 	tc.cache.Balance.Update(r.MD.Protocol, r.MD.Tick, r.Transfer.Sender, &dcache.BalanceItem{
-		Overall: senderAmount,
+		Available: senderAvailable,
+		Overall:   senderAmount,
 	})
 
 	for _, item := range r.Transfer.Receives {
+		available := decimal.Zero
+		if lockable {
+			available = item.Amount
+		}
+
 		ok, receiveBalance := tc.cache.Balance.Get(r.MD.Protocol, r.MD.Tick, item.Address)
 		if !ok {
 			holders++
 
 			receiveAmount := item.Amount
 			tc.cache.Balance.Create(r.MD.Protocol, r.MD.Tick, item.Address, &dcache.BalanceItem{
-				Overall: receiveAmount,
+				Available: available,
+				Overall:   receiveAmount,
 			})
 
 			//mark minter init
@@ -134,7 +231,8 @@ func (tc *TxResultHandler) updateTransferCache(r *TxResult) {
 
 			receiveAmount := receiveBalance.Overall.Add(item.Amount)
 			tc.cache.Balance.Update(r.MD.Protocol, r.MD.Tick, item.Address, &dcache.BalanceItem{
-				Overall: receiveAmount,
+				Available: receiveBalance.Available.Add(available),
+				Overall:   receiveAmount,
 			})
 		}
 	}
@@ -145,3 +243,57 @@ func (tc *TxResultHandler) updateTransferCache(r *TxResult) {
 	xylog.Logger.Infof("update tick[%s] holders. holders[%d]", r.MD.Tick, holders)
 	tc.cache.InscriptionStats.Holders(r.MD.Protocol, r.MD.Tick, holders)
 }
+
+func (tc *TxResultHandler) updateEthscriptionCache(r *TxResult) {
+	if r.MD.Operate == OperateCreate {
+		tc.cache.Ethscription.Create(r.Ethscription.Id, r.Ethscription.ContentSha, r.Ethscription.To)
+		return
+	}
+	tc.cache.Ethscription.Transfer(r.Ethscription.Id, r.Ethscription.To)
+}
+
+// Rollback sync the cache with the data reverted by DEvent.Rollback
+func (tc *TxResultHandler) Rollback(rm *RollbackModels) {
+	for _, item := range rm.Inscriptions {
+		tc.cache.Inscription.Delete(item.Protocol, item.Tick)
+		tc.cache.InscriptionStats.Delete(item.Protocol, item.Tick)
+		tc.cache.Balance.DeleteTick(item.Protocol, item.Tick)
+	}
+
+	for _, item := range rm.InscriptionStats {
+		tc.cache.InscriptionStats.Create(item.Protocol, item.Tick, &dcache.InsStats{
+			SID:     item.SID,
+			Minted:  item.Minted,
+			Holders: int64(item.Holders),
+			TxCnt:   item.TxCnt,
+		})
+	}
+
+	for _, item := range rm.Balances[DBActionUpdate] {
+		tc.cache.Balance.Create(item.Protocol, item.Tick, item.Address, &dcache.BalanceItem{
+			SID:       item.SID,
+			Available: item.Available,
+			Overall:   item.Balance,
+		})
+	}
+
+	for _, item := range rm.Balances[DBActionDelete] {
+		tc.cache.Balance.Delete(item.Protocol, item.Tick, item.Address)
+	}
+
+	for _, item := range rm.UTXOs[DBActionUpdate] {
+		tc.cache.UTXO.Add(item.Protocol, item.Tick, item.RootHash, item.Address, item.Amount, item.Sn, item.Spender, item.Vout, item.SatOffset)
+	}
+
+	for _, item := range rm.UTXOs[DBActionDelete] {
+		tc.cache.UTXO.Delete(item.RootHash)
+	}
+
+	for _, item := range rm.Ethscriptions[DBActionUpdate] {
+		tc.cache.Ethscription.Transfer(item.EthscriptionId, item.Owner)
+	}
+
+	for _, item := range rm.Ethscriptions[DBActionDelete] {
+		tc.cache.Ethscription.Delete(item.EthscriptionId)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package devents
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/xylog"
+	"testing"
+)
+
+func TestTxResultHandler_RevertCache(t *testing.T) {
+	xylog.InitLog(logrus.DebugLevel, "")
+
+	cache := dcache.NewManager(nil, "eth")
+	cache.Balance = dcache.NewBalance()
+	cache.UTXO = dcache.NewUTXO()
+	cache.Inscription = dcache.NewInscription()
+	cache.InscriptionStats = dcache.NewInscriptionStats()
+	tc := NewTxResultHandler(cache)
+
+	md := &MetaData{Chain: "eth", Protocol: "erc-20", Tick: "abcd"}
+	tx := &xycommon.RpcTransaction{Hash: "0x01"}
+
+	// block 1: deploy & mint
+	cache.Journal.Begin(1)
+	tc.UpdateCache(&TxResult{MD: md, Tx: tx, Deploy: &Deploy{MaxSupply: decimal.NewFromInt(1000), MintLimit: decimal.NewFromInt(100)}})
+	tc.UpdateCache(&TxResult{MD: md, Tx: tx, Mint: &Mint{Minter: "0xa1", Amount: decimal.NewFromInt(100)}})
+
+	// block 2: mint & transfer
+	cache.Journal.Begin(2)
+	tc.UpdateCache(&TxResult{MD: md, Tx: tx, Mint: &Mint{Minter: "0xa1", Amount: decimal.NewFromInt(100)}})
+	tc.UpdateCache(&TxResult{MD: md, Tx: tx, Transfer: &Transfer{
+		Sender:   "0xa1",
+		Receives: []*Receive{{Address: "0xb1", Amount: decimal.NewFromInt(50)}},
+	}})
+
+	ok, balance := cache.Balance.Get("erc-20", "abcd", "0xa1")
+	assert.True(t, ok)
+	assert.True(t, balance.Overall.Equal(decimal.NewFromInt(150)))
+
+	// block 1 flushed, block 2 reverted
+	cache.Journal.Commit(1)
+	assert.Equal(t, 1, cache.Journal.Revert(1))
+
+	ok, balance = cache.Balance.Get("erc-20", "abcd", "0xa1")
+	assert.True(t, ok)
+	assert.True(t, balance.Overall.Equal(decimal.NewFromInt(100)))
+
+	ok, _ = cache.Balance.Get("erc-20", "abcd", "0xb1")
+	assert.False(t, ok)
+
+	_, stats := cache.InscriptionStats.Get("erc-20", "abcd")
+	assert.True(t, stats.Minted.Equal(decimal.NewFromInt(100)))
+	assert.Equal(t, int64(1), stats.Holders)
+	assert.Equal(t, uint64(2), stats.TxCnt)
+
+	// committed blocks are kept
+	assert.Equal(t, 0, cache.Journal.Revert(0))
+	ok, _ = cache.Inscription.Get("erc-20", "abcd")
+	assert.True(t, ok)
+}
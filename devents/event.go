@@ -24,10 +24,12 @@ package devents
 
 import (
 	"context"
+	"github.com/uxuycom/indexer/model"
 	"github.com/uxuycom/indexer/storage"
 	"github.com/uxuycom/indexer/xylog"
 	"gorm.io/gorm"
 	"math/rand"
+	"sync/atomic"
 	"time"
 )
 
@@ -38,12 +40,23 @@ type Event struct {
 	BlockTime uint64
 	BlockHash string
 	Items     []*DBModelEvent
+	BlockGas  *model.BlockGas
+	TickGas   []*model.TickGas
+	Rejects   []*model.RejectedTx
 }
 
+// maxSinkRetries times of flushing the failed events again before dropping them
+const maxSinkRetries = 3
+
 type DEvent struct {
-	ctx    context.Context
-	events chan *Event
-	db     *storage.DBClient
+	ctx       context.Context
+	events    chan *Event
+	db        *storage.DBClient
+	pending   atomic.Int64  // events written but not yet flushed into db
+	committed atomic.Uint64 // last block flushed into db
+	failed    atomic.Bool   // events dropped after flushing failed, cleared by Resume
+	retrying  []*Event      // events failed flushing, retried with the same data
+	retries   int
 }
 
 func NewDEvents(ctx context.Context, db *storage.DBClient) *DEvent {
@@ -55,9 +68,21 @@ func NewDEvents(ctx context.Context, db *storage.DBClient) *DEvent {
 }
 
 func (h *DEvent) WriteDBAsync(e *Event) {
+	h.pending.Add(1)
 	h.events <- e
 }
 
+// WaitFlushed blocks until all written events have been flushed into db
+func (h *DEvent) WaitFlushed(ctx context.Context) {
+	for h.pending.Load() > 0 {
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 func (h *DEvent) Read(num int) (items []*Event) {
 	items = make([]*Event, 0, num)
 	for i := 0; i < num; i++ {
@@ -79,15 +104,41 @@ func (h *DEvent) Flush() {
 	for {
 		select {
 		case <-t.C:
-			if !h.Sink(h.db) {
-				return
-			}
+			h.Sink(h.db)
 		case <-h.ctx.Done():
 			return
 		}
 	}
 }
 
+// CommittedBlock the last block flushed into db by this instance, 0 if none
+func (h *DEvent) CommittedBlock() uint64 {
+	return h.committed.Load()
+}
+
+// Failed
+/***************************************
+ * events have been dropped after flushing failed, the cache is ahead of db.
+ * all events written are dropped till Resume called
+ ***************************************/
+func (h *DEvent) Failed() bool {
+	return h.failed.Load()
+}
+
+// Resume flush the events written again, the cache has been reverted to the last committed block
+func (h *DEvent) Resume() {
+	h.failed.Store(false)
+}
+
+// drop discard the events, they are written again after the cache reverted
+func (h *DEvent) drop(events []*Event) {
+	if len(events) < 1 {
+		return
+	}
+	h.pending.Add(-int64(len(events)))
+	xylog.Logger.Warnf("drop events, blocks[%d-%d]", events[0].BlockNum, events[len(events)-1].BlockNum)
+}
+
 // getDBLockTillSuccess get db lock until success,
 func (h *DEvent) getDBLockTillSuccess(db *storage.DBClient) {
 	startTs := time.Now()
@@ -116,7 +167,7 @@ func (h *DEvent) getDBLockTillSuccess(db *storage.DBClient) {
 // releaseDBLock release db lock
 func (h *DEvent) releaseDBLock(db *storage.DBClient) {
 	for {
-		_, err := db.ReleaseLock()
+		err := db.ReleaseLock()
 		if err != nil {
 			xylog.Logger.Errorf("failed to get block status & retry after 1s. err=%s", err)
 			<-time.After(time.Second)
@@ -128,8 +179,17 @@ func (h *DEvent) releaseDBLock(db *storage.DBClient) {
 }
 
 func (h *DEvent) Sink(db *storage.DBClient) bool {
-	//get events from channel
-	events := h.Read(100)
+	// drop all events till the cache reverted
+	if h.failed.Load() {
+		h.drop(h.Read(cap(h.events)))
+		return true
+	}
+
+	//get events from channel, the failed events are retried first
+	events := h.retrying
+	if len(events) < 1 {
+		events = h.Read(100)
+	}
 
 	// merge events data
 	if len(events) < 1 {
@@ -250,6 +310,68 @@ func (h *DEvent) Sink(db *storage.DBClient) bool {
 			}
 		}
 
+		// insert transferable utxos before spending, they may be spent in the same batch
+		if err := db.BatchAddUTXOs(tx, dm.UTXOs[DBActionCreate]); err != nil {
+			xylog.Logger.Errorf("failed insert utxo records. err=%s", err)
+			return err
+		}
+
+		if err := db.SpendUTXOs(tx, chain, dm.UTXOs[DBActionUpdate]); err != nil {
+			xylog.Logger.Errorf("failed update utxo records. err=%s", err)
+			return err
+		}
+
+		// insert listings before closing, they may be closed in the same batch
+		if err := db.BatchAddListings(tx, dm.Listings[DBActionCreate]); err != nil {
+			xylog.Logger.Errorf("failed insert listing records. err=%s", err)
+			return err
+		}
+
+		if err := db.CloseListings(tx, chain, dm.Listings[DBActionUpdate]); err != nil {
+			xylog.Logger.Errorf("failed update listing records. err=%s", err)
+			return err
+		}
+
+		if err := db.BatchAddTrades(tx, dm.Trades); err != nil {
+			xylog.Logger.Errorf("failed insert trade records. err=%s", err)
+			return err
+		}
+
+		// insert ethscriptions before transferring, they may be transferred in the same batch
+		if err := db.BatchAddEthscriptions(tx, dm.Ethscriptions[DBActionCreate]); err != nil {
+			xylog.Logger.Errorf("failed insert ethscription records. err=%s", err)
+			return err
+		}
+
+		if err := db.UpdateEthscriptionOwners(tx, chain, dm.Ethscriptions[DBActionUpdate]); err != nil {
+			xylog.Logger.Errorf("failed update ethscription owners. err=%s", err)
+			return err
+		}
+
+		if err := db.BatchAddEthscriptionTransfers(tx, dm.EthscriptionTransfers); err != nil {
+			xylog.Logger.Errorf("failed insert ethscription transfer records. err=%s", err)
+			return err
+		}
+
+		// insert rejected txs
+		if len(dm.Rejects) > 0 {
+			if err := db.BatchAddRejectedTxs(tx, dm.Rejects); err != nil {
+				xylog.Logger.Errorf("failed insert rejected tx records. err=%s", err)
+				return err
+			}
+		}
+
+		// insert gas stats
+		if err := db.BatchAddBlockGas(tx, dm.BlockGas); err != nil {
+			xylog.Logger.Errorf("failed insert block gas records. err=%s", err)
+			return err
+		}
+
+		if err := db.BatchAddTickGas(tx, dm.TickGas); err != nil {
+			xylog.Logger.Errorf("failed insert tick gas records. err=%s", err)
+			return err
+		}
+
 		// record block status
 		if err := db.SaveLastBlock(tx, dm.BlockStatus); err != nil {
 			xylog.Logger.Errorf("failed to save block information. err=%s", err)
@@ -259,9 +381,32 @@ func (h *DEvent) Sink(db *storage.DBClient) bool {
 	})
 
 	if err != nil {
-		xylog.Logger.Errorf("flush db error. err=%s, cost:%v", err, time.Since(startTs))
+		xylog.Logger.Errorf("flush db error. err=%s, cost:%v, retries[%d]", err, time.Since(startTs), h.retries)
+		h.onSinkFailed(events)
 		return false
 	}
+	h.retrying, h.retries = nil, 0
+	h.committed.Store(dm.BlockStatus.BlockNumber)
+	h.pending.Add(-int64(len(events)))
 	xylog.Logger.Infof("flush db success, cost:%v", time.Since(startTs))
 	return true
 }
+
+// onSinkFailed
+/***************************************
+ * retry the failed events with the same data later,
+ * drop them with the following events once retries exhausted
+ ***************************************/
+func (h *DEvent) onSinkFailed(events []*Event) {
+	if h.retries < maxSinkRetries {
+		h.retrying = events
+		h.retries++
+		return
+	}
+
+	xylog.Logger.Errorf("flush db failed after %d retries, drop events & wait cache reverted to block[%d]", h.retries, h.committed.Load())
+	h.retrying, h.retries = nil, 0
+	h.failed.Store(true)
+	h.drop(events)
+	h.drop(h.Read(cap(h.events)))
+}
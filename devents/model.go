@@ -24,6 +24,7 @@ package devents
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/shopspring/decimal"
 	"github.com/uxuycom/indexer/model"
@@ -36,6 +37,7 @@ type DBAction string
 const (
 	DBActionCreate DBAction = "create"
 	DBActionUpdate DBAction = "update"
+	DBActionDelete DBAction = "delete"
 )
 
 type DBModelEvent struct {
@@ -45,16 +47,32 @@ type DBModelEvent struct {
 	Balances         map[DBAction][]*model.Balances
 	AddressTxs       []*model.AddressTxs
 	BalanceTxs       []*model.BalanceTxn
+	UTXOs            map[DBAction]*model.UTXO
+	Listings         map[DBAction]*model.Listing
+	Trade            *model.Trade
+
+	Ethscriptions        map[DBAction]*model.Ethscription
+	EthscriptionTransfer *model.EthscriptionTransfer
 }
 
 func (tc *TxResultHandler) BuildModel(r *TxResult) *DBModelEvent {
 	dm := &DBModelEvent{}
 
 	dm.Tx = tc.BuildTx(r)
+
+	// ethscriptions have no tick & balances
+	if r.Ethscription != nil {
+		dm.Ethscriptions, dm.EthscriptionTransfer = tc.BuildEthscription(r)
+		return dm
+	}
+
 	dm.Inscriptions = tc.BuildInscription(r)
 	dm.InscriptionStats = tc.BuildInscriptionStat(r)
 	dm.BalanceTxs, dm.Balances = tc.BuildBalance(r)
 	dm.AddressTxs = tc.BuildAddressTxs(r)
+	dm.UTXOs = tc.BuildUTXO(r)
+	dm.Listings = tc.BuildListing(r)
+	dm.Trade = tc.BuildTrade(r)
 	return dm
 }
 
@@ -77,10 +95,149 @@ func (tc *TxResultHandler) BuildInscription(e *TxResult) map[DBAction]*model.Ins
 		DeployHash:   e.Tx.Hash,
 		DeployTime:   time.Unix(int64(e.Block.Time), 0),
 		Decimals:     e.Deploy.Decimal,
+		TransferType: e.Deploy.TransferType,
+		Workc:        e.Deploy.Workc,
 	}
 	return ret
 }
 
+// BuildUTXO transferable inscription utxo created by inscribe-transfer or spent by transfer
+func (tc *TxResultHandler) BuildUTXO(e *TxResult) map[DBAction]*model.UTXO {
+	if e.Inscribe != nil {
+		return map[DBAction]*model.UTXO{
+			DBActionCreate: {
+				Sn:        e.Inscribe.Sn,
+				Chain:     e.MD.Chain,
+				Protocol:  e.MD.Protocol,
+				Address:   e.Inscribe.Owner,
+				Tick:      e.MD.Tick,
+				Amount:    e.Inscribe.Amount,
+				RootHash:  e.Tx.Hash,
+				TxHash:    e.Tx.Hash,
+				Spender:   e.Inscribe.Spender,
+				Vout:      e.Inscribe.Vout,
+				SatOffset: e.Inscribe.Offset,
+				Status:    model.UTXOStatusUnspent,
+			},
+		}
+	}
+
+	if e.Transfer != nil && e.Transfer.UTXO != "" {
+		return map[DBAction]*model.UTXO{
+			DBActionUpdate: {
+				Chain:    e.MD.Chain,
+				Protocol: e.MD.Protocol,
+				Tick:     e.MD.Tick,
+				RootHash: e.Transfer.UTXO,
+				TxHash:   e.Tx.Hash,
+				Status:   model.UTXOStatusSpent,
+			},
+		}
+	}
+	return nil
+}
+
+// BuildListing marketplace listing created by list, or closed by exchange / delist
+func (tc *TxResultHandler) BuildListing(e *TxResult) map[DBAction]*model.Listing {
+	if e.Listing == nil {
+		return nil
+	}
+
+	if e.Listing.Status == model.ListingStatusOpen {
+		return map[DBAction]*model.Listing{
+			DBActionCreate: {
+				Chain:       e.MD.Chain,
+				Protocol:    e.MD.Protocol,
+				Tick:        e.MD.Tick,
+				ListId:      e.Listing.ListId,
+				Seller:      e.Listing.Seller,
+				Market:      e.Listing.Market,
+				Amount:      e.Listing.Amount,
+				Status:      model.ListingStatusOpen,
+				BlockHeight: e.Block.Number.Uint64(),
+			},
+		}
+	}
+
+	return map[DBAction]*model.Listing{
+		DBActionUpdate: {
+			Chain:            e.MD.Chain,
+			ListId:           e.Listing.ListId,
+			Amount:           e.Listing.Amount,
+			Price:            e.Listing.Price,
+			UnitPrice:        e.Listing.UnitPrice(),
+			Status:           e.Listing.Status,
+			Buyer:            e.Listing.Buyer,
+			CloseTxHash:      e.Tx.Hash,
+			CloseBlockHeight: e.Block.Number.Uint64(),
+		},
+	}
+}
+
+// BuildTrade the fill record of the listing filled by exchange
+func (tc *TxResultHandler) BuildTrade(e *TxResult) *model.Trade {
+	if e.Listing == nil || e.Listing.Status != model.ListingStatusFilled {
+		return nil
+	}
+
+	return &model.Trade{
+		Chain:       e.MD.Chain,
+		Protocol:    e.MD.Protocol,
+		Tick:        e.MD.Tick,
+		ListId:      e.Listing.ListId,
+		TxHash:      e.Tx.Hash,
+		Seller:      e.Listing.Seller,
+		Buyer:       e.Listing.Buyer,
+		Amount:      e.Listing.Amount,
+		Price:       e.Listing.Price,
+		UnitPrice:   e.Listing.UnitPrice(),
+		BlockHeight: e.Block.Number.Uint64(),
+		BlockTime:   time.Unix(int64(e.Block.Time), 0),
+	}
+}
+
+// BuildEthscription ethscription created or owner updated, with the transfer history record
+func (tc *TxResultHandler) BuildEthscription(e *TxResult) (map[DBAction]*model.Ethscription, *model.EthscriptionTransfer) {
+	blockTime := time.Unix(int64(e.Block.Time), 0)
+	transfer := &model.EthscriptionTransfer{
+		Chain:          e.MD.Chain,
+		EthscriptionId: e.Ethscription.Id,
+		TxHash:         common.FromHex(e.Tx.Hash),
+		From:           e.Ethscription.From,
+		To:             e.Ethscription.To,
+		LogIndex:       e.Ethscription.LogIndex,
+		BlockHeight:    e.Block.Number.Uint64(),
+		BlockTime:      blockTime,
+	}
+
+	if e.MD.Operate == OperateCreate {
+		return map[DBAction]*model.Ethscription{
+			DBActionCreate: {
+				Chain:          e.MD.Chain,
+				EthscriptionId: e.Ethscription.Id,
+				Creator:        e.Ethscription.From,
+				InitialOwner:   e.Ethscription.To,
+				Owner:          e.Ethscription.To,
+				PreviousOwner:  e.Ethscription.From,
+				ContentSha:     e.Ethscription.ContentSha,
+				Mimetype:       e.Ethscription.Mimetype,
+				ContentUri:     e.Ethscription.ContentUri,
+				BlockHeight:    e.Block.Number.Uint64(),
+				BlockTime:      blockTime,
+			},
+		}, transfer
+	}
+
+	return map[DBAction]*model.Ethscription{
+		DBActionUpdate: {
+			Chain:          e.MD.Chain,
+			EthscriptionId: e.Ethscription.Id,
+			Owner:          e.Ethscription.To,
+			PreviousOwner:  e.Ethscription.From,
+		},
+	}, transfer
+}
+
 func (tc *TxResultHandler) BuildInscriptionStat(e *TxResult) map[DBAction]*model.InscriptionsStats {
 	_, d := tc.cache.InscriptionStats.Get(e.MD.Protocol, e.MD.Tick)
 
@@ -139,11 +296,19 @@ func (tc *TxResultHandler) BuildAddressTxEvents(e *TxResult) []*AddressTxEvent {
 
 	if e.Mint != nil {
 		items = append(items, &AddressTxEvent{
-			Address: e.Tx.To,
+			Address: e.Mint.Minter,
 			Amount:  e.Mint.Amount,
 		})
 	}
 
+	// no balance moved till the utxo spent
+	if e.Inscribe != nil {
+		items = append(items, &AddressTxEvent{
+			Address: e.Inscribe.Owner,
+			Amount:  decimal.Zero,
+		})
+	}
+
 	if e.Transfer != nil {
 		sendTotalAmount := decimal.Zero
 		for _, item := range e.Transfer.Receives {
@@ -182,6 +347,7 @@ func (tc *TxResultHandler) BuildAddressTxs(e *TxResult) (txs []*model.AddressTxs
 			RelatedAddress: item.RelatedAddress,
 			Amount:         item.Amount,
 			TxHash:         common.FromHex(e.Tx.Hash),
+			SubIndex:       e.SubIndex,
 			Tick:           e.MD.Tick,
 			Protocol:       e.MD.Protocol,
 			Operate:        e.MD.Operate,
@@ -200,11 +366,11 @@ func (tc *TxResultHandler) getEventByOperate(operate string) model.TxEvent {
 		return model.TransactionEventMint
 	case OperateTransfer:
 		return model.TransactionEventTransfer
-	case OperateList:
+	case OperateList, OperateFreezeSell:
 		return model.TransactionEventList
-	case OperateDelist:
+	case OperateDelist, OperateUnfreezeSell:
 		return model.TransactionEventDelist
-	case OperateExchange:
+	case OperateExchange, OperateProxyTransfer:
 		return model.TransactionEventExchange
 	}
 	return model.TxEvent(0)
@@ -220,21 +386,37 @@ type BalanceTxEvent struct {
 }
 
 func (tc *TxResultHandler) BuildBalanceTxEvents(e *TxResult) []BalanceTxEvent {
+	// balances missing only if failed loading, the block is handled again then
 	items := make([]BalanceTxEvent, 0, 10)
 	if e.Mint != nil {
-		_, balance := tc.cache.Balance.Get(e.MD.Protocol, e.MD.Tick, e.Mint.Minter)
-		action := DBActionUpdate
-		if e.Mint.Init {
-			action = DBActionCreate
+		if ok, balance := tc.cache.Balance.Get(e.MD.Protocol, e.MD.Tick, e.Mint.Minter); ok {
+			action := DBActionUpdate
+			if e.Mint.Init {
+				action = DBActionCreate
+			}
+			items = append(items, BalanceTxEvent{
+				Action:           action,
+				SID:              balance.SID,
+				Address:          e.Mint.Minter,
+				Amount:           e.Mint.Amount,
+				AvailableBalance: balance.Available,
+				OverallBalance:   balance.Overall,
+			})
+		}
+	}
+
+	// overall balance unchanged, only the available balance locked
+	if e.Inscribe != nil {
+		if ok, balance := tc.cache.Balance.Get(e.MD.Protocol, e.MD.Tick, e.Inscribe.Owner); ok {
+			items = append(items, BalanceTxEvent{
+				Action:           DBActionUpdate,
+				SID:              balance.SID,
+				Address:          e.Inscribe.Owner,
+				Amount:           decimal.Zero,
+				AvailableBalance: balance.Available,
+				OverallBalance:   balance.Overall,
+			})
 		}
-		items = append(items, BalanceTxEvent{
-			Action:           action,
-			SID:              balance.SID,
-			Address:          e.Mint.Minter,
-			Amount:           e.Mint.Amount,
-			AvailableBalance: balance.Available,
-			OverallBalance:   balance.Overall,
-		})
 	}
 
 	if e.Transfer != nil {
@@ -243,18 +425,23 @@ func (tc *TxResultHandler) BuildBalanceTxEvents(e *TxResult) []BalanceTxEvent {
 			sendTotalAmount = sendTotalAmount.Add(item.Amount)
 		}
 
-		_, senderBalance := tc.cache.Balance.Get(e.MD.Protocol, e.MD.Tick, e.Transfer.Sender)
-		items = append(items, BalanceTxEvent{
-			Action:           DBActionUpdate,
-			SID:              senderBalance.SID,
-			Address:          e.Transfer.Sender,
-			Amount:           sendTotalAmount.Neg(),
-			AvailableBalance: senderBalance.Available,
-			OverallBalance:   senderBalance.Overall,
-		})
+		if ok, senderBalance := tc.cache.Balance.Get(e.MD.Protocol, e.MD.Tick, e.Transfer.Sender); ok {
+			items = append(items, BalanceTxEvent{
+				Action:           DBActionUpdate,
+				SID:              senderBalance.SID,
+				Address:          e.Transfer.Sender,
+				Amount:           sendTotalAmount.Neg(),
+				AvailableBalance: senderBalance.Available,
+				OverallBalance:   senderBalance.Overall,
+			})
+		}
 
 		for _, item := range e.Transfer.Receives {
-			_, receiveBalance := tc.cache.Balance.Get(e.MD.Protocol, e.MD.Tick, item.Address)
+			ok, receiveBalance := tc.cache.Balance.Get(e.MD.Protocol, e.MD.Tick, item.Address)
+			if !ok {
+				continue
+			}
+
 			action := DBActionUpdate
 			if item.Init {
 				action = DBActionCreate
@@ -287,6 +474,7 @@ func (tc *TxResultHandler) BuildBalance(e *TxResult) (txns []*model.BalanceTxn,
 			Balance:   event.OverallBalance,
 			Available: event.AvailableBalance,
 			TxHash:    common.FromHex(e.Tx.Hash),
+			SubIndex:  e.SubIndex,
 			CreatedAt: time.Unix(int64(e.Block.Time), 0),
 		})
 
@@ -314,6 +502,7 @@ func (tc *TxResultHandler) BuildTx(e *TxResult) *model.Transaction {
 		PositionInBlock: e.Tx.TxIndex.Uint64(),
 		BlockTime:       time.Unix(int64(e.Block.Time), 0),
 		TxHash:          common.FromHex(e.Tx.Hash),
+		SubIndex:        e.SubIndex,
 		From:            e.Tx.From,
 		To:              e.Tx.To,
 		Op:              e.MD.Operate,
@@ -333,7 +522,10 @@ func (tc *TxResultHandler) BuildTx(e *TxResult) *model.Transaction {
 		}
 	case OperateDeploy:
 		trx.Amount = decimal.NewFromInt(0)
-	case OperateTransfer:
+	case OperateTransfer, OperateFreezeSell, OperateUnfreezeSell, OperateProxyTransfer:
+		if e.Inscribe != nil {
+			trx.Amount = e.Inscribe.Amount
+		}
 		if e.Transfer != nil {
 			amount := decimal.NewFromInt(0)
 			for _, v := range e.Transfer.Receives {
@@ -352,7 +544,16 @@ type DBModelsFattened struct {
 	Txs              []*model.Transaction
 	AddressTxs       []*model.AddressTxs
 	BalanceTxs       []*model.BalanceTxn
+	BlockGas         []*model.BlockGas
+	TickGas          []*model.TickGas
+	Rejects          []*model.RejectedTx
+	UTXOs            map[DBAction][]*model.UTXO
+	Listings         map[DBAction][]*model.Listing
+	Trades           []*model.Trade
 	BlockStatus      *model.BlockStatus
+
+	Ethscriptions         map[DBAction][]*model.Ethscription
+	EthscriptionTransfers []*model.EthscriptionTransfer
 }
 
 type DBModels struct {
@@ -365,6 +566,30 @@ type DBModels struct {
 }
 
 func BuildDBUpdateModel(blocksEvents []*Event) (dmf *DBModelsFattened) {
+	blockGas := make([]*model.BlockGas, 0, len(blocksEvents))
+	tickGas := make([]*model.TickGas, 0, len(blocksEvents))
+	rejects := make([]*model.RejectedTx, 0, len(blocksEvents))
+
+	// utxos are created & spent in order, not merged
+	utxos := map[DBAction][]*model.UTXO{
+		DBActionCreate: make([]*model.UTXO, 0, len(blocksEvents)),
+		DBActionUpdate: make([]*model.UTXO, 0, len(blocksEvents)),
+	}
+
+	// listings are listed & closed in order, not merged
+	listings := map[DBAction][]*model.Listing{
+		DBActionCreate: make([]*model.Listing, 0, len(blocksEvents)),
+		DBActionUpdate: make([]*model.Listing, 0, len(blocksEvents)),
+	}
+
+	trades := make([]*model.Trade, 0, len(blocksEvents))
+
+	// ethscriptions are created & transferred in order, not merged
+	ethscriptions := map[DBAction][]*model.Ethscription{
+		DBActionCreate: make([]*model.Ethscription, 0, len(blocksEvents)),
+		DBActionUpdate: make([]*model.Ethscription, 0, len(blocksEvents)),
+	}
+	ethscriptionTransfers := make([]*model.EthscriptionTransfer, 0, len(blocksEvents))
 	dm := &DBModels{
 		Inscriptions: map[DBAction]map[uint32]*model.Inscriptions{
 			DBActionCreate: make(map[uint32]*model.Inscriptions, 100),
@@ -387,6 +612,12 @@ func BuildDBUpdateModel(blocksEvents []*Event) (dmf *DBModelsFattened) {
 		data, _ := json.Marshal(blockEvent)
 		xylog.Logger.Debugf("BuildDBUpdateModel blockEvent = %v", string(data))
 
+		if blockEvent.BlockGas != nil {
+			blockGas = append(blockGas, blockEvent.BlockGas)
+		}
+		tickGas = append(tickGas, blockEvent.TickGas...)
+		rejects = append(rejects, blockEvent.Rejects...)
+
 		for _, event := range blockEvent.Items {
 			for action, item := range event.Inscriptions {
 				if _, ok := dm.Inscriptions[action][item.SID]; ok {
@@ -413,7 +644,7 @@ func BuildDBUpdateModel(blocksEvents []*Event) (dmf *DBModelsFattened) {
 				dm.InscriptionStats[action][item.SID] = item
 			}
 
-			txIdx := common.Bytes2Hex(event.Tx.TxHash)
+			txIdx := fmt.Sprintf("%s_%d", common.Bytes2Hex(event.Tx.TxHash), event.Tx.SubIndex)
 			if _, ok := dm.Txs[txIdx]; ok {
 				xylog.Logger.Debugf("tx[%s] exist & force update", txIdx)
 			}
@@ -427,6 +658,26 @@ func BuildDBUpdateModel(blocksEvents []*Event) (dmf *DBModelsFattened) {
 				dm.BalanceTxs = append(dm.BalanceTxs, event.BalanceTxs...)
 			}
 
+			for action, item := range event.UTXOs {
+				utxos[action] = append(utxos[action], item)
+			}
+
+			for action, item := range event.Listings {
+				listings[action] = append(listings[action], item)
+			}
+
+			if event.Trade != nil {
+				trades = append(trades, event.Trade)
+			}
+
+			for action, item := range event.Ethscriptions {
+				ethscriptions[action] = append(ethscriptions[action], item)
+			}
+
+			if event.EthscriptionTransfer != nil {
+				ethscriptionTransfers = append(ethscriptionTransfers, event.EthscriptionTransfer)
+			}
+
 			for action, items := range event.Balances {
 				for _, item := range items {
 					if _, ok := dm.Balances[action][item.SID]; ok {
@@ -463,7 +714,16 @@ func BuildDBUpdateModel(blocksEvents []*Event) (dmf *DBModelsFattened) {
 		Txs:         make([]*model.Transaction, 0, len(dm.Txs)),
 		AddressTxs:  dm.AddressTxs,
 		BalanceTxs:  dm.BalanceTxs,
+		BlockGas:    blockGas,
+		TickGas:     tickGas,
+		Rejects:     rejects,
+		UTXOs:       utxos,
+		Listings:    listings,
+		Trades:      trades,
 		BlockStatus: bs,
+
+		Ethscriptions:         ethscriptions,
+		EthscriptionTransfers: ethscriptionTransfers,
 	}
 
 	// flatten tx
@@ -0,0 +1,54 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package devents
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/xylog"
+	"sort"
+	"testing"
+)
+
+func TestBuildDBUpdateModel_SubIndex(t *testing.T) {
+	xylog.InitLog(logrus.DebugLevel, "")
+
+	hash := common.FromHex("0x01")
+	items := make([]*DBModelEvent, 0, 3)
+	for _, idx := range []uint32{0, 1, 1} {
+		items = append(items, &DBModelEvent{
+			Tx: &model.Transaction{Chain: "avalanche", TxHash: hash, SubIndex: idx, Op: OperateExchange},
+		})
+	}
+
+	dmf := BuildDBUpdateModel([]*Event{{Chain: "avalanche", BlockNum: 1, Items: items}})
+	assert.Equal(t, 2, len(dmf.Txs))
+
+	sort.Slice(dmf.Txs, func(i, j int) bool {
+		return dmf.Txs[i].SubIndex < dmf.Txs[j].SubIndex
+	})
+	assert.Equal(t, uint32(0), dmf.Txs[0].SubIndex)
+	assert.Equal(t, uint32(1), dmf.Txs[1].SubIndex)
+}
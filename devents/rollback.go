@@ -0,0 +1,425 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package devents
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/xylog"
+	"gorm.io/gorm"
+	"time"
+)
+
+// RollbackModels the derived data reverted by a block rollback
+type RollbackModels struct {
+	Inscriptions     []*model.Inscriptions // ticks deployed in the rolled back blocks
+	InscriptionStats []*model.InscriptionsStats
+	Balances         map[DBAction][]*model.Balances
+	UTXOs            map[DBAction][]*model.UTXO         // utxos created (deleted) or spent (restored) in the rolled back blocks
+	Ethscriptions    map[DBAction][]*model.Ethscription // ethscriptions created (deleted) or transferred (owner restored)
+}
+
+// tickBalances the addresses whose balances of the tick are reverted
+type tickBalances struct {
+	protocol string
+	tick     string
+	addrs    []string
+	firstId  uint64 // the earliest rolled back balance txn of the tick
+}
+
+type tickRollback struct {
+	protocol string
+	tick     string
+	deployed bool
+	minted   decimal.Decimal
+	txCnt    uint64
+}
+
+// Rollback
+/***************************************
+ * revert txs, address_txs, balance_txn, balances, inscriptions_stats,
+ * utxos, listings, trades, ethscriptions, rejected txs & gas stats
+ * written above the ancestor block, balances are restored from
+ * the latest balance_txn record before the rolled back blocks.
+ * only the tick's data is reverted if tick is not empty, and the
+ * synced block status is kept
+ ***************************************/
+func (h *DEvent) Rollback(ancestor *model.BlockStatus, tick string) (*RollbackModels, error) {
+	chain := ancestor.Chain
+	fromBlock := ancestor.BlockNumber + 1
+
+	// fetch db lock
+	h.getDBLockTillSuccess(h.db)
+	defer h.releaseDBLock(h.db)
+
+	startTs := time.Now()
+	txs, err := h.db.FindTxsFromBlock(chain, fromBlock, tick)
+	if err != nil {
+		return nil, fmt.Errorf("load rollback txs err:%v", err)
+	}
+
+	hashes := make([]common.Hash, 0, len(txs))
+	utxoHashes := make([]string, 0, len(txs))
+	ticks := make(map[string]*tickRollback, 10)
+	for _, tx := range txs {
+		hashes = append(hashes, common.BytesToHash(tx.TxHash))
+		utxoHashes = append(utxoHashes, common.Bytes2Hex(tx.TxHash))
+
+		// tick-less txs have no stats, e.g. ethscriptions
+		if tx.Tick == "" {
+			continue
+		}
+
+		key := fmt.Sprintf("%s_%s", tx.Protocol, tx.Tick)
+		t, ok := ticks[key]
+		if !ok {
+			t = &tickRollback{protocol: tx.Protocol, tick: tx.Tick}
+			ticks[key] = t
+		}
+		t.txCnt++
+
+		switch tx.Op {
+		case OperateDeploy:
+			t.deployed = true
+		case OperateMint:
+			t.minted = t.minted.Add(tx.Amount)
+		}
+	}
+
+	txns, err := h.db.FindBalanceTxnsByHashes(chain, hashes, tick)
+	if err != nil {
+		return nil, fmt.Errorf("load rollback balance txns err:%v", err)
+	}
+
+	// the addresses with balance txns rolled back, grouped by tick
+	balanceTicks := make(map[string]*tickBalances, len(ticks))
+	revertedAddrs := make(map[string]struct{}, len(txns))
+	for _, txn := range txns {
+		if t := ticks[fmt.Sprintf("%s_%s", txn.Protocol, txn.Tick)]; t != nil && t.deployed {
+			continue
+		}
+
+		key := fmt.Sprintf("%s_%s_%s", txn.Protocol, txn.Tick, txn.Address)
+		if _, ok := revertedAddrs[key]; ok {
+			continue
+		}
+		revertedAddrs[key] = struct{}{}
+
+		tickKey := fmt.Sprintf("%s_%s", txn.Protocol, txn.Tick)
+		tb, ok := balanceTicks[tickKey]
+		if !ok {
+			// txns are sorted by id, the first one is the earliest
+			tb = &tickBalances{protocol: txn.Protocol, tick: txn.Tick, firstId: txn.ID}
+			balanceTicks[tickKey] = tb
+		}
+		tb.addrs = append(tb.addrs, txn.Address)
+	}
+
+	utxos, err := h.db.FindUTXOsByHashes(chain, utxoHashes, tick)
+	if err != nil {
+		return nil, fmt.Errorf("load rollback utxos err:%v", err)
+	}
+
+	rm := &RollbackModels{
+		Inscriptions:     make([]*model.Inscriptions, 0, len(ticks)),
+		InscriptionStats: make([]*model.InscriptionsStats, 0, len(ticks)),
+		Balances: map[DBAction][]*model.Balances{
+			DBActionUpdate: make([]*model.Balances, 0, len(revertedAddrs)),
+			DBActionDelete: make([]*model.Balances, 0, len(revertedAddrs)),
+		},
+		UTXOs: map[DBAction][]*model.UTXO{
+			DBActionUpdate: make([]*model.UTXO, 0, len(utxos)),
+			DBActionDelete: make([]*model.UTXO, 0, len(utxos)),
+		},
+	}
+
+	created := make(map[string]struct{}, len(utxoHashes))
+	for _, hash := range utxoHashes {
+		created[hash] = struct{}{}
+	}
+	for _, item := range utxos {
+		if _, ok := created[item.RootHash]; ok {
+			rm.UTXOs[DBActionDelete] = append(rm.UTXOs[DBActionDelete], item)
+			continue
+		}
+		rm.UTXOs[DBActionUpdate] = append(rm.UTXOs[DBActionUpdate], item)
+	}
+
+	// ethscriptions have no tick, kept while reverting a single tick
+	if tick == "" {
+		if rm.Ethscriptions, err = h.rollbackEthscriptions(chain, fromBlock); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, tb := range balanceTicks {
+		balances, err := h.db.FindUserBalancesByTick(chain, tb.protocol, tb.tick, tb.addrs)
+		if err != nil {
+			return nil, fmt.Errorf("load rollback balances err:%v", err)
+		}
+
+		// the blocks are flushed in order, all balance txns before the rolled back blocks have smaller ids
+		prevTxns, err := h.db.FindLastBalanceTxnsBeforeId(chain, tb.protocol, tb.tick, tb.addrs, tb.firstId)
+		if err != nil {
+			return nil, fmt.Errorf("load previous balance txns err:%v", err)
+		}
+
+		prevs := make(map[string]*model.BalanceTxn, len(prevTxns))
+		for _, prev := range prevTxns {
+			prevs[prev.Address] = prev
+		}
+
+		for _, balance := range balances {
+			// balance created in the rolled back blocks
+			prev, ok := prevs[balance.Address]
+			if !ok {
+				rm.Balances[DBActionDelete] = append(rm.Balances[DBActionDelete], balance)
+				continue
+			}
+
+			balance.Available = prev.Available
+			balance.Balance = prev.Balance
+			rm.Balances[DBActionUpdate] = append(rm.Balances[DBActionUpdate], balance)
+		}
+	}
+
+	for _, t := range ticks {
+		if t.deployed {
+			ins, err := h.db.FindInscriptionByTick(chain, t.protocol, t.tick)
+			if err != nil {
+				return nil, fmt.Errorf("load rollback inscription err:%v", err)
+			}
+			if ins != nil {
+				rm.Inscriptions = append(rm.Inscriptions, ins)
+			}
+			continue
+		}
+
+		stats, err := h.db.FindInscriptionsStatsByTick(chain, t.protocol, t.tick)
+		if err != nil {
+			return nil, fmt.Errorf("load rollback inscription stats err:%v", err)
+		}
+
+		stats.Minted = stats.Minted.Sub(t.minted)
+		if stats.TxCnt > t.txCnt {
+			stats.TxCnt -= t.txCnt
+		} else {
+			stats.TxCnt = 0
+		}
+
+		if stats.MintFirstBlock >= fromBlock {
+			stats.MintFirstBlock = 0
+		}
+
+		if stats.MintLastBlock >= fromBlock {
+			stats.MintLastBlock = 0
+			stats.MintCompletedTime = nil
+		}
+		rm.InscriptionStats = append(rm.InscriptionStats, stats)
+	}
+
+	err = h.db.SqlDB.Transaction(func(tx *gorm.DB) error {
+		if err := h.db.DeleteTxsFromBlock(tx, chain, fromBlock, tick); err != nil {
+			xylog.Logger.Errorf("failed to delete transactions. err=%s", err)
+			return err
+		}
+
+		if err := h.db.DeleteAddressTxsByHashes(tx, chain, hashes, tick); err != nil {
+			xylog.Logger.Errorf("failed to delete address transactions. err=%s", err)
+			return err
+		}
+
+		if err := h.db.DeleteBalanceTxnsByHashes(tx, chain, hashes, tick); err != nil {
+			xylog.Logger.Errorf("failed to delete balance txn records. err=%s", err)
+			return err
+		}
+
+		if items := rm.Balances[DBActionDelete]; len(items) > 0 {
+			sids := make([]uint64, 0, len(items))
+			for _, item := range items {
+				sids = append(sids, item.SID)
+			}
+			if err := h.db.DeleteBalancesBySIDs(tx, chain, sids); err != nil {
+				xylog.Logger.Errorf("failed to delete balances. err=%s", err)
+				return err
+			}
+		}
+
+		if items := rm.Balances[DBActionUpdate]; len(items) > 0 {
+			if err := h.db.BatchUpdateBalances(tx, chain, items); err != nil {
+				xylog.Logger.Errorf("failed to restore balances. err=%s", err)
+				return err
+			}
+		}
+
+		if err := h.db.DeleteUTXOsByRootHashes(tx, chain, utxoHashes, tick); err != nil {
+			xylog.Logger.Errorf("failed to delete utxos. err=%s", err)
+			return err
+		}
+
+		if err := h.db.RestoreUTXOsBySpentHashes(tx, chain, utxoHashes, tick); err != nil {
+			xylog.Logger.Errorf("failed to restore utxos. err=%s", err)
+			return err
+		}
+
+		for _, item := range rm.Inscriptions {
+			if err := h.db.DeleteInscriptionByTick(tx, chain, item.Protocol, item.Tick); err != nil {
+				xylog.Logger.Errorf("failed to delete inscription. err=%s", err)
+				return err
+			}
+		}
+
+		for _, item := range rm.InscriptionStats {
+			holders, err := h.db.CountHoldersByTick(tx, chain, item.Protocol, item.Tick)
+			if err != nil {
+				xylog.Logger.Errorf("failed to count holders. err=%s", err)
+				return err
+			}
+			item.Holders = uint64(holders)
+
+			updates := map[string]interface{}{
+				"minted":              item.Minted,
+				"holders":             item.Holders,
+				"tx_cnt":              item.TxCnt,
+				"mint_first_block":    item.MintFirstBlock,
+				"mint_last_block":     item.MintLastBlock,
+				"mint_completed_time": item.MintCompletedTime,
+			}
+			if err := h.db.UpdateInscriptionsStatsBySID(tx, chain, item.SID, updates); err != nil {
+				xylog.Logger.Errorf("failed to restore inscription stats. err=%s", err)
+				return err
+			}
+		}
+
+		if err := h.db.DeleteListingsFromBlock(tx, chain, fromBlock, tick); err != nil {
+			xylog.Logger.Errorf("failed to delete listings. err=%s", err)
+			return err
+		}
+
+		if err := h.db.ReopenListingsFromBlock(tx, chain, fromBlock, tick); err != nil {
+			xylog.Logger.Errorf("failed to reopen listings. err=%s", err)
+			return err
+		}
+
+		if err := h.db.DeleteTradesFromBlock(tx, chain, fromBlock, tick); err != nil {
+			xylog.Logger.Errorf("failed to delete trades. err=%s", err)
+			return err
+		}
+
+		if err := h.db.DeleteRejectedTxsFromBlock(tx, chain, fromBlock, tick); err != nil {
+			xylog.Logger.Errorf("failed to delete rejected tx records. err=%s", err)
+			return err
+		}
+
+		if err := h.db.DeleteTickGasFromBlock(tx, chain, fromBlock, tick); err != nil {
+			xylog.Logger.Errorf("failed to delete tick gas records. err=%s", err)
+			return err
+		}
+
+		// block gas covers all ticks, kept while reverting a single tick
+		if tick != "" {
+			return nil
+		}
+
+		if err := h.db.DeleteEthscriptionsFromBlock(tx, chain, fromBlock); err != nil {
+			xylog.Logger.Errorf("failed to delete ethscriptions. err=%s", err)
+			return err
+		}
+
+		if err := h.db.DeleteEthscriptionTransfersFromBlock(tx, chain, fromBlock); err != nil {
+			xylog.Logger.Errorf("failed to delete ethscription transfers. err=%s", err)
+			return err
+		}
+
+		if err := h.db.UpdateEthscriptionOwners(tx, chain, rm.Ethscriptions[DBActionUpdate]); err != nil {
+			xylog.Logger.Errorf("failed to restore ethscription owners. err=%s", err)
+			return err
+		}
+
+		if err := h.db.DeleteBlockGasFromBlock(tx, chain, fromBlock); err != nil {
+			xylog.Logger.Errorf("failed to delete block gas records. err=%s", err)
+			return err
+		}
+
+		if err := h.db.RollbackLastBlock(tx, ancestor); err != nil {
+			xylog.Logger.Errorf("failed to rollback block information. err=%s", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	xylog.Logger.Infof("rollback db success, chain[%s], from block[%d], txs[%d], cost:%v", chain, fromBlock, len(txs), time.Since(startTs))
+	return rm, nil
+}
+
+// rollbackEthscriptions ethscriptions created in the rolled back blocks, and the owners
+// of the ones transferred restored from the latest transfer before the blocks
+func (h *DEvent) rollbackEthscriptions(chain string, fromBlock uint64) (map[DBAction][]*model.Ethscription, error) {
+	created, err := h.db.FindEthscriptionsFromBlock(chain, fromBlock)
+	if err != nil {
+		return nil, fmt.Errorf("load rollback ethscriptions err:%v", err)
+	}
+
+	transfers, err := h.db.FindEthscriptionTransfersFromBlock(chain, fromBlock)
+	if err != nil {
+		return nil, fmt.Errorf("load rollback ethscription transfers err:%v", err)
+	}
+
+	items := map[DBAction][]*model.Ethscription{
+		DBActionUpdate: make([]*model.Ethscription, 0, len(transfers)),
+		DBActionDelete: created,
+	}
+
+	ids := make(map[string]struct{}, len(created)+len(transfers))
+	for _, item := range created {
+		ids[item.EthscriptionId] = struct{}{}
+	}
+
+	for _, transfer := range transfers {
+		if _, ok := ids[transfer.EthscriptionId]; ok {
+			continue
+		}
+		ids[transfer.EthscriptionId] = struct{}{}
+
+		prev, err := h.db.FindLastEthscriptionTransferBeforeBlock(chain, transfer.EthscriptionId, fromBlock)
+		if err != nil {
+			return nil, fmt.Errorf("load previous ethscription transfer err:%v", err)
+		}
+		if prev == nil {
+			continue
+		}
+
+		items[DBActionUpdate] = append(items[DBActionUpdate], &model.Ethscription{
+			Chain:          chain,
+			EthscriptionId: prev.EthscriptionId,
+			Owner:          prev.To,
+			PreviousOwner:  prev.From,
+		})
+	}
+	return items, nil
+}
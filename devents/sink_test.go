@@ -0,0 +1,172 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package devents
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/storage"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"testing"
+)
+
+// newSqliteEvents open an in-memory sqlite db & build the block events updating the cache
+func newSqliteEvents(t *testing.T) (*storage.DBClient, func(num uint64, results ...*TxResult) *Event) {
+	xylog.InitLog(logrus.DebugLevel, "")
+
+	db, err := storage.NewDbClient(&config.DatabaseConfig{Type: storage.DatabaseTypeSqlite3, Dsn: "file::memory:"})
+	assert.NoError(t, err)
+
+	cache := dcache.NewManager(nil, "eth")
+	cache.Balance = dcache.NewBalance()
+	cache.UTXO = dcache.NewUTXO()
+	cache.Inscription = dcache.NewInscription()
+	cache.InscriptionStats = dcache.NewInscriptionStats()
+	tc := NewTxResultHandler(cache)
+
+	return db, func(num uint64, results ...*TxResult) *Event {
+		block := &xycommon.RpcBlock{Number: new(big.Int).SetUint64(num), Time: 1700000000 + num}
+		e := &Event{Chain: "eth", BlockNum: num, BlockTime: block.Time, BlockHash: "0x0" + block.Number.String()}
+		for idx, r := range results {
+			r.Block = block
+			r.Tx = &xycommon.RpcTransaction{
+				Hash:        common.BigToHash(new(big.Int).SetUint64(num*10 + uint64(idx))).Hex(),
+				BlockNumber: block.Number,
+				TxIndex:     big.NewInt(int64(idx)),
+				From:        "0xa1",
+				Gas:         big.NewInt(21000),
+				GasPrice:    big.NewInt(1),
+			}
+			tc.UpdateCache(r)
+			e.Items = append(e.Items, tc.BuildModel(r))
+		}
+		return e
+	}
+}
+
+func testMetaData(op string) *MetaData {
+	return &MetaData{Chain: "eth", Protocol: "erc-20", Tick: "abcd", Operate: op}
+}
+
+func TestDEvent_SinkSqlite(t *testing.T) {
+	db, newEvent := newSqliteEvents(t)
+	amount := decimal.RequireFromString("100.123456789012345678")
+
+	h := NewDEvents(context.Background(), db)
+	h.WriteDBAsync(newEvent(1,
+		&TxResult{MD: testMetaData(OperateDeploy), Deploy: &Deploy{Name: "abcd", MaxSupply: decimal.NewFromInt(1000), MintLimit: amount}},
+		&TxResult{MD: testMetaData(OperateMint), Mint: &Mint{Minter: "0xa1", Amount: amount}},
+	))
+	h.WriteDBAsync(newEvent(2,
+		&TxResult{MD: testMetaData(OperateMint), Mint: &Mint{Minter: "0xa1", Amount: amount}},
+		&TxResult{MD: testMetaData(OperateTransfer), Transfer: &Transfer{
+			Sender:   "0xa1",
+			Receives: []*Receive{{Address: "0xb1", Amount: decimal.NewFromInt(50)}},
+		}},
+	))
+	assert.True(t, h.Sink(db))
+	assert.Equal(t, uint64(2), h.CommittedBlock())
+
+	// the lock is released after flushing, & held by one sink at a time
+	ok, err := db.GetLock()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = db.GetLock()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.NoError(t, db.ReleaseLock())
+
+	status, err := db.QueryLastBlockStatus("eth")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), status.BlockNumber)
+
+	balances := make([]*model.Balances, 0)
+	assert.NoError(t, db.SqlDB.Order("address").Find(&balances, "chain = ?", "eth").Error)
+	assert.Len(t, balances, 2)
+	assert.Equal(t, "150.246913578024691356", balances[0].Balance.String())
+	assert.Equal(t, "50", balances[1].Balance.String())
+
+	holders, err := db.CountHoldersByTick(db.SqlDB, "eth", "erc-20", "abcd")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), holders)
+
+	txs, err := db.FindTransactions("eth", common.BigToHash(big.NewInt(21)))
+	assert.NoError(t, err)
+	assert.Len(t, txs, 1)
+	assert.Equal(t, OperateTransfer, txs[0].Op)
+
+	// block status never rewinds
+	h.WriteDBAsync(newEvent(1))
+	assert.True(t, h.Sink(db))
+	status, err = db.QueryLastBlockStatus("eth")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), status.BlockNumber)
+}
+
+func TestDEvent_RollbackSqlite(t *testing.T) {
+	db, newEvent := newSqliteEvents(t)
+	amount := decimal.NewFromInt(100)
+
+	h := NewDEvents(context.Background(), db)
+	h.WriteDBAsync(newEvent(1,
+		&TxResult{MD: testMetaData(OperateDeploy), Deploy: &Deploy{Name: "abcd", MaxSupply: decimal.NewFromInt(1000), MintLimit: amount}},
+		&TxResult{MD: testMetaData(OperateMint), Mint: &Mint{Minter: "0xa1", Amount: amount}},
+		&TxResult{MD: testMetaData(OperateMint), Mint: &Mint{Minter: "0xc1", Amount: amount}},
+	))
+	h.WriteDBAsync(newEvent(2,
+		&TxResult{MD: testMetaData(OperateMint), Mint: &Mint{Minter: "0xa1", Amount: amount}},
+		&TxResult{MD: testMetaData(OperateTransfer), Transfer: &Transfer{
+			Sender:   "0xa1",
+			Receives: []*Receive{{Address: "0xb1", Amount: decimal.NewFromInt(50)}},
+		}},
+	))
+	h.WriteDBAsync(newEvent(3,
+		&TxResult{MD: testMetaData(OperateMint), Mint: &Mint{Minter: "0xa1", Amount: amount}},
+	))
+	assert.True(t, h.Sink(db))
+
+	rm, err := h.Rollback(&model.BlockStatus{Chain: "eth", BlockNumber: 1}, "")
+	assert.NoError(t, err)
+	assert.Len(t, rm.Balances[DBActionUpdate], 1)
+	assert.Len(t, rm.Balances[DBActionDelete], 1)
+
+	balances := make([]*model.Balances, 0)
+	assert.NoError(t, db.SqlDB.Order("address").Find(&balances, "chain = ?", "eth").Error)
+	assert.Len(t, balances, 2)
+	assert.Equal(t, "0xa1", balances[0].Address)
+	assert.Equal(t, "100", balances[0].Balance.String())
+	assert.Equal(t, "0xc1", balances[1].Address)
+	assert.Equal(t, "100", balances[1].Balance.String())
+
+	txs, err := db.FindTransactions("eth", common.BigToHash(big.NewInt(21)))
+	assert.NoError(t, err)
+	assert.Len(t, txs, 0)
+}
@@ -25,6 +25,7 @@ package devents
 import (
 	"github.com/shopspring/decimal"
 	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/model"
 )
 
 const (
@@ -34,6 +35,12 @@ const (
 	OperateList     string = "list"
 	OperateDelist   string = "delist"
 	OperateExchange string = "exchange"
+	OperateCreate   string = "create"
+
+	// ierc-20 freeze ops
+	OperateFreezeSell    string = "freeze_sell"
+	OperateUnfreezeSell  string = "unfreeze_sell"
+	OperateProxyTransfer string = "proxy_transfer"
 )
 
 type MetaData struct {
@@ -59,6 +66,12 @@ type Deploy struct {
 	MaxSupply decimal.Decimal
 	MintLimit decimal.Decimal
 	Decimal   int8
+
+	// model.TransferTypeHash: balances are moved by inscribe-transfer utxos
+	TransferType int8
+
+	// ierc-20 pow mint tx hash prefix
+	Workc string
 }
 
 type Mint struct {
@@ -73,9 +86,64 @@ type Receive struct {
 	Init    bool
 }
 
+// Inscribe
+/***************************************
+ * inscribe-transfer, locks the owner's available balance
+ * into a transferable inscription utxo
+ ***************************************/
+type Inscribe struct {
+	Owner   string
+	Amount  decimal.Decimal
+	Sn      string // inscription id
+	Spender string // address allowed to spend besides the owner
+	Vout    uint32 // output holding the inscribed sat, btc only
+	Offset  uint64 // offset of the inscribed sat in the output, btc only
+}
+
 type Transfer struct {
 	Sender   string
 	Receives []*Receive
+
+	// root hash of the spent transferable inscription utxo,
+	// the amount has been locked from the sender's available balance
+	UTXO string
+}
+
+// Ethscription
+/***************************************
+ * ethscription created or transferred, by calldata or event logs
+ ***************************************/
+type Ethscription struct {
+	Id         string // hash of the creation tx
+	From       string // creator, or the previous owner
+	To         string // initial owner, or the receiver
+	ContentUri string // creation only
+	ContentSha string
+	Mimetype   string
+	LogIndex   int64 // -1 for calldata
+}
+
+// Listing
+/***************************************
+ * marketplace sell order listed, or closed by
+ * exchange (filled) / delist (cancelled)
+ ***************************************/
+type Listing struct {
+	ListId string // hash of the list tx
+	Seller string
+	Market string
+	Amount decimal.Decimal
+	Status model.ListingStatus
+	Buyer  string          // filled only
+	Price  decimal.Decimal // total price of the order, closing only
+}
+
+// UnitPrice price per unit of the listed amount
+func (l *Listing) UnitPrice() decimal.Decimal {
+	if l.Amount.LessThanOrEqual(decimal.Zero) {
+		return decimal.Zero
+	}
+	return l.Price.DivRound(l.Amount, 18)
 }
 
 type TxResult struct {
@@ -84,5 +152,13 @@ type TxResult struct {
 	Tx       *xycommon.RpcTransaction
 	Mint     *Mint
 	Deploy   *Deploy
+	Inscribe *Inscribe
 	Transfer *Transfer
+	Listing  *Listing
+
+	// op index in the tx, the position in the parsed results of the tx
+	SubIndex uint32
+
+	// protocol ethscriptions, no tick data
+	Ethscription *Ethscription
 }
@@ -27,9 +27,12 @@ import (
 	"fmt"
 	"github.com/alitto/pond"
 	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
 	"github.com/uxuycom/indexer/devents"
-	"github.com/uxuycom/indexer/protocol"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/btc/ord"
 	"github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/protocol/evm/ethscriptions"
 	"github.com/uxuycom/indexer/xyerrors"
 	"github.com/uxuycom/indexer/xylog"
 	"math/big"
@@ -39,7 +42,38 @@ import (
 	"time"
 )
 
-func (e *Explorer) validReceiptTxs(items []*xycommon.RpcTransaction) ([]*xycommon.RpcTransaction, *xyerrors.InsError) {
+// detectBlockReceipts check whether the node supports eth_getBlockReceipts
+func (e *Explorer) detectBlockReceipts() {
+	if e.config.Chain.ChainGroup == model.BtcChainGroup {
+		return
+	}
+
+	num, err := e.node.BlockNumber(e.ctx)
+	if err != nil {
+		xylog.Logger.Warnf("detect block receipts support failed, use tx receipts. err=%v", err)
+		return
+	}
+
+	header, err := e.node.HeaderByNumber(e.ctx, new(big.Int).SetUint64(num))
+	if err != nil {
+		xylog.Logger.Warnf("detect block receipts support failed, use tx receipts. err=%v", err)
+		return
+	}
+
+	if _, err = e.node.BlockReceipts(e.ctx, header.Hash); err != nil {
+		xylog.Logger.Infof("block receipts not supported by node, use tx receipts. err=%v", err)
+		return
+	}
+	e.blockReceipts.Store(true)
+	xylog.Logger.Infof("block receipts supported by node")
+}
+
+func (e *Explorer) validReceiptTxs(block *xycommon.RpcBlock, items []*xycommon.RpcTransaction) ([]*xycommon.RpcTransaction, *xyerrors.InsError) {
+	// bitcoin txs in a block are always valid
+	if len(items) < 1 || e.config.Chain.ChainGroup == model.BtcChainGroup {
+		return items, nil
+	}
+
 	startTs := time.Now()
 	defer func() {
 		xylog.Logger.Infof("handle txs, fetch receipt data cost[%v], items[%d]", time.Since(startTs), len(items))
@@ -50,30 +84,26 @@ func (e *Explorer) validReceiptTxs(items []*xycommon.RpcTransaction) ([]*xycommo
 		txHashList[item.Hash] = struct{}{}
 	}
 
-	workers := int(e.config.Scan.TxBatchWorkers)
-	pool := pond.New(workers, 0, pond.MinWorkers(workers))
-
 	receiptsMap := &sync.Map{}
-	for txHash := range txHashList {
-		hash := txHash
-		pool.Submit(func() {
-			r, err := e.node.TransactionReceipt(e.ctx, hash)
-			if err != nil {
-				xylog.Logger.Errorf("get tx receipt err:%v, tx:%s", err, hash)
-				return
-			}
+	if len(txHashList) >= blockReceiptsMinTxs && e.blockReceipts.Load() {
+		if err := e.fetchBlockReceipts(block, receiptsMap); err != nil {
+			xylog.Logger.Errorf("get block[%d] receipts err:%v & fallback to tx receipts", block.Number.Uint64(), err)
+		}
 
-			if r == nil {
-				xylog.Logger.Errorf("get tx receipt nil, tx:%s", hash)
-				return
+		// the receipts missing in the block receipts are fetched one by one
+		missing := make(map[string]struct{}, len(txHashList))
+		for hash := range txHashList {
+			if _, ok := receiptsMap.Load(hash); !ok {
+				missing[hash] = struct{}{}
 			}
-			receiptsMap.Store(hash, r)
-		})
+		}
+		if len(missing) > 0 {
+			e.fetchTxReceipts(missing, receiptsMap)
+		}
+	} else {
+		e.fetchTxReceipts(txHashList, receiptsMap)
 	}
 
-	// Stop the pool and wait for all submitted tasks to complete
-	pool.StopAndWait()
-
 	results := make([]*xycommon.RpcTransaction, 0, len(items))
 	for _, item := range items {
 		rv, ok := receiptsMap.Load(item.Hash)
@@ -101,10 +131,92 @@ func (e *Explorer) validReceiptTxs(items []*xycommon.RpcTransaction) ([]*xycommo
 	return results, nil
 }
 
-func (e *Explorer) tryFilterTxs(txs []*xycommon.RpcTransaction) []*xycommon.RpcTransaction {
+// validReceipts fetch the receipts of both the candidate & rejected txs
+func (e *Explorer) validReceipts(block *xycommon.RpcBlock, txs []*xycommon.RpcTransaction, rejected []*rejectedTx) ([]*xycommon.RpcTransaction, []*rejectedTx, *xyerrors.InsError) {
+	items := make([]*xycommon.RpcTransaction, 0, len(txs)+len(rejected))
+	items = append(items, txs...)
+	for _, r := range rejected {
+		items = append(items, r.tx)
+	}
+
+	items, err := e.validReceiptTxs(block, items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	succeeded := make(map[*xycommon.RpcTransaction]struct{}, len(items))
+	for _, item := range items {
+		succeeded[item] = struct{}{}
+	}
+
 	validTxs := make([]*xycommon.RpcTransaction, 0, len(txs))
 	for _, tx := range txs {
-		pt, md := protocol.GetProtocol(e.config, tx)
+		if _, ok := succeeded[tx]; ok {
+			validTxs = append(validTxs, tx)
+		}
+	}
+
+	validRejects := make([]*rejectedTx, 0, len(rejected))
+	for _, r := range rejected {
+		if _, ok := succeeded[r.tx]; ok {
+			validRejects = append(validRejects, r)
+		}
+	}
+	return validTxs, validRejects, nil
+}
+
+// fetchBlockReceipts fetch all receipts of the block in one call
+func (e *Explorer) fetchBlockReceipts(block *xycommon.RpcBlock, receiptsMap *sync.Map) error {
+	receipts, err := e.node.BlockReceipts(e.ctx, block.Hash)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range receipts {
+		if r != nil {
+			receiptsMap.Store(r.TxHash.String(), r)
+		}
+	}
+	return nil
+}
+
+// fetchTxReceipts fetch receipts one by one with the tx workers pool
+func (e *Explorer) fetchTxReceipts(txHashList map[string]struct{}, receiptsMap *sync.Map) {
+	workers := int(e.config.Scan.TxBatchWorkers)
+	pool := pond.New(workers, 0, pond.MinWorkers(workers))
+
+	for txHash := range txHashList {
+		hash := txHash
+		pool.Submit(func() {
+			r, err := e.node.TransactionReceipt(e.ctx, hash)
+			if err != nil {
+				xylog.Logger.Errorf("get tx receipt err:%v, tx:%s", err, hash)
+				return
+			}
+
+			if r == nil {
+				xylog.Logger.Errorf("get tx receipt nil, tx:%s", hash)
+				return
+			}
+			receiptsMap.Store(hash, r)
+		})
+	}
+
+	// Stop the pool and wait for all submitted tasks to complete
+	pool.StopAndWait()
+}
+
+func (e *Explorer) tryFilterTxs(txs []*xycommon.RpcTransaction) ([]*xycommon.RpcTransaction, []*rejectedTx) {
+	validTxs := make([]*xycommon.RpcTransaction, 0, len(txs))
+	rejects := make([]*rejectedTx, 0)
+
+	// btc txs may spend transferable inscriptions revealed in the same block, which
+	// are only cached while handling, protocol filters are applied in handleTxs
+	if e.config.Chain.ChainGroup == model.BtcChainGroup {
+		return txs, rejects
+	}
+	for _, tx := range txs {
+		pt, md := e.protocols.GetProtocol(tx)
 		if pt == nil {
 			continue
 		}
@@ -122,11 +234,12 @@ func (e *Explorer) tryFilterTxs(txs []*xycommon.RpcTransaction) []*xycommon.RpcT
 		// Add mint completed filter
 		if e.filterMintCompleted(md) {
 			xylog.Logger.Infof("tx hit mint completed strategy & ignore. tx[%s]", tx.Hash)
+			rejects = append(rejects, &rejectedTx{tx: tx, md: md, err: xyerrors.ErrMintCompleted})
 			continue
 		}
 		validTxs = append(validTxs, tx)
 	}
-	return validTxs
+	return validTxs, rejects
 }
 
 func (e *Explorer) filterMintCompleted(md *devents.MetaData) bool {
@@ -154,15 +267,23 @@ func (e *Explorer) filterMintCompleted(md *devents.MetaData) bool {
 	return false
 }
 
-func (e *Explorer) handleTxs(block *xycommon.RpcBlock, txs []*xycommon.RpcTransaction) *xyerrors.InsError {
+func (e *Explorer) handleTxs(block *xycommon.RpcBlock, txs []*xycommon.RpcTransaction, rejected []*rejectedTx) *xyerrors.InsError {
 	startTs := time.Now()
 	defer func() {
 		xylog.Logger.Infof("handle txs, parse & async sink cost[%v], txs[%d]", time.Since(startTs), len(txs))
 	}()
 
 	blockTxResults := make([]*devents.DBModelEvent, 0, len(txs))
+	gasTxs := make([]*gasTx, 0, len(txs)+len(rejected))
+
+	// the gas of the rejected txs is spent too, e.g. mints after the supply exhausted
+	rejects := make([]*model.RejectedTx, 0, len(rejected))
+	for _, r := range rejected {
+		rejects = append(rejects, e.buildRejectedTx(block, r.tx, r.md, r.err))
+		gasTxs = append(gasTxs, &gasTx{tx: r.tx, md: r.md})
+	}
 	for _, tx := range txs {
-		pt, md := protocol.GetProtocol(e.config, tx)
+		pt, md := e.protocols.GetProtocol(tx)
 		if pt == nil {
 			continue
 		}
@@ -183,6 +304,8 @@ func (e *Explorer) handleTxs(block *xycommon.RpcBlock, txs []*xycommon.RpcTransa
 		}
 		if err != nil {
 			xylog.Logger.Infof("tx data parsed failed. md[%v], tx[%s], err[%v]", md, tx.Hash, err)
+			rejects = append(rejects, e.buildRejectedTx(block, tx, md, err))
+			gasTxs = append(gasTxs, &gasTx{tx: tx, md: md})
 			continue
 		}
 		xylog.Logger.Infof("tx data parsed success. md[%v], tx[%s]", md, tx.Hash)
@@ -193,12 +316,44 @@ func (e *Explorer) handleTxs(block *xycommon.RpcBlock, txs []*xycommon.RpcTransa
 		}
 
 		// update cache
-		for _, txResult := range txResults {
+		var gasMD *devents.MetaData
+		for idx, txResult := range txResults {
+			// ops of the tx are kept apart by the stable parse position
+			txResult.SubIndex = uint32(idx)
+
+			// tick may be resolved while parsing, e.g. exchange events
+			if !e.tickEnabled(txResult.MD.Tick) {
+				continue
+			}
+
 			e.txResultHandler.UpdateCache(txResult)
 			blockTxResults = append(blockTxResults, e.txResultHandler.BuildModel(txResult))
+
+			// tx gas is accounted to the first op only
+			if gasMD == nil {
+				gasMD = txResult.MD
+			}
+		}
+
+		if gasMD != nil {
+			gasTxs = append(gasTxs, &gasTx{tx: tx, md: gasMD})
 		}
 	}
-	e.writeDBAsync(block, blockTxResults)
+
+	// balances failed loading were taken as missing, the txs are parsed again
+	if e.dCache.Balance != nil {
+		if err := e.dCache.Balance.LoadErr(); err != nil {
+			return xyerrors.Wrap(err, xyerrors.ErrInternal.Code(), xyerrors.ErrInternal.Message())
+		}
+	}
+
+	blockGas, tickGas := e.SyncGas(block, gasTxs)
+	e.writeDBAsync(block, &devents.Event{
+		Items:    blockTxResults,
+		BlockGas: blockGas,
+		TickGas:  tickGas,
+		Rejects:  rejects,
+	})
 	return nil
 }
 
@@ -207,6 +362,10 @@ func (e *Explorer) extractTxsFromBlock(block *xycommon.RpcBlock) []*xycommon.Rpc
 		return nil
 	}
 
+	if e.config.Chain.ChainGroup == model.BtcChainGroup {
+		return e.extractBTCTxsFromBlock(block)
+	}
+
 	txs := make([]*xycommon.RpcTransaction, 0, len(block.Transactions))
 	for _, tx := range block.Transactions {
 		// fast check & filter invalid txs
@@ -218,12 +377,45 @@ func (e *Explorer) extractTxsFromBlock(block *xycommon.RpcBlock) []*xycommon.Rpc
 	return txs
 }
 
+// extractBTCTxsFromBlock txs revealing inscriptions or spending transferable inscriptions
+func (e *Explorer) extractBTCTxsFromBlock(block *xycommon.RpcBlock) []*xycommon.RpcTransaction {
+	txs := make([]*xycommon.RpcTransaction, 0, len(block.Transactions))
+	revealed := make(map[string]struct{}, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		// inscription envelopes are revealed in taproot witnesses
+		if ord.ContainsEnvelope(tx.Vin) {
+			revealed[tx.Hash] = struct{}{}
+			txs = append(txs, tx)
+			continue
+		}
+
+		for _, in := range tx.Vin {
+			// transferable inscriptions revealed in this block, or cached with the output holding them
+			if in.Txid == "" {
+				continue
+			}
+
+			_, ok := revealed[in.Txid]
+			if !ok {
+				var item *dcache.UTXOItem
+				ok, item = e.dCache.UTXO.Get(in.Txid)
+				ok = ok && item.Vout == in.Vout
+			}
+			if ok {
+				txs = append(txs, tx)
+				break
+			}
+		}
+	}
+	return txs
+}
+
 func (e *Explorer) FlushDB() {
 	defer func() {
 		e.cancel()
 		if err := recover(); err != nil {
 			stack := string(debug.Stack())
-			xylog.Logger.Panicf("flush db error & quit, err[%v], stack=%v", err, stack)
+			xylog.Logger.Errorf("flush db error & quit, err[%v], stack=%v", err, stack)
 		}
 		xylog.Logger.Infof("flush db quit")
 	}()
@@ -235,22 +427,89 @@ func (e *Explorer) Index() {
 		e.cancel()
 		if err := recover(); err != nil {
 			stack := string(debug.Stack())
-			xylog.Logger.Panicf("index error & quit, err[%v], stack:%v", err, stack)
+			xylog.Logger.Errorf("index error & quit, err[%v], stack:%v", err, stack)
 		}
 		xylog.Logger.Infof("index quit")
 	}()
 	xylog.Logger.Infof("start indexing...")
+	e.detectBlockReceipts()
 
 	for {
 		select {
 		case block := <-e.blocks:
 			e.handleBlock(block)
+			if archiver, ok := e.node.(xycommon.IBlockArchiver); ok {
+				archiver.FinishBlock(block.Number.Uint64())
+			}
+
+			// cache changes flushed into db can not be reverted any more
+			e.dCache.Commit(e.dEvent.CommittedBlock())
+
+			// before marked indexed, the cache is not rolled back while copying
+			e.trySnapshot()
+			e.indexedBlockNum.Store(block.Number.Uint64())
 		case <-e.ctx.Done():
 			return
 		}
 	}
 }
 
+// trySnapshot
+/***************************************
+ * copy the cache periodically, tagged with the last block pushed to db,
+ * and write it in background. the blocks after it have no changes, so the
+ * snapshot is equal to db once the block flushed, it is not restored otherwise
+ ***************************************/
+func (e *Explorer) trySnapshot() {
+	cfg := e.config.Snapshot
+	if cfg == nil || cfg.Dir == "" {
+		return
+	}
+
+	interval := time.Duration(cfg.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultSnapshotInterval
+	}
+	if time.Since(e.snapshotAt) < interval {
+		return
+	}
+
+	// the cache is reverted once the failed events dropped
+	status := e.lastWritten.Load()
+	if status == nil || e.dEvent.Failed() {
+		return
+	}
+
+	// the last snapshot is still being written
+	if !e.snapshotting.CompareAndSwap(false, true) {
+		return
+	}
+
+	e.snapshotAt = time.Now()
+	s := e.dCache.TakeSnapshot(status)
+	go func() {
+		defer e.snapshotting.Store(false)
+		if err := s.Write(dcache.SnapshotPath(cfg.Dir, e.config.Chain.ChainName)); err != nil {
+			xylog.Logger.Errorf("write cache snapshot err:%v", err)
+		}
+	}()
+}
+
+// removeSnapshot remove the snapshot file after the one being written finished,
+// the cache snapshots taken before a rollback are stale
+func (e *Explorer) removeSnapshot() error {
+	cfg := e.config.Snapshot
+	if cfg == nil || cfg.Dir == "" {
+		return nil
+	}
+
+	for !e.snapshotting.CompareAndSwap(false, true) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	defer e.snapshotting.Store(false)
+	return dcache.RemoveSnapshot(dcache.SnapshotPath(cfg.Dir, e.config.Chain.ChainName))
+}
+
 func (e *Explorer) handleBlock(block *xycommon.RpcBlock) {
 	xylog.Logger.Infof("start handle block:%d", block.Number.Uint64())
 	st := time.Now()
@@ -265,15 +524,19 @@ func (e *Explorer) handleBlock(block *xycommon.RpcBlock) {
 			return
 		}
 
+		// record cache changes of the block, reverted if flushing failed
+		e.dCache.Begin(block.Number.Uint64())
+
 		// extract txs from block & fast checking invalid tx
 		txs := e.extractTxsFromBlock(block)
 
 		// try filter invalid txs
-		txs = e.tryFilterTxs(txs)
+		txs, rejected := e.tryFilterTxs(txs)
 
-		// Add receipt data & filter invalid status
-		txs, err := e.validReceiptTxs(txs)
+		// Add receipt data & filter invalid status, the rejected txs are kept for the gas stats
+		txs, rejected, err := e.validReceipts(block, txs, rejected)
 		if err != nil {
+			e.dCache.Journal.Revert(block.Number.Uint64() - 1)
 			xylog.Logger.Errorf("fetch receipt data internal err:%v & retry later[%d]", err, retry)
 			retry++
 			<-time.After(time.Millisecond * 100)
@@ -281,8 +544,10 @@ func (e *Explorer) handleBlock(block *xycommon.RpcBlock) {
 		}
 
 		// Handle: parse txs & sync cache / db
-		err = e.handleTxs(block, txs)
+		err = e.handleTxs(block, txs, rejected)
 		if err != nil {
+			// undo the cache changes of the txs handled before the error
+			e.dCache.Journal.Revert(block.Number.Uint64() - 1)
 			xylog.Logger.Errorf("parse internal err:%v & retry later[%d]", err, retry)
 			retry++
 			<-time.After(time.Millisecond * 100)
@@ -292,23 +557,25 @@ func (e *Explorer) handleBlock(block *xycommon.RpcBlock) {
 	}
 }
 
-func (e *Explorer) writeDBAsync(block *xycommon.RpcBlock, txResults []*devents.DBModelEvent) {
-	if block == nil || len(txResults) <= 0 {
+func (e *Explorer) writeDBAsync(block *xycommon.RpcBlock, event *devents.Event) {
+	if block == nil || (len(event.Items) <= 0 && len(event.Rejects) <= 0) {
 		return
 	}
 
 	start := time.Now()
 
 	//write db async
-	event := &devents.Event{
-		Chain:     e.config.Chain.ChainName,
-		ChainId:   txResults[0].Tx.ChainId,
-		BlockNum:  block.Number.Uint64(),
-		BlockTime: block.Time,
-		BlockHash: block.Hash,
-		Items:     txResults,
+	event.Chain = e.config.Chain.ChainName
+	event.BlockNum = block.Number.Uint64()
+	event.BlockTime = block.Time
+	event.BlockHash = block.Hash
+	if len(event.Items) > 0 {
+		event.ChainId = event.Items[0].Tx.ChainId
+	} else {
+		event.ChainId = event.Rejects[0].ChainId
 	}
 	e.dEvent.WriteDBAsync(event)
+	e.lastWritten.Store(&model.BlockStatus{Chain: event.Chain, BlockNumber: event.BlockNum, BlockHash: event.BlockHash})
 
 	xylog.Logger.Infof("push block data to events, cost[%v], block[%d]", time.Since(start), block.Number.Uint64())
 }
@@ -331,7 +598,9 @@ func (e *Explorer) fastChecking(tx *xycommon.RpcTransaction) bool {
 	if strings.HasPrefix(trxContent, common.DataPrefix) {
 		return true
 	}
-	return false
+
+	// ethscription id checking
+	return ethscriptions.IsTransferCalldata(trxContent)
 }
 
 func (e *Explorer) protocolEnabled(protocol string) bool {
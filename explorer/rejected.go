@@ -0,0 +1,76 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package explorer
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/xyerrors"
+	"time"
+)
+
+// maxRejectMessageLen length of the rejected_txs.message column
+const maxRejectMessageLen = 512
+
+// rejectedTx a candidate tx rejected before parsing
+type rejectedTx struct {
+	tx  *xycommon.RpcTransaction
+	md  *devents.MetaData
+	err *xyerrors.InsError
+}
+
+// buildRejectedTx record why a candidate tx is not counted
+func (e *Explorer) buildRejectedTx(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData, err *xyerrors.InsError) *model.RejectedTx {
+	// the innermost error carries the reject reason, e.g. data verified failed <- mint completed
+	for {
+		cause, ok := err.Cause(nil).(*xyerrors.InsError)
+		if !ok || cause == nil {
+			break
+		}
+		err = cause
+	}
+
+	item := &model.RejectedTx{
+		Chain:       e.config.Chain.ChainName,
+		Protocol:    md.Protocol,
+		Tick:        md.Tick,
+		Op:          md.Operate,
+		BlockHeight: block.Number.Uint64(),
+		BlockTime:   time.Unix(int64(block.Time), 0),
+		TxHash:      common.FromHex(tx.Hash),
+		Address:     tx.From,
+		To:          tx.To,
+		Code:        err.Code(),
+		Message:     err.Message(),
+	}
+	if len(item.Message) > maxRejectMessageLen {
+		item.Message = item.Message[:maxRejectMessageLen]
+	}
+
+	if tx.ChainID != nil {
+		item.ChainId = tx.ChainID.Int64()
+	}
+	return item
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package explorer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"time"
+)
+
+const defaultReorgWindow = 128
+
+// ErrReorgTooDeep no common ancestor found in the saved block hashes, the chain has to be reindexed manually
+var ErrReorgTooDeep = errors.New("reorg deeper than the block window")
+
+// ReorgError the parent hash of the block does not match the saved one
+type ReorgError struct {
+	BlockNum uint64
+}
+
+func (e *ReorgError) Error() string {
+	return fmt.Sprintf("chain reorganization detected at block[%d]", e.BlockNum)
+}
+
+// blockWindow keeps the hashes of recent scanned blocks
+type blockWindow struct {
+	size   uint64
+	hashes map[uint64]string
+}
+
+func newBlockWindow(size uint64) *blockWindow {
+	if size <= 0 {
+		size = defaultReorgWindow
+	}
+	return &blockWindow{
+		size:   size,
+		hashes: make(map[uint64]string, size),
+	}
+}
+
+func (w *blockWindow) Put(blockNum uint64, hash string) {
+	w.hashes[blockNum] = hash
+	if blockNum >= w.size {
+		delete(w.hashes, blockNum-w.size)
+	}
+}
+
+func (w *blockWindow) Get(blockNum uint64) (string, bool) {
+	hash, ok := w.hashes[blockNum]
+	return hash, ok
+}
+
+// Truncate drop all hashes above the block number
+func (w *blockWindow) Truncate(blockNum uint64) {
+	for num := range w.hashes {
+		if num > blockNum {
+			delete(w.hashes, num)
+		}
+	}
+}
+
+// checkParent verify the block connects to the saved chain
+func (w *blockWindow) checkParent(blockNum uint64, parentHash string) bool {
+	hash, ok := w.Get(blockNum - 1)
+	return !ok || hash == parentHash
+}
+
+// initBlockWindow seed the window with the last saved block
+func (e *Explorer) initBlockWindow(startBlock uint64) error {
+	status, err := e.db.QueryLastBlockStatus(e.config.Chain.ChainName)
+	if err != nil {
+		return err
+	}
+
+	if status != nil && status.BlockHash != "" && status.BlockNumber+1 == startBlock {
+		e.hashes.Put(status.BlockNumber, status.BlockHash)
+	}
+	return nil
+}
+
+// handleReorg
+/***************************************
+ * wait all pushed blocks flushed into db, find the common ancestor
+ * then rollback db & cache data to it for re-indexing the canonical chain.
+ * nothing is changed on errors, the reorg is detected again on the next scan
+ ***************************************/
+func (e *Explorer) handleReorg(blockNum uint64) error {
+	xylog.Logger.Warnf("chain reorganization detected at block[%d], chain:%s", blockNum, e.config.Chain.ChainName)
+
+	// wait indexing & flushing finished
+	e.waitFlushed()
+	select {
+	case <-e.ctx.Done():
+		return nil
+	default:
+	}
+
+	// cache reverted & blocks scanned again first, the reorg is detected again if still there
+	if e.dEvent.Failed() {
+		return nil
+	}
+
+	ancestor, err := e.findCommonAncestor(blockNum - 1)
+	if err != nil {
+		return fmt.Errorf("find common ancestor failed, block[%d], err:%w", blockNum, err)
+	}
+
+	if err = e.removeSnapshot(); err != nil {
+		return fmt.Errorf("remove cache snapshot failed, err:%w", err)
+	}
+
+	rm, err := e.dEvent.Rollback(ancestor, "")
+	if err != nil {
+		return fmt.Errorf("rollback to block[%d] failed, err:%w", ancestor.BlockNumber, err)
+	}
+	e.txResultHandler.Rollback(rm)
+	e.dCache.Journal.Reset()
+	e.lastWritten.Store(nil)
+	if archiver, ok := e.node.(xycommon.IBlockArchiver); ok {
+		archiver.RollbackBlocks(ancestor.BlockNumber)
+	}
+
+	e.hashes.Truncate(ancestor.BlockNumber)
+	e.pushedBlockNum.Store(ancestor.BlockNumber)
+	e.indexedBlockNum.Store(ancestor.BlockNumber)
+	e.currentBlockNum.Store(ancestor.BlockNumber + 1)
+	xylog.Logger.Infof("rollback to common ancestor block[%d] finished, chain:%s", ancestor.BlockNumber, e.config.Chain.ChainName)
+	return nil
+}
+
+// findCommonAncestor walk back from the block till the saved hash matches the canonical chain
+func (e *Explorer) findCommonAncestor(blockNum uint64) (*model.BlockStatus, error) {
+	for num := blockNum; num > 0; num-- {
+		hash, ok := e.hashes.Get(num)
+		if !ok {
+			break
+		}
+
+		ctx, cancel := context.WithTimeout(e.ctx, 5*time.Second)
+		header, err := e.node.HeaderByNumber(ctx, new(big.Int).SetUint64(num))
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("rpc HeaderByNumber[%d] err:%v", num, err)
+		}
+
+		if header.Hash == hash {
+			return &model.BlockStatus{
+				Chain:       e.config.Chain.ChainName,
+				BlockHash:   hash,
+				BlockNumber: num,
+				BlockTime:   time.Unix(int64(header.Time), 0),
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w[%d]", ErrReorgTooDeep, e.hashes.size)
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package explorer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBlockWindow(t *testing.T) {
+	w := newBlockWindow(3)
+	w.Put(10, "0x0a")
+	w.Put(11, "0x0b")
+	w.Put(12, "0x0c")
+	w.Put(13, "0x0d")
+
+	_, ok := w.Get(10)
+	assert.False(t, ok, "block out of window should be dropped")
+
+	// unknown parent is always accepted
+	assert.True(t, w.checkParent(11, "0xff"))
+	assert.True(t, w.checkParent(14, "0x0d"))
+	assert.False(t, w.checkParent(14, "0xff"))
+
+	w.Truncate(11)
+	_, ok = w.Get(12)
+	assert.False(t, ok)
+	hash, ok := w.Get(11)
+	assert.True(t, ok)
+	assert.Equal(t, "0x0b", hash)
+}
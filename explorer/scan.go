@@ -32,6 +32,8 @@ import (
 	"github.com/uxuycom/indexer/config"
 	"github.com/uxuycom/indexer/dcache"
 	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol"
 	"github.com/uxuycom/indexer/storage"
 	"github.com/uxuycom/indexer/xylog"
 	"golang.org/x/sync/errgroup"
@@ -44,19 +46,39 @@ import (
 	"time"
 )
 
+// blockReceiptsMinTxs fetch receipts by block only if enough txs to save rpc calls
+const blockReceiptsMinTxs = 2
+
+// defaultSnapshotInterval interval of cache snapshots if not configured
+const defaultSnapshotInterval = 10 * time.Minute
+
 type Explorer struct {
 	config          *config.Config
 	node            xycommon.IRPCClient
 	db              *storage.DBClient
 	ctx             context.Context
 	cancel          context.CancelFunc
-	quit            chan os.Signal
+	quit            chan os.Signal // notified once the scan quits, the chain is stopped
+	stopped         atomic.Bool
 	blocks          chan *xycommon.RpcBlock
 	txResultHandler *devents.TxResultHandler
 	dCache          *dcache.Manager
+	protocols       *protocol.Protocols
 	dEvent          *devents.DEvent
 	latestBlockNum  atomic.Uint64
 	currentBlockNum atomic.Uint64
+	pushedBlockNum  atomic.Uint64
+	indexedBlockNum atomic.Uint64
+	hashes          *blockWindow
+	newHeads        chan struct{}
+	subscribed      atomic.Bool
+	blockReceipts   atomic.Bool // node supports eth_getBlockReceipts
+	snapshotAt      time.Time   // last cache snapshot taken
+	fromBlock       uint64      // optional scan range, used for reindex
+	toBlock         uint64
+
+	snapshotting atomic.Bool                       // a cache snapshot is being written
+	lastWritten  atomic.Pointer[model.BlockStatus] // the last block pushed to db, nil after rollback
 }
 
 func NewExplorer(rpcClient xycommon.IRPCClient, dbc *storage.DBClient, cfg *config.Config, dCache *dcache.Manager, dEvent *devents.DEvent, quit chan os.Signal) *Explorer {
@@ -72,22 +94,33 @@ func NewExplorer(rpcClient xycommon.IRPCClient, dbc *storage.DBClient, cfg *conf
 		db:              dbc,
 		config:          cfg,
 		dCache:          dCache,
+		protocols:       protocol.NewProtocols(&cfg.Chain, dCache),
 		blocks:          make(chan *xycommon.RpcBlock, 100),
 		txResultHandler: txResultHandler,
+		hashes:          newBlockWindow(cfg.Scan.ReorgWindow),
+		newHeads:        make(chan struct{}, 1),
+		snapshotAt:      time.Now(),
 
 		dEvent: dEvent,
 	}
 	return exp
 }
 
+// SetScanRange scan the blocks [from, to] only & quit after all of them indexed
+func (e *Explorer) SetScanRange(from, to uint64) {
+	e.fromBlock = from
+	e.toBlock = to
+}
+
 func (e *Explorer) Scan() {
 	defer func() {
 		e.cancel()
 		if err := recover(); err != nil {
 			stack := string(debug.Stack())
-			xylog.Logger.Panicf("scan error & quit, err[%v],stack=%v", err, stack)
+			xylog.Logger.Errorf("scan error & quit, err[%v],stack=%v", err, stack)
 		}
-		xylog.Logger.Infof("scan quit")
+		e.stopped.Store(true)
+		xylog.Logger.Infof("scan quit. chain:%s", e.config.Chain.ChainName)
 		e.quit <- syscall.SIGUSR1
 	}()
 	xylog.Logger.Infof("start scanning...")
@@ -95,7 +128,21 @@ func (e *Explorer) Scan() {
 	// Prioritize using data retrieved from the database
 	blockNum, err := e.db.QueryLastBlock(e.config.Chain.ChainName)
 	if err != nil {
-		xylog.Logger.Fatalf("load hisotry block index err:%v", err)
+		xylog.Logger.Errorf("load hisotry block index err:%v", err)
+		return
+	}
+
+	switch e.config.Scan.ConfirmationMode {
+	case "", config.ConfirmationModeDelayed, config.ConfirmationModeSafe, config.ConfirmationModeFinalized:
+	default:
+		xylog.Logger.Errorf("invalid scan confirmation mode[%s]", e.config.Scan.ConfirmationMode)
+		return
+	}
+
+	safeMode := e.config.Scan.ConfirmationMode == config.ConfirmationModeSafe || e.config.Scan.ConfirmationMode == config.ConfirmationModeFinalized
+	if safeMode && e.config.Chain.ChainGroup == model.BtcChainGroup {
+		xylog.Logger.Errorf("scan confirmation mode[%s] not supported by btc chains", e.config.Scan.ConfirmationMode)
+		return
 	}
 
 	startBlock := e.config.Scan.StartBlock
@@ -103,8 +150,21 @@ func (e *Explorer) Scan() {
 		startBlock = blockNum.Uint64() + 1
 	}
 
+	if e.fromBlock > 0 {
+		startBlock = e.fromBlock
+	}
+
+	// seed reorg detection window
+	if err = e.initBlockWindow(startBlock); err != nil {
+		xylog.Logger.Errorf("load last block status err:%v", err)
+		return
+	}
+
 	// update latest block number
 	go e.updateBlockLatestNumberTiming()
+	if e.config.Chain.Ws != "" {
+		go e.subscribeNewHeads()
+	}
 
 	// set start block number
 	e.currentBlockNum.Store(startBlock)
@@ -115,7 +175,23 @@ func (e *Explorer) Scan() {
 			return
 		default:
 		}
+
+		// blocks failed flushing are dropped, index them again
+		if e.dEvent.Failed() {
+			e.handleFlushFailure()
+			continue
+		}
+
 		startBlock = e.currentBlockNum.Load()
+		if e.toBlock > 0 && startBlock > e.toBlock {
+			e.waitFlushed()
+			if e.dEvent.Failed() {
+				continue
+			}
+			xylog.Logger.Infof("scan range[%d-%d] finished. chain:%s", e.fromBlock, e.toBlock, e.config.Chain.ChainName)
+			return
+		}
+
 		latestBlockNum := e.latestBlockNum.Load()
 		if latestBlockNum < 1 {
 			xylog.Logger.Infof("latest block number is zero. chain:%s", e.config.Chain.ChainName)
@@ -124,9 +200,9 @@ func (e *Explorer) Scan() {
 		}
 
 		// wait more blocks for safety
-		if startBlock > (latestBlockNum - e.config.Scan.DelayedBlockNum) {
+		if startBlock+e.delayedBlockNum() > latestBlockNum {
 			xylog.Logger.Infof("current block number[%d] is too close to the latest block number[%d]. chain:%s", startBlock, latestBlockNum, e.config.Chain.ChainName)
-			<-time.After(time.Second)
+			e.waitNewHead()
 			continue
 		}
 
@@ -135,11 +211,28 @@ func (e *Explorer) Scan() {
 			endBlock = startBlock + e.config.Scan.BlockBatchWorkers - 1
 		}
 
-		if endBlock > latestBlockNum {
-			endBlock = latestBlockNum
+		if endBlock+e.delayedBlockNum() > latestBlockNum {
+			endBlock = latestBlockNum - e.delayedBlockNum()
+		}
+
+		if e.toBlock > 0 && endBlock > e.toBlock {
+			endBlock = e.toBlock
 		}
 
 		err = e.batchScan(startBlock, endBlock)
+		var reorgErr *ReorgError
+		if errors.As(err, &reorgErr) {
+			err = e.handleReorg(reorgErr.BlockNum)
+			if errors.Is(err, ErrReorgTooDeep) {
+				xylog.Logger.Errorf("stop scanning, %v. chain:%s", err, e.config.Chain.ChainName)
+				return
+			}
+			if err != nil {
+				xylog.Logger.Errorf("handle reorg failed & retry after 1s. chain:%s err=%s", e.config.Chain.ChainName, err)
+				<-time.After(time.Second)
+			}
+			continue
+		}
 		if err != nil {
 			xylog.Logger.Errorf("batch block scanning failed. blocks[%d-%d] err=%s", startBlock, endBlock, err)
 			continue
@@ -162,6 +255,10 @@ func (e *Explorer) updateBlockLatestNumberTiming() {
 	for {
 		select {
 		case <-t.C:
+			// new heads are pushed by the subscription
+			if e.subscribed.Load() {
+				continue
+			}
 			if err := e.syncLatestBlockNumber(); err != nil {
 				xylog.Logger.Errorf("failed to obtain the current block height. chain:%s err=%s", e.config.Chain.ChainName, err)
 			}
@@ -177,7 +274,7 @@ func (e *Explorer) syncLatestBlockNumber() error {
 		return nil
 	}
 
-	num, err := e.node.BlockNumber(e.ctx)
+	num, err := e.headBlockNumber()
 	if err != nil {
 		return err
 	}
@@ -191,17 +288,46 @@ func (e *Explorer) syncLatestBlockNumber() error {
 	return nil
 }
 
+// headBlockNumber returns the head block number of the confirmation mode
+func (e *Explorer) headBlockNumber() (uint64, error) {
+	switch e.config.Scan.ConfirmationMode {
+	case config.ConfirmationModeSafe, config.ConfirmationModeFinalized:
+		return e.node.BlockNumberByTag(e.ctx, e.config.Scan.ConfirmationMode)
+	}
+	return e.node.BlockNumber(e.ctx)
+}
+
+// delayedBlockNum safe & finalized heads are already confirmed, no more blocks need to wait
+func (e *Explorer) delayedBlockNum() uint64 {
+	switch e.config.Scan.ConfirmationMode {
+	case config.ConfirmationModeSafe, config.ConfirmationModeFinalized:
+		return 0
+	}
+	return e.config.Scan.DelayedBlockNum
+}
+
 func (e *Explorer) scanLogs(startBlock, endBlock uint64, result chan map[string][]xycommon.RpcLog) {
-	if e.config.Filters == nil || len(e.config.Filters.EventTopics) <= 0 {
+	// configured topics & marketplace event topics
+	eventTopics := e.protocols.EventTopics()
+	if e.config.Filters != nil {
+		eventTopics = append(eventTopics, e.config.Filters.EventTopics...)
+	}
+	if len(eventTopics) <= 0 {
 		result <- nil
 		return
 	}
 
 	// filter Logs
 	topics := [][]common.Hash{{}}
-	topics[0] = make([]common.Hash, 0, len(e.config.Filters.EventTopics))
-	for _, ts := range e.config.Filters.EventTopics {
-		topics[0] = append(topics[0], common.HexToHash(ts))
+	topics[0] = make([]common.Hash, 0, len(eventTopics))
+	seen := make(map[common.Hash]struct{}, len(eventTopics))
+	for _, ts := range eventTopics {
+		topic := common.HexToHash(ts)
+		if _, ok := seen[topic]; ok {
+			continue
+		}
+		seen[topic] = struct{}{}
+		topics[0] = append(topics[0], topic)
 	}
 
 	retry := 0
@@ -273,6 +399,11 @@ func (e *Explorer) batchScan(startBlock, endBlock uint64) error {
 		}
 
 		block := blockVal.(*xycommon.RpcBlock)
+		if !e.hashes.checkParent(blockNum, block.ParentHash) {
+			return &ReorgError{BlockNum: blockNum}
+		}
+		e.hashes.Put(blockNum, block.Hash)
+
 		// add logs data
 		for _, tx := range block.Transactions {
 			if logs, ok1 := blockLogs[tx.Hash]; ok1 {
@@ -280,6 +411,7 @@ func (e *Explorer) batchScan(startBlock, endBlock uint64) error {
 			}
 		}
 		e.blocks <- block
+		e.pushedBlockNum.Store(blockNum)
 	}
 	return nil
 }
@@ -287,3 +419,58 @@ func (e *Explorer) batchScan(startBlock, endBlock uint64) error {
 func (e *Explorer) Stop() {
 	e.cancel()
 }
+
+// handleFlushFailure
+/***************************************
+ * the events failed flushing have been dropped, wait indexing finished,
+ * revert the cache to the last block in db & scan again from there
+ ***************************************/
+func (e *Explorer) handleFlushFailure() {
+	e.waitFlushed()
+	select {
+	case <-e.ctx.Done():
+		return
+	default:
+	}
+
+	blockNum, err := e.db.QueryLastBlock(e.config.Chain.ChainName)
+	if err != nil {
+		xylog.Logger.Errorf("load last block err:%v & retry after 1s", err)
+		<-time.After(time.Second)
+		return
+	}
+
+	lastBlock := blockNum.Uint64()
+	reverted := e.dCache.Journal.Revert(lastBlock)
+	e.lastWritten.Store(nil)
+
+	startBlock := lastBlock + 1
+	if lastBlock < 1 {
+		startBlock = e.config.Scan.StartBlock
+	}
+	if e.fromBlock > startBlock {
+		startBlock = e.fromBlock
+	}
+	if startBlock < 1 {
+		startBlock = 1
+	}
+
+	e.hashes.Truncate(startBlock - 1)
+	e.pushedBlockNum.Store(startBlock - 1)
+	e.indexedBlockNum.Store(startBlock - 1)
+	e.currentBlockNum.Store(startBlock)
+	e.dEvent.Resume()
+	xylog.Logger.Warnf("cache reverted to block[%d], blocks reverted[%d], scan again from block[%d], chain:%s", lastBlock, reverted, startBlock, e.config.Chain.ChainName)
+}
+
+// waitFlushed wait all pushed blocks indexed & flushed into db
+func (e *Explorer) waitFlushed() {
+	for e.indexedBlockNum.Load() < e.pushedBlockNum.Load() {
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-e.ctx.Done():
+			return
+		}
+	}
+	e.dEvent.WaitFlushed(e.ctx)
+}
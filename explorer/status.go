@@ -0,0 +1,49 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package explorer
+
+import "github.com/uxuycom/indexer/dcache"
+
+// Status the indexing progress of the chain
+type Status struct {
+	Chain        string `json:"chain"`
+	LatestBlock  uint64 `json:"latest_block"`
+	CurrentBlock uint64 `json:"current_block"`
+	IndexedBlock uint64 `json:"indexed_block"`
+	Subscribed   bool   `json:"subscribed"`
+	Stopped      bool   `json:"stopped"` // scanning quit, the other chains keep running
+
+	BalanceCache dcache.BalanceStats `json:"balance_cache"`
+}
+
+func (e *Explorer) Status() *Status {
+	return &Status{
+		Chain:        e.config.Chain.ChainName,
+		LatestBlock:  e.latestBlockNum.Load(),
+		CurrentBlock: e.currentBlockNum.Load(),
+		IndexedBlock: e.indexedBlockNum.Load(),
+		Subscribed:   e.subscribed.Load(),
+		Stopped:      e.stopped.Load(),
+		BalanceCache: e.dCache.Balance.Stats(),
+	}
+}
@@ -22,6 +22,125 @@
 
 package explorer
 
-func (e *Explorer) SyncGas() {
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"math/big"
+	"sort"
+	"time"
+)
 
+// gasTx an indexed tx with the op it is accounted to
+type gasTx struct {
+	tx *xycommon.RpcTransaction
+	md *devents.MetaData
+}
+
+type gasStat struct {
+	txCnt   uint64
+	gasUsed uint64
+	fee     *big.Int
+	prices  []*big.Int
+}
+
+func newGasStat() *gasStat {
+	return &gasStat{fee: big.NewInt(0)}
+}
+
+func (s *gasStat) add(tx *xycommon.RpcTransaction) {
+	gas, price := big.NewInt(0), big.NewInt(0)
+	if tx.Gas != nil {
+		gas = tx.Gas
+	}
+	if tx.GasPrice != nil {
+		price = tx.GasPrice
+	}
+
+	s.txCnt++
+	s.gasUsed += gas.Uint64()
+	s.fee.Add(s.fee, new(big.Int).Mul(gas, price))
+	s.prices = append(s.prices, price)
+}
+
+// medianGasPrice the mean of the two middle prices if the count is even
+func (s *gasStat) medianGasPrice() decimal.Decimal {
+	if len(s.prices) < 1 {
+		return decimal.Zero
+	}
+
+	sort.Slice(s.prices, func(i, j int) bool {
+		return s.prices[i].Cmp(s.prices[j]) < 0
+	})
+
+	mid := len(s.prices) / 2
+	if len(s.prices)%2 == 1 {
+		return decimal.NewFromBigInt(s.prices[mid], 0)
+	}
+	sum := new(big.Int).Add(s.prices[mid-1], s.prices[mid])
+	return decimal.NewFromBigInt(sum, 0).Div(decimal.NewFromInt(2))
+}
+
+// nativeDecimals decimals of the evm native coins, fees are stored in native units
+const nativeDecimals = 18
+
+// SyncGas
+/***************************************
+ * aggregate the gas spent by the indexed & rejected txs of the block,
+ * both per block & per tick op. tx gas & gas price are the
+ * receipt gas used & effective gas price, see validReceiptTxs.
+ * btc txs have no gas, the fees are not recorded
+ ***************************************/
+func (e *Explorer) SyncGas(block *xycommon.RpcBlock, txs []*gasTx) (*model.BlockGas, []*model.TickGas) {
+	if block == nil || len(txs) < 1 || e.config.Chain.ChainGroup == model.BtcChainGroup {
+		return nil, nil
+	}
+
+	total := newGasStat()
+	keys := make([]string, 0, len(txs))
+	stats := make(map[string]*gasStat, len(txs))
+	metas := make(map[string]*devents.MetaData, len(txs))
+	for _, item := range txs {
+		total.add(item.tx)
+
+		key := fmt.Sprintf("%s_%s_%s", item.md.Protocol, item.md.Tick, item.md.Operate)
+		if _, ok := stats[key]; !ok {
+			keys = append(keys, key)
+			stats[key] = newGasStat()
+			metas[key] = item.md
+		}
+		stats[key].add(item.tx)
+	}
+
+	chain := e.config.Chain.ChainName
+	blockTime := time.Unix(int64(block.Time), 0)
+	blockGas := &model.BlockGas{
+		Chain:          chain,
+		BlockHeight:    block.Number.Uint64(),
+		BlockTime:      blockTime,
+		TxCnt:          total.txCnt,
+		GasUsed:        total.gasUsed,
+		Fee:            decimal.NewFromBigInt(total.fee, -nativeDecimals),
+		MedianGasPrice: total.medianGasPrice(),
+	}
+
+	tickGas := make([]*model.TickGas, 0, len(keys))
+	for _, key := range keys {
+		md, stat := metas[key], stats[key]
+		tickGas = append(tickGas, &model.TickGas{
+			Chain:          chain,
+			Protocol:       md.Protocol,
+			Tick:           md.Tick,
+			Op:             md.Operate,
+			BlockHeight:    block.Number.Uint64(),
+			BlockTime:      blockTime,
+			TxCnt:          stat.txCnt,
+			GasUsed:        stat.gasUsed,
+			Fee:            decimal.NewFromBigInt(stat.fee, -nativeDecimals),
+			MedianGasPrice: stat.medianGasPrice(),
+		})
+	}
+	return blockGas, tickGas
 }
@@ -0,0 +1,74 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package explorer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"math/big"
+	"testing"
+)
+
+func TestSyncGas(t *testing.T) {
+	e := &Explorer{config: &config.Config{
+		Chain: config.ChainConfig{ChainName: "avalanche", ChainGroup: model.EvmChainGroup},
+	}}
+
+	newTx := func(gas, price int64) *xycommon.RpcTransaction {
+		return &xycommon.RpcTransaction{Gas: big.NewInt(gas), GasPrice: big.NewInt(price)}
+	}
+	mint := &devents.MetaData{Protocol: "asc-20", Tick: "avav", Operate: devents.OperateMint}
+	transfer := &devents.MetaData{Protocol: "asc-20", Tick: "avav", Operate: devents.OperateTransfer}
+
+	block := &xycommon.RpcBlock{Number: big.NewInt(100), Time: 1700000000}
+	blockGas, tickGas := e.SyncGas(block, []*gasTx{
+		{tx: newTx(21000, 3e9), md: mint},
+		{tx: newTx(21000, 1e9), md: mint},
+		{tx: newTx(50000, 2e9), md: transfer},
+		{tx: newTx(30000, 5e9), md: mint},
+	})
+
+	assert.Equal(t, uint64(4), blockGas.TxCnt)
+	assert.Equal(t, uint64(122000), blockGas.GasUsed)
+	assert.Equal(t, "0.000334", blockGas.Fee.String())
+	assert.Equal(t, "2500000000", blockGas.MedianGasPrice.String())
+
+	assert.Len(t, tickGas, 2)
+	assert.Equal(t, devents.OperateMint, tickGas[0].Op)
+	assert.Equal(t, uint64(3), tickGas[0].TxCnt)
+	assert.Equal(t, "0.000234", tickGas[0].Fee.String())
+	assert.Equal(t, "3000000000", tickGas[0].MedianGasPrice.String())
+
+	blockGas, tickGas = e.SyncGas(block, nil)
+	assert.Nil(t, blockGas)
+	assert.Nil(t, tickGas)
+
+	// btc fees are not recorded
+	e.config.Chain.ChainGroup = model.BtcChainGroup
+	blockGas, tickGas = e.SyncGas(block, []*gasTx{{tx: newTx(21000, 3e9), md: mint}})
+	assert.Nil(t, blockGas)
+	assert.Nil(t, tickGas)
+}
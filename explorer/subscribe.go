@@ -0,0 +1,125 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package explorer
+
+import (
+	"errors"
+	"github.com/uxuycom/indexer/client"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/xylog"
+	"time"
+)
+
+const resubscribeInterval = 5 * time.Second
+
+// subscribeNewHeads
+/***************************************
+ * push based head tracking over websocket,
+ * falls back to polling while the socket is down and retries later.
+ * blocks missed during the outage are filled by the scan loop from currentBlockNum
+ ***************************************/
+func (e *Explorer) subscribeNewHeads() {
+	for {
+		err := e.watchNewHeads()
+		e.subscribed.Store(false)
+
+		select {
+		case <-e.ctx.Done():
+			return
+		default:
+		}
+
+		xylog.Logger.Errorf("new head subscription dropped & fallback to polling, retry after %v. chain:%s err=%v", resubscribeInterval, e.config.Chain.ChainName, err)
+		select {
+		case <-time.After(resubscribeInterval):
+		case <-e.ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *Explorer) watchNewHeads() error {
+	subscriber, err := client.NewHeadSubscriber(e.config.Chain.Ws, e.config.Chain.ChainGroup)
+	if err != nil {
+		return err
+	}
+	defer subscriber.Close()
+
+	heads := make(chan *xycommon.RpcHeader, 16)
+	sub, err := subscriber.SubscribeNewHead(e.ctx, heads)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	e.subscribed.Store(true)
+	xylog.Logger.Infof("new head subscription started. chain:%s", e.config.Chain.ChainName)
+	for {
+		select {
+		case head := <-heads:
+			e.onNewHead(head)
+		case err = <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return err
+		case <-e.ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (e *Explorer) onNewHead(head *xycommon.RpcHeader) {
+	if head == nil || head.Number == nil {
+		return
+	}
+
+	switch e.config.Scan.ConfirmationMode {
+	case config.ConfirmationModeSafe, config.ConfirmationModeFinalized:
+		// the safe head moves along with new heads
+		if err := e.syncLatestBlockNumber(); err != nil {
+			xylog.Logger.Errorf("failed to obtain the current block height. chain:%s err=%s", e.config.Chain.ChainName, err)
+			return
+		}
+	default:
+		if num := head.Number.Uint64(); num > e.latestBlockNum.Load() {
+			e.latestBlockNum.Store(num)
+		}
+	}
+
+	// wake up the scan loop
+	select {
+	case e.newHeads <- struct{}{}:
+	default:
+	}
+}
+
+// waitNewHead wait for a new head notification, or a polling interval
+func (e *Explorer) waitNewHead() {
+	select {
+	case <-e.newHeads:
+	case <-time.After(time.Second):
+	case <-e.ctx.Done():
+	}
+}
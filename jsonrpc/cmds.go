@@ -156,6 +156,7 @@ type AddressTransaction struct {
 	From      string      `json:"from"`
 	To        string      `json:"to"`
 	TxHash    common.Hash `json:"tx_hash"`
+	SubIndex  uint32      `json:"sub_index"`
 	Amount    string      `json:"amount"`
 	Event     int8        `json:"event"`
 	Operate   string      `json:"operate"`
@@ -319,6 +320,7 @@ type TransactionResponse struct {
 	PositionInBlock uint64          `json:"position_in_block"` // Position in Block
 	BlockTime       time.Time       `json:"block_time"`        // block time
 	TxHash          common.Hash     `json:"tx_hash"`           // tx hash
+	SubIndex        uint32          `json:"sub_index"`         // op index in the tx
 	From            string          `json:"from"`              // from address
 	To              string          `json:"to"`                // to address
 	Op              string          `json:"op"`                // op code
@@ -332,11 +334,13 @@ type TransactionResponse struct {
 }
 
 type GetTxByHashResponse struct {
-	IsInscription    bool                 `json:"is_inscription"`
-	Transaction      *TransactionResponse `json:"transaction,omitempty"`
-	Inscriptions     *model.Inscriptions  `json:"inscriptions,omitempty"`
-	Address          *model.AddressTxs    `json:"address,omitempty"`
-	InscriptionsData *InscriptionsData    `json:"data,omitempty"`
+	IsInscription    bool                   `json:"is_inscription"`
+	Transaction      *TransactionResponse   `json:"transaction,omitempty"` // the first op
+	Operations       []*TransactionResponse `json:"operations,omitempty"`  // all ops of the tx
+	Inscriptions     *model.Inscriptions    `json:"inscriptions,omitempty"`
+	Address          *model.AddressTxs      `json:"address,omitempty"`   // the first address tx
+	Addresses        []*model.AddressTxs    `json:"addresses,omitempty"` // address txs of all ops
+	InscriptionsData *InscriptionsData      `json:"data,omitempty"`
 }
 type GetAllChainCmd struct {
 	Chains []string
@@ -350,6 +354,168 @@ type ChainBlockStatCmd struct {
 type ChainInfoCmd struct {
 	Chain string
 }
+type GetBlockGasCmd struct {
+	Chain       string
+	BlockHeight uint64
+}
+
+type GetBlockGasResponse struct {
+	Block *model.BlockGas  `json:"block"`
+	Ticks []*model.TickGas `json:"ticks"`
+}
+
+type GetTickGasCmd struct {
+	Chain    string
+	Protocol string
+	Tick     string
+	Op       *string
+}
+
+type GetTickGasResponse struct {
+	TxCnt   uint64                `json:"tx_cnt"`
+	GasUsed uint64                `json:"gas_used"`
+	Fee     decimal.Decimal       `json:"fee"`
+	Ops     []*model.TickGasTotal `json:"ops"`
+}
+
+type GetRejectedTxByHashCmd struct {
+	Chain  string
+	TxHash common.Hash
+}
+
+type GetRejectedTxsByAddressCmd struct {
+	Chain   string
+	Address string
+	Limit   int
+	Offset  int
+}
+
+type GetRejectedTxsResponse struct {
+	Transactions []*RejectedTxResponse `json:"transactions"`
+	Total        int64                 `json:"total"`
+	Limit        int                   `json:"limit"`
+	Offset       int                   `json:"offset"`
+}
+
+type RejectedTxResponse struct {
+	Chain       string      `json:"chain"`
+	Protocol    string      `json:"protocol"`
+	Tick        string      `json:"tick"`
+	Op          string      `json:"op"`
+	BlockHeight uint64      `json:"block_height"`
+	BlockTime   time.Time   `json:"block_time"`
+	TxHash      common.Hash `json:"tx_hash"`
+	Address     string      `json:"address"`
+	To          string      `json:"to"`
+	Code        int         `json:"code"`
+	Message     string      `json:"message"`
+}
+
+type GetEthscriptionCmd struct {
+	Chain string
+	Id    string
+}
+
+type GetEthscriptionsByOwnerCmd struct {
+	Chain  string
+	Owner  string
+	Limit  int
+	Offset int
+}
+
+type GetEthscriptionsResponse struct {
+	Ethscriptions []*model.Ethscription `json:"ethscriptions"`
+	Total         int64                 `json:"total"`
+	Limit         int                   `json:"limit"`
+	Offset        int                   `json:"offset"`
+}
+
+type GetEthscriptionTransfersCmd struct {
+	Chain  string
+	Id     string
+	Limit  int
+	Offset int
+}
+
+type GetEthscriptionTransfersResponse struct {
+	Transfers []*EthscriptionTransferResponse `json:"transfers"`
+	Total     int64                           `json:"total"`
+	Limit     int                             `json:"limit"`
+	Offset    int                             `json:"offset"`
+}
+
+type EthscriptionTransferResponse struct {
+	EthscriptionId string      `json:"ethscription_id"`
+	TxHash         common.Hash `json:"tx_hash"`
+	From           string      `json:"from"`
+	To             string      `json:"to"`
+	LogIndex       int64       `json:"log_index"`
+	BlockHeight    uint64      `json:"block_height"`
+	BlockTime      time.Time   `json:"block_time"`
+}
+
+type GetOpenListingsByTickCmd struct {
+	Chain    string
+	Protocol string
+	Tick     string
+	Limit    int
+	Offset   int
+}
+
+type GetOpenListingsByAddressCmd struct {
+	Chain   string
+	Address string
+	Limit   int
+	Offset  int
+}
+
+type GetListingsResponse struct {
+	Listings []*model.Listing `json:"listings"`
+	Total    int64            `json:"total"`
+	Limit    int              `json:"limit"`
+	Offset   int              `json:"offset"`
+}
+
+type GetTradesByTickCmd struct {
+	Chain    string
+	Protocol string
+	Tick     string
+	Limit    int
+	Offset   int
+}
+
+type GetTradesResponse struct {
+	Trades []*model.Trade `json:"trades"`
+	Total  int64          `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+// GetTickCandlesCmd hourly candles of the trades in [Start, End), unix seconds
+type GetTickCandlesCmd struct {
+	Chain    string
+	Protocol string
+	Tick     string
+	Start    int64
+	End      int64
+}
+
+type GetTickCandlesResponse struct {
+	Interval int64                `json:"interval"` // seconds
+	Candles  []*model.TradeCandle `json:"candles"`
+}
+
+type GetTickMarketCmd struct {
+	Chain    string
+	Protocol string
+	Tick     string
+}
+
+type GetTickMarketResponse struct {
+	Stats24h  *model.TradeStats `json:"stats_24h"`
+	LastPrice decimal.Decimal   `json:"last_price"` // unit price of the latest trade
+}
+
 type InscriptionsData struct {
 	Protocol string          `json:"p"`
 	Operate  string          `json:"op"`
@@ -392,5 +558,17 @@ func init() {
 	MustRegisterCmd("inds_chainStat", (*ChainStatCmd)(nil), flags)
 	MustRegisterCmd("inds_chainBlockStat", (*ChainBlockStatCmd)(nil), flags)
 	MustRegisterCmd("inds_chainInfo", (*ChainInfoCmd)(nil), flags)
+	MustRegisterCmd("inds_getBlockGas", (*GetBlockGasCmd)(nil), flags)
+	MustRegisterCmd("inds_getTickGas", (*GetTickGasCmd)(nil), flags)
+	MustRegisterCmd("inds_getRejectedTxByHash", (*GetRejectedTxByHashCmd)(nil), flags)
+	MustRegisterCmd("inds_getRejectedTxsByAddress", (*GetRejectedTxsByAddressCmd)(nil), flags)
+	MustRegisterCmd("inds_getEthscription", (*GetEthscriptionCmd)(nil), flags)
+	MustRegisterCmd("inds_getEthscriptionsByOwner", (*GetEthscriptionsByOwnerCmd)(nil), flags)
+	MustRegisterCmd("inds_getEthscriptionTransfers", (*GetEthscriptionTransfersCmd)(nil), flags)
+	MustRegisterCmd("inds_getOpenListingsByTick", (*GetOpenListingsByTickCmd)(nil), flags)
+	MustRegisterCmd("inds_getOpenListingsByAddress", (*GetOpenListingsByAddressCmd)(nil), flags)
+	MustRegisterCmd("inds_getTradesByTick", (*GetTradesByTickCmd)(nil), flags)
+	MustRegisterCmd("inds_getTickCandles", (*GetTickCandlesCmd)(nil), flags)
+	MustRegisterCmd("inds_getTickMarket", (*GetTickMarketCmd)(nil), flags)
 
 }
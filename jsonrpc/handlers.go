@@ -40,6 +40,18 @@ var rpcHandlersBeforeInitV2 = map[string]commandHandler{
 	"inds_chainStat":                 indsChainStat,
 	"inds_chainBlockStat":            indsChainBlockStat,
 	"inds_chainInfo":                 indsChainInfo,
+	"inds_getBlockGas":               indsGetBlockGas,
+	"inds_getTickGas":                indsGetTickGas,
+	"inds_getRejectedTxByHash":       indsGetRejectedTxByHash,
+	"inds_getRejectedTxsByAddress":   indsGetRejectedTxsByAddress,
+	"inds_getEthscription":           indsGetEthscription,
+	"inds_getEthscriptionsByOwner":   indsGetEthscriptionsByOwner,
+	"inds_getEthscriptionTransfers":  indsGetEthscriptionTransfers,
+	"inds_getOpenListingsByTick":     indsGetOpenListingsByTick,
+	"inds_getOpenListingsByAddress":  indsGetOpenListingsByAddress,
+	"inds_getTradesByTick":           indsGetTradesByTick,
+	"inds_getTickCandles":            indsGetTickCandles,
+	"inds_getTickMarket":             indsGetTickMarket,
 }
 
 func indsGetAllChains(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -227,3 +239,128 @@ func indsChainInfo(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (in
 	svr := NewService(s)
 	return svr.GetChainInfo(req.Chain)
 }
+
+func indsGetBlockGas(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	req, ok := cmd.(*GetBlockGasCmd)
+	if !ok {
+		return ErrRPCInvalidParams, errors.New("invalid params")
+	}
+	xylog.Logger.Infof("get block gas cmd params:%v", req)
+	svr := NewService(s)
+	return svr.GetBlockGas(req.Chain, req.BlockHeight)
+}
+
+func indsGetTickGas(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	req, ok := cmd.(*GetTickGasCmd)
+	if !ok {
+		return ErrRPCInvalidParams, errors.New("invalid params")
+	}
+	xylog.Logger.Infof("get tick gas cmd params:%v", req)
+
+	op := ""
+	if req.Op != nil {
+		op = *req.Op
+	}
+	svr := NewService(s)
+	return svr.GetTickGas(req.Chain, req.Protocol, req.Tick, op)
+}
+
+func indsGetRejectedTxByHash(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	req, ok := cmd.(*GetRejectedTxByHashCmd)
+	if !ok {
+		return ErrRPCInvalidParams, errors.New("invalid params")
+	}
+	xylog.Logger.Infof("get rejected tx by hash cmd params:%v", req)
+	svr := NewService(s)
+	return svr.GetRejectedTxByHash(req.Chain, req.TxHash)
+}
+
+func indsGetRejectedTxsByAddress(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	req, ok := cmd.(*GetRejectedTxsByAddressCmd)
+	if !ok {
+		return ErrRPCInvalidParams, errors.New("invalid params")
+	}
+	xylog.Logger.Infof("get rejected txs by address cmd params:%v", req)
+	svr := NewService(s)
+	return svr.GetRejectedTxsByAddress(req.Limit, req.Offset, req.Chain, req.Address)
+}
+
+func indsGetEthscription(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	req, ok := cmd.(*GetEthscriptionCmd)
+	if !ok {
+		return ErrRPCInvalidParams, errors.New("invalid params")
+	}
+	xylog.Logger.Infof("get ethscription cmd params:%v", req)
+	svr := NewService(s)
+	return svr.GetEthscription(req.Chain, req.Id)
+}
+
+func indsGetEthscriptionsByOwner(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	req, ok := cmd.(*GetEthscriptionsByOwnerCmd)
+	if !ok {
+		return ErrRPCInvalidParams, errors.New("invalid params")
+	}
+	xylog.Logger.Infof("get ethscriptions by owner cmd params:%v", req)
+	svr := NewService(s)
+	return svr.GetEthscriptionsByOwner(req.Limit, req.Offset, req.Chain, req.Owner)
+}
+
+func indsGetEthscriptionTransfers(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	req, ok := cmd.(*GetEthscriptionTransfersCmd)
+	if !ok {
+		return ErrRPCInvalidParams, errors.New("invalid params")
+	}
+	xylog.Logger.Infof("get ethscription transfers cmd params:%v", req)
+	svr := NewService(s)
+	return svr.GetEthscriptionTransfers(req.Limit, req.Offset, req.Chain, req.Id)
+}
+
+func indsGetOpenListingsByTick(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	req, ok := cmd.(*GetOpenListingsByTickCmd)
+	if !ok {
+		return ErrRPCInvalidParams, errors.New("invalid params")
+	}
+	xylog.Logger.Infof("get open listings by tick cmd params:%v", req)
+	svr := NewService(s)
+	return svr.GetOpenListingsByTick(req.Limit, req.Offset, req.Chain, req.Protocol, req.Tick)
+}
+
+func indsGetOpenListingsByAddress(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	req, ok := cmd.(*GetOpenListingsByAddressCmd)
+	if !ok {
+		return ErrRPCInvalidParams, errors.New("invalid params")
+	}
+	xylog.Logger.Infof("get open listings by address cmd params:%v", req)
+	svr := NewService(s)
+	return svr.GetOpenListingsByAddress(req.Limit, req.Offset, req.Chain, req.Address)
+}
+
+func indsGetTradesByTick(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	req, ok := cmd.(*GetTradesByTickCmd)
+	if !ok {
+		return ErrRPCInvalidParams, errors.New("invalid params")
+	}
+	xylog.Logger.Infof("get trades by tick cmd params:%v", req)
+	svr := NewService(s)
+	return svr.GetTradesByTick(req.Limit, req.Offset, req.Chain, req.Protocol, req.Tick)
+}
+
+func indsGetTickCandles(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	req, ok := cmd.(*GetTickCandlesCmd)
+	if !ok {
+		return ErrRPCInvalidParams, errors.New("invalid params")
+	}
+	xylog.Logger.Infof("get tick candles cmd params:%v", req)
+	svr := NewService(s)
+	return svr.GetTickCandles(req.Chain, req.Protocol, req.Tick, req.Start, req.End)
+}
+
+func indsGetTickMarket(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	req, ok := cmd.(*GetTickMarketCmd)
+	if !ok {
+		return ErrRPCInvalidParams, errors.New("invalid params")
+	}
+	xylog.Logger.Infof("get tick market cmd params:%v", req)
+	svr := NewService(s)
+	return svr.GetTickMarket(req.Chain, req.Protocol, req.Tick)
+}
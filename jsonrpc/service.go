@@ -467,6 +467,7 @@ func (s *Service) GetAddressTransactions(protocol string, tick string, chain str
 		trans := &AddressTransaction{
 			Event:     t.Event,
 			TxHash:    common.BytesToHash(t.TxHash),
+			SubIndex:  t.SubIndex,
 			Address:   t.Address,
 			From:      from,
 			To:        to,
@@ -500,44 +501,53 @@ func (s *Service) GetTxByHash(txHash common.Hash, chain string) (interface{}, er
 			return allIns, nil
 		}
 	}
-	tx, err := s.rpcServer.dbc.FindTransaction(chain, txHash)
+	txs, err := s.rpcServer.dbc.FindTransactions(chain, txHash)
 	if err != nil {
 		return nil, err
 	}
-	if tx == nil {
+	if len(txs) < 1 {
 		return nil, errors.New("Transaction Record not found")
 	}
+	tx := txs[0]
 	resp := &GetTxByHashResponse{}
 	inscription, err := s.rpcServer.dbc.FindInscriptionByTick(tx.Chain, tx.Protocol, tx.Tick)
 	// get amount from address tx tab
-	addressTx, err := s.rpcServer.dbc.FindAddressTxByHash(chain, txHash)
+	addressTxs, err := s.rpcServer.dbc.FindAddressTxsByHash(chain, txHash)
+	if err != nil {
+		return nil, err
+	}
 	resp.IsInscription = true
 
 	resp.Inscriptions = inscription
-	resp.Address = addressTx
-
-	if tx != nil {
-		trs := &TransactionResponse{
-			ID:              tx.ID,
-			Chain:           tx.Chain,
-			Protocol:        tx.Protocol,
-			BlockHeight:     tx.BlockHeight,
-			PositionInBlock: tx.PositionInBlock,
-			BlockTime:       tx.BlockTime,
-			TxHash:          common.BytesToHash(tx.TxHash),
-			From:            tx.From,
-			To:              tx.To,
-			Op:              tx.Op,
-			Tick:            tx.Tick,
-			Amount:          tx.Amount,
-			Gas:             tx.Gas,
-			GasPrice:        tx.GasPrice,
-			Status:          tx.Status,
-			CreatedAt:       tx.CreatedAt,
-			UpdatedAt:       tx.UpdatedAt,
-		}
-		resp.Transaction = trs
-	}
+	resp.Addresses = addressTxs
+	if len(addressTxs) > 0 {
+		resp.Address = addressTxs[0]
+	}
+
+	resp.Operations = make([]*TransactionResponse, 0, len(txs))
+	for _, item := range txs {
+		resp.Operations = append(resp.Operations, &TransactionResponse{
+			ID:              item.ID,
+			Chain:           item.Chain,
+			Protocol:        item.Protocol,
+			BlockHeight:     item.BlockHeight,
+			PositionInBlock: item.PositionInBlock,
+			BlockTime:       item.BlockTime,
+			TxHash:          common.BytesToHash(item.TxHash),
+			SubIndex:        item.SubIndex,
+			From:            item.From,
+			To:              item.To,
+			Op:              item.Op,
+			Tick:            item.Tick,
+			Amount:          item.Amount,
+			Gas:             item.Gas,
+			GasPrice:        item.GasPrice,
+			Status:          item.Status,
+			CreatedAt:       item.CreatedAt,
+			UpdatedAt:       item.UpdatedAt,
+		})
+	}
+	resp.Transaction = resp.Operations[0]
 	inscriptionsData := &InscriptionsData{
 		Protocol: tx.Protocol,
 		Operate:  tx.Op,
@@ -842,3 +852,360 @@ func (s *Service) GetChainInfo(chain string) (interface{}, error) {
 	}
 	return chainInfoExt, nil
 }
+
+func (s *Service) GetBlockGas(chain string, blockHeight uint64) (interface{}, error) {
+	cacheKey := fmt.Sprintf("block_gas_%s_%d", chain, blockHeight)
+	if ins, ok := s.rpcServer.cacheStore.Get(cacheKey); ok {
+		if resp, ok := ins.(*GetBlockGasResponse); ok {
+			return resp, nil
+		}
+	}
+
+	block, err := s.rpcServer.dbc.FindBlockGas(chain, blockHeight)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+	if block == nil {
+		return nil, errors.New("block gas record not found")
+	}
+
+	ticks, err := s.rpcServer.dbc.FindTickGasByBlock(chain, blockHeight)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	resp := &GetBlockGasResponse{
+		Block: block,
+		Ticks: ticks,
+	}
+	s.rpcServer.cacheStore.Set(cacheKey, resp)
+	return resp, nil
+}
+
+func (s *Service) GetTickGas(chain, protocol, tick, op string) (interface{}, error) {
+	protocol = strings.ToLower(protocol)
+	tick = strings.ToLower(tick)
+	cacheKey := fmt.Sprintf("tick_gas_%s_%s_%s_%s", chain, protocol, tick, op)
+	if ins, ok := s.rpcServer.cacheStore.Get(cacheKey); ok {
+		if resp, ok := ins.(*GetTickGasResponse); ok {
+			return resp, nil
+		}
+	}
+
+	ops, err := s.rpcServer.dbc.SumTickGas(chain, protocol, tick, op)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	resp := &GetTickGasResponse{
+		Fee: decimal.Zero,
+		Ops: ops,
+	}
+	for _, item := range ops {
+		resp.TxCnt += item.TxCnt
+		resp.GasUsed += item.GasUsed
+		resp.Fee = resp.Fee.Add(item.Fee)
+	}
+	s.rpcServer.cacheStore.Set(cacheKey, resp)
+	return resp, nil
+}
+
+func (s *Service) GetRejectedTxByHash(chain string, txHash common.Hash) (interface{}, error) {
+	items, err := s.rpcServer.dbc.FindRejectedTxsByHash(chain, txHash)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	resp := &GetRejectedTxsResponse{
+		Transactions: toRejectedTxResponses(items),
+		Total:        int64(len(items)),
+	}
+	return resp, nil
+}
+
+func (s *Service) GetRejectedTxsByAddress(limit, offset int, chain, address string) (interface{}, error) {
+	cacheKey := fmt.Sprintf("rejected_txs_%d_%d_%s_%s", limit, offset, chain, address)
+	if ins, ok := s.rpcServer.cacheStore.Get(cacheKey); ok {
+		if resp, ok := ins.(*GetRejectedTxsResponse); ok {
+			return resp, nil
+		}
+	}
+
+	items, total, err := s.rpcServer.dbc.GetRejectedTxsByAddress(limit, offset, chain, address)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	resp := &GetRejectedTxsResponse{
+		Transactions: toRejectedTxResponses(items),
+		Total:        total,
+		Limit:        limit,
+		Offset:       offset,
+	}
+	s.rpcServer.cacheStore.Set(cacheKey, resp)
+	return resp, nil
+}
+
+func toRejectedTxResponses(items []*model.RejectedTx) []*RejectedTxResponse {
+	list := make([]*RejectedTxResponse, 0, len(items))
+	for _, item := range items {
+		list = append(list, &RejectedTxResponse{
+			Chain:       item.Chain,
+			Protocol:    item.Protocol,
+			Tick:        item.Tick,
+			Op:          item.Op,
+			BlockHeight: item.BlockHeight,
+			BlockTime:   item.BlockTime,
+			TxHash:      common.BytesToHash(item.TxHash),
+			Address:     item.Address,
+			To:          item.To,
+			Code:        item.Code,
+			Message:     item.Message,
+		})
+	}
+	return list
+}
+
+func (s *Service) GetEthscription(chain, id string) (interface{}, error) {
+	item, err := s.rpcServer.dbc.FindEthscriptionById(chain, strings.ToLower(id))
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+	if item == nil {
+		return ErrRPCRecordNotFound, err
+	}
+	return item, nil
+}
+
+func (s *Service) GetEthscriptionsByOwner(limit, offset int, chain, owner string) (interface{}, error) {
+	owner = strings.ToLower(owner)
+	cacheKey := fmt.Sprintf("ethscriptions_%d_%d_%s_%s", limit, offset, chain, owner)
+	if ins, ok := s.rpcServer.cacheStore.Get(cacheKey); ok {
+		if resp, ok := ins.(*GetEthscriptionsResponse); ok {
+			return resp, nil
+		}
+	}
+
+	items, total, err := s.rpcServer.dbc.GetEthscriptionsByOwner(limit, offset, chain, owner)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	resp := &GetEthscriptionsResponse{
+		Ethscriptions: items,
+		Total:         total,
+		Limit:         limit,
+		Offset:        offset,
+	}
+	s.rpcServer.cacheStore.Set(cacheKey, resp)
+	return resp, nil
+}
+
+func (s *Service) GetEthscriptionTransfers(limit, offset int, chain, id string) (interface{}, error) {
+	id = strings.ToLower(id)
+	cacheKey := fmt.Sprintf("ethscription_transfers_%d_%d_%s_%s", limit, offset, chain, id)
+	if ins, ok := s.rpcServer.cacheStore.Get(cacheKey); ok {
+		if resp, ok := ins.(*GetEthscriptionTransfersResponse); ok {
+			return resp, nil
+		}
+	}
+
+	items, total, err := s.rpcServer.dbc.GetEthscriptionTransfers(limit, offset, chain, id)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	resp := &GetEthscriptionTransfersResponse{
+		Transfers: make([]*EthscriptionTransferResponse, 0, len(items)),
+		Total:     total,
+		Limit:     limit,
+		Offset:    offset,
+	}
+	for _, item := range items {
+		resp.Transfers = append(resp.Transfers, &EthscriptionTransferResponse{
+			EthscriptionId: item.EthscriptionId,
+			TxHash:         common.BytesToHash(item.TxHash),
+			From:           item.From,
+			To:             item.To,
+			LogIndex:       item.LogIndex,
+			BlockHeight:    item.BlockHeight,
+			BlockTime:      item.BlockTime,
+		})
+	}
+	s.rpcServer.cacheStore.Set(cacheKey, resp)
+	return resp, nil
+}
+
+func (s *Service) GetOpenListingsByTick(limit, offset int, chain, protocol, tick string) (interface{}, error) {
+	protocol = strings.ToLower(protocol)
+	tick = strings.ToLower(tick)
+	cacheKey := fmt.Sprintf("open_listings_tick_%d_%d_%s_%s_%s", limit, offset, chain, protocol, tick)
+	if ins, ok := s.rpcServer.cacheStore.Get(cacheKey); ok {
+		if resp, ok := ins.(*GetListingsResponse); ok {
+			return resp, nil
+		}
+	}
+
+	items, total, err := s.rpcServer.dbc.GetOpenListingsByTick(limit, offset, chain, protocol, tick)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	resp := &GetListingsResponse{
+		Listings: items,
+		Total:    total,
+		Limit:    limit,
+		Offset:   offset,
+	}
+	s.rpcServer.cacheStore.Set(cacheKey, resp)
+	return resp, nil
+}
+
+func (s *Service) GetOpenListingsByAddress(limit, offset int, chain, address string) (interface{}, error) {
+	address = strings.ToLower(address)
+	cacheKey := fmt.Sprintf("open_listings_address_%d_%d_%s_%s", limit, offset, chain, address)
+	if ins, ok := s.rpcServer.cacheStore.Get(cacheKey); ok {
+		if resp, ok := ins.(*GetListingsResponse); ok {
+			return resp, nil
+		}
+	}
+
+	items, total, err := s.rpcServer.dbc.GetOpenListingsBySeller(limit, offset, chain, address)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	resp := &GetListingsResponse{
+		Listings: items,
+		Total:    total,
+		Limit:    limit,
+		Offset:   offset,
+	}
+	s.rpcServer.cacheStore.Set(cacheKey, resp)
+	return resp, nil
+}
+
+func (s *Service) GetTradesByTick(limit, offset int, chain, protocol, tick string) (interface{}, error) {
+	protocol = strings.ToLower(protocol)
+	tick = strings.ToLower(tick)
+	cacheKey := fmt.Sprintf("trades_tick_%d_%d_%s_%s_%s", limit, offset, chain, protocol, tick)
+	if ins, ok := s.rpcServer.cacheStore.Get(cacheKey); ok {
+		if resp, ok := ins.(*GetTradesResponse); ok {
+			return resp, nil
+		}
+	}
+
+	items, total, err := s.rpcServer.dbc.GetTradesByTick(limit, offset, chain, protocol, tick)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	resp := &GetTradesResponse{
+		Trades: items,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	s.rpcServer.cacheStore.Set(cacheKey, resp)
+	return resp, nil
+}
+
+const (
+	candleInterval = time.Hour
+
+	// maxCandles limit the candles of a query, 30 days of hourly candles
+	maxCandles = 30 * 24
+)
+
+func (s *Service) GetTickCandles(chain, protocol, tick string, start, end int64) (interface{}, error) {
+	protocol = strings.ToLower(protocol)
+	tick = strings.ToLower(tick)
+
+	interval := int64(candleInterval / time.Second)
+	start = start - start%interval
+	if end <= start || (end-start)/interval > maxCandles {
+		return ErrRPCInvalidParams, fmt.Errorf("invalid candle range[%d, %d), max %d candles", start, end, maxCandles)
+	}
+
+	cacheKey := fmt.Sprintf("tick_candles_%s_%s_%s_%d_%d", chain, protocol, tick, start, end)
+	if ins, ok := s.rpcServer.cacheStore.Get(cacheKey); ok {
+		if resp, ok := ins.(*GetTickCandlesResponse); ok {
+			return resp, nil
+		}
+	}
+
+	trades, err := s.rpcServer.dbc.FindTradesByTime(chain, protocol, tick, time.Unix(start, 0), time.Unix(end, 0))
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	resp := &GetTickCandlesResponse{
+		Interval: interval,
+		Candles:  buildCandles(trades, candleInterval),
+	}
+	s.rpcServer.cacheStore.Set(cacheKey, resp)
+	return resp, nil
+}
+
+// buildCandles aggregate the ordered trades by interval, intervals without trades are skipped
+func buildCandles(trades []*model.Trade, interval time.Duration) []*model.TradeCandle {
+	candles := make([]*model.TradeCandle, 0)
+	var last *model.TradeCandle
+	for _, trade := range trades {
+		ts := trade.BlockTime.Truncate(interval)
+		if last == nil || !last.Time.Equal(ts) {
+			last = &model.TradeCandle{
+				Time:   ts,
+				Open:   trade.UnitPrice,
+				High:   trade.UnitPrice,
+				Low:    trade.UnitPrice,
+				Volume: decimal.Zero,
+				Amount: decimal.Zero,
+			}
+			candles = append(candles, last)
+		}
+
+		if trade.UnitPrice.GreaterThan(last.High) {
+			last.High = trade.UnitPrice
+		}
+		if trade.UnitPrice.LessThan(last.Low) {
+			last.Low = trade.UnitPrice
+		}
+		last.Close = trade.UnitPrice
+		last.Volume = last.Volume.Add(trade.Price)
+		last.Amount = last.Amount.Add(trade.Amount)
+		last.Trades++
+	}
+	return candles
+}
+
+func (s *Service) GetTickMarket(chain, protocol, tick string) (interface{}, error) {
+	protocol = strings.ToLower(protocol)
+	tick = strings.ToLower(tick)
+	cacheKey := fmt.Sprintf("tick_market_%s_%s_%s", chain, protocol, tick)
+	if ins, ok := s.rpcServer.cacheStore.Get(cacheKey); ok {
+		if resp, ok := ins.(*GetTickMarketResponse); ok {
+			return resp, nil
+		}
+	}
+
+	stats, err := s.rpcServer.dbc.SumTradesSince(chain, protocol, tick, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	last, err := s.rpcServer.dbc.FindLastTrade(chain, protocol, tick)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	resp := &GetTickMarketResponse{
+		Stats24h:  stats,
+		LastPrice: decimal.Zero,
+	}
+	if last != nil {
+		resp.LastPrice = last.UnitPrice
+	}
+	s.rpcServer.cacheStore.Set(cacheKey, resp)
+	return resp, nil
+}
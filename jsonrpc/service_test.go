@@ -24,10 +24,51 @@ package jsonrpc
 
 import (
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/model"
 	"testing"
+	"time"
 )
 
 func Test_TxHash(t *testing.T) {
 	hash := []byte("0x7ffc56b2bf20f4f3474c1fd503fc3f1fb9066c8b0665d6da11185cac892108a5")
 	t.Logf("tx_hash=%v", common.Bytes2Hex(hash))
 }
+
+func Test_buildCandles(t *testing.T) {
+	hour := time.Unix(1700000000, 0).Truncate(time.Hour)
+	trade := func(offset time.Duration, amount, price int64) *model.Trade {
+		return &model.Trade{
+			Amount:    decimal.NewFromInt(amount),
+			Price:     decimal.NewFromInt(price),
+			UnitPrice: decimal.NewFromInt(price / amount),
+			BlockTime: hour.Add(offset),
+		}
+	}
+
+	candles := buildCandles([]*model.Trade{
+		trade(time.Minute, 10, 50),
+		trade(10*time.Minute, 10, 80),
+		trade(20*time.Minute, 10, 30),
+		trade(59*time.Minute, 10, 60),
+		trade(3*time.Hour, 5, 35),
+	}, time.Hour)
+
+	assert.Equal(t, 2, len(candles))
+	assert.True(t, candles[0].Time.Equal(hour))
+	assert.Equal(t, "5", candles[0].Open.String())
+	assert.Equal(t, "8", candles[0].High.String())
+	assert.Equal(t, "3", candles[0].Low.String())
+	assert.Equal(t, "6", candles[0].Close.String())
+	assert.Equal(t, "220", candles[0].Volume.String())
+	assert.Equal(t, "40", candles[0].Amount.String())
+	assert.Equal(t, uint64(4), candles[0].Trades)
+
+	// hours without trades are skipped
+	assert.True(t, candles[1].Time.Equal(hour.Add(3*time.Hour)))
+	assert.Equal(t, "7", candles[1].Open.String())
+	assert.Equal(t, uint64(1), candles[1].Trades)
+
+	assert.Equal(t, 0, len(buildCandles(nil, time.Hour)))
+}
@@ -59,6 +59,9 @@ type UTXO struct {
 	Tick      string          `json:"tick" gorm:"column:tick"`
 	Amount    decimal.Decimal `json:"amount" gorm:"column:amount;type:decimal(38,18)"` // amount
 	RootHash  string          `json:"root_hash" gorm:"column:root_hash"`
+	Spender   string          `json:"spender" gorm:"column:spender"`       // address allowed to spend besides the owner, e.g. ierc-20 freeze platform
+	Vout      uint32          `json:"vout" gorm:"column:vout"`             // output of the root tx holding the inscribed sat, btc only
+	SatOffset uint64          `json:"sat_offset" gorm:"column:sat_offset"` // offset of the inscribed sat in the output, btc only
 	TxHash    string          `json:"tx_hash" gorm:"column:tx_hash"`
 	Status    int8            `json:"status" gorm:"column:status"` // tx status
 	CreatedAt time.Time       `json:"created_at" gorm:"column:created_at"`
@@ -0,0 +1,78 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package model
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// BlockGas gas spent by the inscription txs of a block
+type BlockGas struct {
+	ID             uint64          `gorm:"primaryKey" json:"id"`
+	Chain          string          `json:"chain" gorm:"column:chain"`
+	BlockHeight    uint64          `json:"block_height" gorm:"column:block_height"`
+	BlockTime      time.Time       `json:"block_time" gorm:"column:block_time"`
+	TxCnt          uint64          `json:"tx_cnt" gorm:"column:tx_cnt"`
+	GasUsed        uint64          `json:"gas_used" gorm:"column:gas_used"`
+	Fee            decimal.Decimal `json:"fee" gorm:"column:fee;type:decimal(38,18)"`                           // effective fee in native units
+	MedianGasPrice decimal.Decimal `json:"median_gas_price" gorm:"column:median_gas_price;type:decimal(38,18)"` // in the smallest native unit
+	CreatedAt      time.Time       `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (BlockGas) TableName() string {
+	return "block_gas"
+}
+
+// TickGas gas spent by the txs of a tick & op in a block
+type TickGas struct {
+	ID             uint64          `gorm:"primaryKey" json:"id"`
+	Chain          string          `json:"chain" gorm:"column:chain"`
+	Protocol       string          `json:"protocol" gorm:"column:protocol"`
+	Tick           string          `json:"tick" gorm:"column:tick"`
+	Op             string          `json:"op" gorm:"column:op"`
+	BlockHeight    uint64          `json:"block_height" gorm:"column:block_height"`
+	BlockTime      time.Time       `json:"block_time" gorm:"column:block_time"`
+	TxCnt          uint64          `json:"tx_cnt" gorm:"column:tx_cnt"`
+	GasUsed        uint64          `json:"gas_used" gorm:"column:gas_used"`
+	Fee            decimal.Decimal `json:"fee" gorm:"column:fee;type:decimal(38,18)"`
+	MedianGasPrice decimal.Decimal `json:"median_gas_price" gorm:"column:median_gas_price;type:decimal(38,18)"`
+	CreatedAt      time.Time       `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (TickGas) TableName() string {
+	return "tick_gas"
+}
+
+// TickGasTotal gas spent by a tick over all indexed blocks
+type TickGasTotal struct {
+	Chain    string          `json:"chain" gorm:"column:chain"`
+	Protocol string          `json:"protocol" gorm:"column:protocol"`
+	Tick     string          `json:"tick" gorm:"column:tick"`
+	Op       string          `json:"op" gorm:"column:op"`
+	TxCnt    uint64          `json:"tx_cnt" gorm:"column:tx_cnt"`
+	GasUsed  uint64          `json:"gas_used" gorm:"column:gas_used"`
+	Fee      decimal.Decimal `json:"fee" gorm:"column:fee"`
+}
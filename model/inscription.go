@@ -49,6 +49,7 @@ type Inscriptions struct {
 	CreatedAt    time.Time       `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt    time.Time       `json:"updated_at" gorm:"column:updated_at"`
 	Decimals     int8            `json:"decimals" gorm:"column:decimals"`
+	Workc        string          `json:"workc" gorm:"column:workc"` // ierc-20 pow mint tx hash prefix
 }
 
 func (Inscriptions) TableName() string {
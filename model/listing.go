@@ -0,0 +1,62 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package model
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type ListingStatus int8
+
+const (
+	ListingStatusOpen      ListingStatus = 1
+	ListingStatusCancelled ListingStatus = 2
+	ListingStatusFilled    ListingStatus = 3
+)
+
+// Listing a marketplace sell order, the list id is the hash of the list tx.
+// prices are in the chain native token wei, known once the order is filled or cancelled
+type Listing struct {
+	ID               uint64          `gorm:"primaryKey" json:"id"`
+	Chain            string          `json:"chain" gorm:"column:chain"`
+	Protocol         string          `json:"protocol" gorm:"column:protocol"`
+	Tick             string          `json:"tick" gorm:"column:tick"`
+	ListId           string          `json:"list_id" gorm:"column:list_id"`
+	Seller           string          `json:"seller" gorm:"column:seller"`
+	Market           string          `json:"market" gorm:"column:market"` // the marketplace contract holding the listed amount
+	Amount           decimal.Decimal `json:"amount" gorm:"column:amount;type:decimal(38,18)"`
+	Price            decimal.Decimal `json:"price" gorm:"column:price;type:decimal(65,18)"`           // total price
+	UnitPrice        decimal.Decimal `json:"unit_price" gorm:"column:unit_price;type:decimal(65,18)"` // price per unit of the amount
+	Status           ListingStatus   `json:"status" gorm:"column:status"`
+	Buyer            string          `json:"buyer" gorm:"column:buyer"`
+	BlockHeight      uint64          `json:"block_height" gorm:"column:block_height"` // list block height
+	CloseTxHash      string          `json:"close_tx_hash" gorm:"column:close_tx_hash"`
+	CloseBlockHeight uint64          `json:"close_block_height" gorm:"column:close_block_height"`
+	CreatedAt        time.Time       `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt        time.Time       `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (Listing) TableName() string {
+	return "listings"
+}
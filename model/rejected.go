@@ -0,0 +1,48 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package model
+
+import "time"
+
+// RejectedTx a candidate inscription tx rejected by the protocol rules
+type RejectedTx struct {
+	ID          uint64    `gorm:"primaryKey" json:"id"`
+	ChainId     int64     `json:"chain_id" gorm:"-:all"`
+	Chain       string    `json:"chain" gorm:"column:chain"`
+	Protocol    string    `json:"protocol" gorm:"column:protocol"`
+	Tick        string    `json:"tick" gorm:"column:tick"`
+	Op          string    `json:"op" gorm:"column:op"`
+	BlockHeight uint64    `json:"block_height" gorm:"column:block_height"`
+	BlockTime   time.Time `json:"block_time" gorm:"column:block_time"`
+	TxHash      []byte    `json:"tx_hash" gorm:"column:tx_hash"`
+	Address     string    `json:"address" gorm:"column:address"` // tx sender
+	To          string    `json:"to" gorm:"column:to"`
+	Code        int       `json:"code" gorm:"column:code"` // xyerrors.InsError code
+	Message     string    `json:"message" gorm:"column:message"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (RejectedTx) TableName() string {
+	return "rejected_txs"
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package model
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Trade a marketplace listing filled, prices are in the chain native token wei
+type Trade struct {
+	ID          uint64          `gorm:"primaryKey" json:"id"`
+	Chain       string          `json:"chain" gorm:"column:chain"`
+	Protocol    string          `json:"protocol" gorm:"column:protocol"`
+	Tick        string          `json:"tick" gorm:"column:tick"`
+	ListId      string          `json:"list_id" gorm:"column:list_id"`
+	TxHash      string          `json:"tx_hash" gorm:"column:tx_hash"`
+	Seller      string          `json:"seller" gorm:"column:seller"`
+	Buyer       string          `json:"buyer" gorm:"column:buyer"`
+	Amount      decimal.Decimal `json:"amount" gorm:"column:amount;type:decimal(38,18)"`
+	Price       decimal.Decimal `json:"price" gorm:"column:price;type:decimal(65,18)"`           // total price
+	UnitPrice   decimal.Decimal `json:"unit_price" gorm:"column:unit_price;type:decimal(65,18)"` // price per unit of the amount
+	BlockHeight uint64          `json:"block_height" gorm:"column:block_height"`
+	BlockTime   time.Time       `json:"block_time" gorm:"column:block_time"`
+	CreatedAt   time.Time       `json:"created_at" gorm:"column:created_at"`
+}
+
+func (Trade) TableName() string {
+	return "trades"
+}
+
+// TradeCandle OHLC unit prices of the trades in [Time, Time + interval)
+type TradeCandle struct {
+	Time   time.Time       `json:"time"`
+	Open   decimal.Decimal `json:"open"`
+	High   decimal.Decimal `json:"high"`
+	Low    decimal.Decimal `json:"low"`
+	Close  decimal.Decimal `json:"close"`
+	Volume decimal.Decimal `json:"volume"` // sum of the trade prices
+	Amount decimal.Decimal `json:"amount"` // sum of the traded amounts
+	Trades uint64          `json:"trades"`
+}
+
+// TradeStats trades of a tick over a period
+type TradeStats struct {
+	Volume decimal.Decimal `json:"volume" gorm:"column:volume"`
+	Amount decimal.Decimal `json:"amount" gorm:"column:amount"`
+	Trades uint64          `json:"trades" gorm:"column:trades"`
+	Low24h decimal.Decimal `json:"low_24h" gorm:"column:low_24h"` // the lowest unit price traded, not the listings floor
+}
@@ -70,6 +70,7 @@ type AddressTxs struct {
 	ID             uint64          `gorm:"primaryKey" json:"id"`
 	Event          TxEvent         `json:"event" gorm:"column:event"`
 	TxHash         []byte          `json:"tx_hash" gorm:"column:tx_hash"`
+	SubIndex       uint32          `json:"sub_index" gorm:"column:sub_index"`
 	Address        string          `json:"address" gorm:"column:address"`
 	RelatedAddress string          `json:"related_address" gorm:"column:related_address"`
 	Amount         decimal.Decimal `json:"amount" gorm:"column:amount;type:decimal(38,18)"`
@@ -96,6 +97,7 @@ type BalanceTxn struct {
 	Available decimal.Decimal `json:"available" gorm:"column:available;type:decimal(38,18)"`
 	Balance   decimal.Decimal `json:"balance" gorm:"column:balance;type:decimal(38,18)"`
 	TxHash    []byte          `json:"tx_hash" gorm:"column:tx_hash"`
+	SubIndex  uint32          `json:"sub_index" gorm:"column:sub_index"`
 	CreatedAt time.Time       `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt time.Time       `json:"updated_at" gorm:"column:updated_at"`
 }
@@ -113,6 +115,7 @@ type Transaction struct {
 	PositionInBlock uint64          `json:"position_in_block" gorm:"column:position_in_block"` // Position in Block
 	BlockTime       time.Time       `json:"block_time" gorm:"column:block_time"`               // block time
 	TxHash          []byte          `json:"tx_hash" gorm:"column:tx_hash"`                     // tx hash
+	SubIndex        uint32          `json:"sub_index" gorm:"column:sub_index"`                 // op index in the tx
 	From            string          `json:"from" gorm:"column:from"`                           // from address
 	To              string          `json:"to" gorm:"column:to"`                               // to address
 	Op              string          `json:"op" gorm:"column:op"`                               // op code
@@ -133,6 +136,7 @@ type AddressTransaction struct {
 	ID        uint64          `gorm:"primaryKey" json:"id"`
 	Event     int8            `json:"event" gorm:"column:event"`
 	TxHash    []byte          `json:"tx_hash" gorm:"column:tx_hash"`
+	SubIndex  uint32          `json:"sub_index" gorm:"column:sub_index"`
 	Address   string          `json:"address" gorm:"column:address"`
 	From      string          `json:"from" gorm:"column:from"`
 	To        string          `json:"to" gorm:"column:to"`
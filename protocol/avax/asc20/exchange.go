@@ -29,6 +29,7 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
 	"github.com/uxuycom/indexer/utils"
 	"github.com/uxuycom/indexer/xyerrors"
 	"github.com/uxuycom/indexer/xylog"
@@ -51,6 +52,11 @@ type Exchange struct {
 	From    string
 	To      string
 	Amount  decimal.Decimal
+
+	// the executed / cancelled listing, empty for TransferASC20Token events
+	ListId string
+	Seller string
+	Price  decimal.Decimal
 }
 
 // ASC20Order is an auto generated low-level Go binding around an user-defined struct.
@@ -94,12 +100,34 @@ func (p *Protocol) Exchange(block *xycommon.RpcBlock, tx *xycommon.RpcTransactio
 					},
 				},
 			},
+			Listing: closeListing(exchange),
 		}
 		items = append(items, item)
 	}
 	return
 }
 
+// closeListing the listing filled by exchange or cancelled by delist
+func closeListing(e *Exchange) *devents.Listing {
+	if e.ListId == "" {
+		return nil
+	}
+
+	listing := &devents.Listing{
+		ListId: e.ListId,
+		Seller: e.Seller,
+		Amount: e.Amount,
+		Price:  e.Price,
+		Status: model.ListingStatusFilled,
+		Buyer:  strings.ToLower(e.To),
+	}
+	if e.Operate == devents.OperateDelist {
+		listing.Status = model.ListingStatusCancelled
+		listing.Buyer = ""
+	}
+	return listing
+}
+
 func (p *Protocol) parseOrderByExchange(e xycommon.RpcLog, orders map[string]*ASC20Order) (*Exchange, *xyerrors.InsError) {
 	order, ok := orders[e.Data.String()]
 	if !ok {
@@ -110,12 +138,20 @@ func (p *Protocol) parseOrderByExchange(e xycommon.RpcLog, orders map[string]*AS
 		return nil, xyerrors.NewInsError(-17, fmt.Sprintf("order amount value empty, ticker[%v]", order.Ticker))
 	}
 
+	price := decimal.Zero
+	if order.Price != nil {
+		price = decimal.NewFromBigInt(order.Price, 0)
+	}
+
 	return &Exchange{
 		Operate: order.Operate,
 		Tick:    order.Ticker,
 		From:    e.Address.String(),
 		To:      common.BytesToAddress(e.Topics[2].Bytes()).String(),
 		Amount:  decimal.NewFromBigInt(order.Amount, 0),
+		ListId:  e.Data.String(),
+		Seller:  strings.ToLower(order.Seller.String()),
+		Price:   price,
 	}, nil
 }
 
@@ -31,6 +31,7 @@ import (
 	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/dcache"
 	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
 	"github.com/uxuycom/indexer/xylog"
 	"math/big"
 	"testing"
@@ -222,6 +223,8 @@ func TestExtractValidOrdersByExchange(t *testing.T) {
 					To:      "0xEB23C2eD8eBa5BF14eD56db47613177C6eCBbCF9",
 					Amount:  decimal.NewFromInt(15000000000),
 					Operate: devents.OperateExchange,
+					ListId:  "0xdd6d2d461eb654a4c46d792d28562a610c121d8e4f016aa17a77fde77d2bf985",
+					Seller:  "0x47b83879dce8d84ee4bb6d6df092ed00834ab981",
 				},
 			},
 		},
@@ -248,6 +251,8 @@ func TestExtractValidOrdersByExchange(t *testing.T) {
 					To:      "0xa6DC0352F4929c471247a872446B63a82dc14Ff8",
 					Amount:  decimal.NewFromInt(94090908150),
 					Operate: devents.OperateDelist,
+					ListId:  "0x50cf0e5438354c45bcaf1689916a6ae39a2198059045bb79275c718d4fce7a5d",
+					Seller:  "0xa6dc0352f4929c471247a872446b63a82dc14ff8",
 				},
 			},
 		},
@@ -299,12 +304,40 @@ func TestExtractValidOrdersByExchange(t *testing.T) {
 					assert.Equal(t, item.To, test.Expected[idx].To)
 					assert.Equal(t, item.Amount, test.Expected[idx].Amount)
 					assert.Equal(t, item.Operate, test.Expected[idx].Operate)
+					assert.Equal(t, item.ListId, test.Expected[idx].ListId)
+					assert.Equal(t, item.Seller, test.Expected[idx].Seller)
 				}
 			}
 		})
 	}
 }
 
+func TestCloseListing(t *testing.T) {
+	filled := closeListing(&Exchange{
+		Operate: devents.OperateExchange,
+		To:      "0xEB23C2eD8eBa5BF14eD56db47613177C6eCBbCF9",
+		Amount:  decimal.NewFromInt(100),
+		ListId:  "0xdd6d2d461eb654a4c46d792d28562a610c121d8e4f016aa17a77fde77d2bf985",
+		Seller:  "0x47b83879dce8d84ee4bb6d6df092ed00834ab981",
+		Price:   decimal.NewFromInt(250),
+	})
+	assert.Equal(t, model.ListingStatusFilled, filled.Status)
+	assert.Equal(t, "0xeb23c2ed8eba5bf14ed56db47613177c6ecbbcf9", filled.Buyer)
+	assert.True(t, filled.Price.Equal(decimal.NewFromInt(250)))
+
+	cancelled := closeListing(&Exchange{
+		Operate: devents.OperateDelist,
+		To:      "0x47b83879dce8d84ee4bb6d6df092ed00834ab981",
+		Amount:  decimal.NewFromInt(100),
+		ListId:  "0xdd6d2d461eb654a4c46d792d28562a610c121d8e4f016aa17a77fde77d2bf985",
+	})
+	assert.Equal(t, model.ListingStatusCancelled, cancelled.Status)
+	assert.Equal(t, "", cancelled.Buyer)
+
+	// TransferASC20Token events close no listing
+	assert.Nil(t, closeListing(&Exchange{Operate: devents.OperateExchange, Amount: decimal.NewFromInt(100)}))
+}
+
 func TestExtractValidOrdersByTransfer(t *testing.T) {
 	tests := []struct {
 		Name     string
@@ -27,6 +27,7 @@ import (
 	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/dcache"
 	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
 	"github.com/uxuycom/indexer/protocol/common"
 	"github.com/uxuycom/indexer/protocol/types"
 	"github.com/uxuycom/indexer/xyerrors"
@@ -62,6 +63,16 @@ func (p *Protocol) Parse(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction,
 	return p.common.Parse(block, tx, md)
 }
 
+// ParseMetaData marketplace exchange events of avalanche
+func (p *Protocol) ParseMetaData(chain string, tx *xycommon.RpcTransaction) *devents.MetaData {
+	if chain != model.ChainAVAX || len(tx.Events) < 1 {
+		return nil
+	}
+
+	md, _ := ParseMetaDataByEventLogs(chain, tx)
+	return md
+}
+
 func ParseMetaDataByEventLogs(chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
 	for _, event := range tx.Events {
 		if len(event.Topics) < 1 {
@@ -81,6 +92,14 @@ func ParseMetaDataByEventLogs(chain string, tx *xycommon.RpcTransaction) (*deven
 }
 
 func init() {
+	types.Register(&types.Registration{
+		Name:        types.ASC20Protocol,
+		ChainGroups: []model.ChainGroup{model.EvmChainGroup},
+		New: func(cache *dcache.Manager) types.IProtocol {
+			return NewProtocol(cache)
+		},
+	})
+
 	var err error
 	ParsedABI, err = abi.JSON(strings.NewReader(abiJSON))
 	if err != nil {
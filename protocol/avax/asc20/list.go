@@ -28,7 +28,10 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/types"
 	"github.com/uxuycom/indexer/xyerrors"
+	"strings"
 )
 
 type List struct {
@@ -36,7 +39,8 @@ type List struct {
 }
 
 func (p *Protocol) List(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
-	list, err := p.verifyList(tx, md)
+	rules := types.RulesAt(md.Protocol, md.Chain, block.Number.Uint64())
+	list, err := p.verifyList(rules, tx, md)
 	if err != nil {
 		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
 	}
@@ -53,11 +57,18 @@ func (p *Protocol) List(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, m
 				},
 			},
 		},
+		Listing: &devents.Listing{
+			ListId: tx.Hash,
+			Seller: strings.ToLower(tx.From),
+			Market: strings.ToLower(tx.To),
+			Amount: list.Amount,
+			Status: model.ListingStatusOpen,
+		},
 	}
 	return []*devents.TxResult{result}, nil
 }
 
-func (p *Protocol) verifyList(tx *xycommon.RpcTransaction, md *devents.MetaData) (*List, *xyerrors.InsError) {
+func (p *Protocol) verifyList(rules *types.Rules, tx *xycommon.RpcTransaction, md *devents.MetaData) (*List, *xyerrors.InsError) {
 	tf := &List{}
 	err := json.Unmarshal([]byte(md.Data), tf)
 	if err != nil {
@@ -77,6 +88,13 @@ func (p *Protocol) verifyList(tx *xycommon.RpcTransaction, md *devents.MetaData)
 		return nil, xyerrors.NewInsError(-15, fmt.Sprintf("inscription not exist, protocol[%s]-tick[%s]", protocol, tick))
 	}
 
+	// list amount precision checking
+	amount, insErr := p.common.CheckAmount(rules, tf.Amount, inscription.Decimals)
+	if insErr != nil {
+		return nil, insErr
+	}
+	tf.Amount = amount
+
 	// sender balance checking
 	ok, balance := p.cache.Balance.Get(protocol, tick, tx.From)
 	if !ok {
@@ -23,16 +23,104 @@
 package brc20
 
 import (
+	"errors"
+	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
 	"github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xyerrors"
+	"github.com/uxuycom/indexer/xylog"
 )
 
+// Protocol
+/*****************************************************
+ * brc-20 two-step transfer:
+ * 1. inscribe-transfer locks the owner's available balance into a transferable inscription utxo
+ * 2. the overall balance is moved once the utxo spent
+ ****************************************************/
 type Protocol struct {
 	*common.Protocol
+	cache *dcache.Manager
 }
 
 func NewProtocol(cache *dcache.Manager) *Protocol {
 	return &Protocol{
 		Protocol: common.NewProtocol(cache),
+		cache:    cache,
 	}
 }
+
+func (p *Protocol) Parse(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	// transferable inscriptions spent by the tx are settled first
+	results := p.Settle(block, tx, md)
+
+	// no brc-20 inscription revealed in the tx
+	if md.Data == "" {
+		return results, nil
+	}
+
+	var (
+		items []*devents.TxResult
+		err   *xyerrors.InsError
+	)
+	switch md.Operate {
+	case devents.OperateDeploy:
+		items, err = p.Deploy(block, tx, md)
+	case devents.OperateTransfer:
+		items, err = p.InscribeTransfer(block, tx, md)
+	default:
+		items, err = p.Protocol.Parse(block, tx, md)
+	}
+
+	if err != nil {
+		if len(results) < 1 || errors.Is(err, xyerrors.ErrInternal) {
+			return nil, err
+		}
+		xylog.Logger.Infof("inscription parsed failed & keep settled transfers. tx[%s], err[%v]", tx.Hash, err)
+		return results, nil
+	}
+	return append(results, items...), nil
+}
+
+func (p *Protocol) Deploy(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	results, err := p.Protocol.Deploy(block, tx, md)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		result.Deploy.TransferType = model.TransferTypeHash
+	}
+	return results, nil
+}
+
+// ParseMetaData
+/***************************************
+ * metadata of tx spending transferable inscriptions without revealing a brc-20 inscription,
+ * returns nil if none spent
+ ***************************************/
+func (p *Protocol) ParseMetaData(chain string, tx *xycommon.RpcTransaction) *devents.MetaData {
+	for _, in := range tx.Vin {
+		if ok, item := p.spentUTXO(in.Txid, in.Vout); ok {
+			return &devents.MetaData{
+				Chain:    chain,
+				Protocol: types.BRC20Protocol,
+				Operate:  devents.OperateTransfer,
+				Tick:     item.Tick,
+			}
+		}
+	}
+	return nil
+}
+
+func init() {
+	types.Register(&types.Registration{
+		Name:        types.BRC20Protocol,
+		ChainGroups: []model.ChainGroup{model.BtcChainGroup},
+		New: func(cache *dcache.Manager) types.IProtocol {
+			return NewProtocol(cache)
+		},
+	})
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package brc20
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/btc/ord"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xyerrors"
+	"github.com/uxuycom/indexer/xylog"
+	"math"
+)
+
+type Transfer struct {
+	Amount decimal.Decimal `json:"amt"`
+}
+
+// InscribeTransfer
+/***************************************
+ * lock the available balance of the inscription owner,
+ * the inscription is revealed to the first sat of the input carrying the envelope,
+ * the owner is the address of the output holding that sat
+ ***************************************/
+func (p *Protocol) InscribeTransfer(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	rules := types.RulesAt(md.Protocol, md.Chain, block.Number.Uint64())
+	sat, err := revealedSat(tx)
+	if err != nil {
+		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
+	}
+
+	tf, err := p.verifyInscribeTransfer(rules, sat.owner, md)
+	if err != nil {
+		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
+	}
+	result := &devents.TxResult{
+		MD:    md,
+		Block: block,
+		Tx:    tx,
+		Inscribe: &devents.Inscribe{
+			Owner:  sat.owner,
+			Amount: tf.Amount,
+			Sn:     fmt.Sprintf("%si0", tx.Hash),
+			Vout:   sat.vout,
+			Offset: sat.offset,
+		},
+	}
+	return []*devents.TxResult{result}, nil
+}
+
+func (p *Protocol) verifyInscribeTransfer(rules *types.Rules, owner string, md *devents.MetaData) (*Transfer, *xyerrors.InsError) {
+	tf := &Transfer{}
+	err := json.Unmarshal([]byte(md.Data), tf)
+	if err != nil {
+		return nil, xyerrors.NewInsError(-13, fmt.Sprintf("data json deocde err:%v, data[%s]", err, md.Data))
+	}
+
+	if tf.Amount.LessThanOrEqual(decimal.Zero) {
+		return nil, xyerrors.NewInsError(-14, "transfer amount <= 0")
+	}
+
+	var (
+		protocol = md.Protocol
+		tick     = md.Tick
+	)
+	ok, inscription := p.cache.Inscription.Get(protocol, tick)
+	if !ok || inscription == nil {
+		return nil, xyerrors.NewInsError(-15, fmt.Sprintf("inscription not exist, protocol[%s]-tick[%s]", protocol, tick))
+	}
+
+	if inscription.TransferType != model.TransferTypeHash {
+		return nil, xyerrors.NewInsError(-15, fmt.Sprintf("inscription transfer type[%d] not supported, protocol[%s]-tick[%s]", inscription.TransferType, protocol, tick))
+	}
+
+	// transfer amount precision checking
+	amount, insErr := p.Protocol.CheckAmount(rules, tf.Amount, inscription.Decimals)
+	if insErr != nil {
+		return nil, insErr
+	}
+	tf.Amount = amount
+
+	// owner balance checking
+	ok, balance := p.cache.Balance.Get(protocol, tick, owner)
+	if !ok {
+		return nil, xyerrors.NewInsError(-16, fmt.Sprintf("owner balance record not exist, tick[%s-%s], address[%s]", protocol, tick, owner))
+	}
+
+	// balance available checking
+	if balance.Available.LessThan(tf.Amount) {
+		return nil, xyerrors.NewInsError(-17, fmt.Sprintf("owner available balance[%v] < transfer amount[%v]", balance.Available, tf.Amount))
+	}
+	return tf, nil
+}
+
+// Settle
+/***************************************
+ * move the locked balance of the transferable inscriptions spent by the tx.
+ * the inscribed sat is tracked by its offset through the input & output values, it goes to
+ * the output holding the sat, e.g. the second input of marketplace PSBTs after a dummy input.
+ * the transfer is returned to the owner if the sat is spent as fee, or the values of the
+ * preceding inputs are unknown
+ ***************************************/
+func (p *Protocol) Settle(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) []*devents.TxResult {
+	results := make([]*devents.TxResult, 0, 1)
+	for idx, in := range tx.Vin {
+		ok, item := p.spentUTXO(in.Txid, in.Vout)
+		if !ok {
+			continue
+		}
+
+		receiver := item.Owner
+		if offset, known := inputOffset(tx, idx); !known {
+			xylog.Logger.Warnf("transferable inscription spent in input[%d] with unknown prevout values, returned to the owner. tx[%s], utxo[%s]", idx, tx.Hash, in.Txid)
+		} else if vout, _, found := locateSat(tx.Vout, offset+item.Offset); found {
+			if addr := outputAddress(&tx.Vout[vout]); addr != "" {
+				receiver = addr
+			}
+		}
+
+		settleMD := md.Copy()
+		settleMD.Protocol = item.Protocol
+		settleMD.Operate = devents.OperateTransfer
+		settleMD.Tick = item.Tick
+		settleMD.Data = ""
+		results = append(results, &devents.TxResult{
+			MD:    settleMD,
+			Block: block,
+			Tx:    tx,
+			Transfer: &devents.Transfer{
+				Sender: item.Owner,
+				Receives: []*devents.Receive{
+					{
+						Address: receiver,
+						Amount:  item.Amount,
+					},
+				},
+				UTXO: in.Txid,
+			},
+		})
+	}
+	return results
+}
+
+// satPoint the output & offset of an inscribed sat
+type satPoint struct {
+	owner  string
+	vout   uint32
+	offset uint64
+}
+
+// revealedSat the inscription is revealed to the first sat of the input carrying the envelope
+func revealedSat(tx *xycommon.RpcTransaction) (*satPoint, *xyerrors.InsError) {
+	input := 0
+	if envelopes := ord.ParseEnvelopes(tx.Vin); len(envelopes) > 0 {
+		input = envelopes[0].Input
+	}
+
+	offset, known := inputOffset(tx, input)
+	if !known {
+		return nil, xyerrors.NewInsError(-18, fmt.Sprintf("inscription revealed in input[%d] with unknown prevout values", input))
+	}
+
+	vout, satOffset, found := locateSat(tx.Vout, offset)
+	if !found {
+		return nil, xyerrors.NewInsError(-18, "inscription revealed as fee")
+	}
+
+	owner := outputAddress(&tx.Vout[vout])
+	if owner == "" {
+		return nil, xyerrors.NewInsError(-18, fmt.Sprintf("inscription revealed to output[%d] without address", vout))
+	}
+	return &satPoint{owner: owner, vout: vout, offset: satOffset}, nil
+}
+
+// inputOffset the offset of the first sat of the input among the sats of all inputs,
+// unknown if the value of any preceding input is missing
+func inputOffset(tx *xycommon.RpcTransaction, input int) (uint64, bool) {
+	offset := uint64(0)
+	for idx := 0; idx < input; idx++ {
+		if idx >= len(tx.Prevouts) || tx.Prevouts[idx] == nil {
+			return 0, false
+		}
+		offset += satoshi(tx.Prevouts[idx].Value)
+	}
+	return offset, true
+}
+
+// locateSat the output holding the sat at the offset among the sats of all inputs, and the
+// offset within that output. not found if the sat is spent as fee
+func locateSat(vout []btcjson.Vout, offset uint64) (uint32, uint64, bool) {
+	for idx := range vout {
+		value := satoshi(vout[idx].Value)
+		if offset < value {
+			return uint32(idx), offset, true
+		}
+		offset -= value
+	}
+	return 0, 0, false
+}
+
+// outputAddress empty if the output has no address, e.g. OP_RETURN
+func outputAddress(out *btcjson.Vout) string {
+	if out.ScriptPubKey.Address != "" {
+		return out.ScriptPubKey.Address
+	}
+	if len(out.ScriptPubKey.Addresses) > 0 {
+		return out.ScriptPubKey.Addresses[0]
+	}
+	return ""
+}
+
+// satoshi converts the btc amount of a json response
+func satoshi(value float64) uint64 {
+	if value <= 0 {
+		return 0
+	}
+	return uint64(math.Round(value * 1e8))
+}
+
+// spentUTXO the transferable inscription held by the output, keyed by the inscribe tx
+func (p *Protocol) spentUTXO(txHash string, vout uint32) (bool, *dcache.UTXOItem) {
+	if txHash == "" {
+		return false, nil
+	}
+
+	ok, item := p.cache.UTXO.Get(txHash)
+	if !ok || item.Vout != vout {
+		return false, nil
+	}
+	return true, item
+}
@@ -0,0 +1,295 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package brc20
+
+import (
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"testing"
+)
+
+func init() {
+	xylog.InitLog(logrus.DebugLevel, "")
+}
+
+const (
+	owner    = "bc1powner"
+	receiver = "bc1preceiver"
+)
+
+func newTestCache() *dcache.Manager {
+	cache := dcache.NewManager(nil, model.ChainBTC)
+	cache.Balance = dcache.NewBalance()
+	cache.UTXO = dcache.NewUTXO()
+	cache.Inscription = dcache.NewInscription()
+	cache.InscriptionStats = dcache.NewInscriptionStats()
+	return cache
+}
+
+func parseAndApply(t *testing.T, p *Protocol, handler *devents.TxResultHandler, tx *xycommon.RpcTransaction, md *devents.MetaData) []*devents.TxResult {
+	block := &xycommon.RpcBlock{Number: big.NewInt(1), Time: 1}
+	results, err := p.Parse(block, tx, md)
+	if err != nil {
+		t.Fatalf("parse tx[%s] err:%v", tx.Hash, err)
+	}
+	for _, result := range results {
+		handler.UpdateCache(result)
+	}
+	return results
+}
+
+func output(value float64, addr string) btcjson.Vout {
+	return btcjson.Vout{Value: value, ScriptPubKey: btcjson.ScriptPubKeyResult{Address: addr}}
+}
+
+// inscribeTx reveals the inscription to the first sat of the outputs
+func inscribeTx(hash string, vout ...btcjson.Vout) *xycommon.RpcTransaction {
+	return &xycommon.RpcTransaction{Hash: hash, To: vout[0].ScriptPubKey.Address, Vout: vout}
+}
+
+func inscriptionMD(op, data string) *devents.MetaData {
+	return &devents.MetaData{
+		Chain:    model.ChainBTC,
+		Protocol: types.BRC20Protocol,
+		Operate:  op,
+		Tick:     "ordi",
+		Data:     data,
+	}
+}
+
+func TestTwoStepTransfer(t *testing.T) {
+	cache := newTestCache()
+	p := NewProtocol(cache)
+	handler := devents.NewTxResultHandler(cache)
+
+	parseAndApply(t, p, handler, &xycommon.RpcTransaction{Hash: "01", To: owner},
+		inscriptionMD(devents.OperateDeploy, `{"p":"brc-20","op":"deploy","tick":"ordi","max":"21000000","lim":"1000"}`))
+	_, tick := cache.Inscription.Get(types.BRC20Protocol, "ordi")
+	assert.Equal(t, int8(model.TransferTypeHash), tick.TransferType)
+
+	parseAndApply(t, p, handler, &xycommon.RpcTransaction{Hash: "02", To: owner},
+		inscriptionMD(devents.OperateMint, `{"p":"brc-20","op":"mint","tick":"ordi","amt":"1000"}`))
+	_, balance := cache.Balance.Get(types.BRC20Protocol, "ordi", owner)
+	assert.Equal(t, "1000", balance.Available.String())
+	assert.Equal(t, "1000", balance.Overall.String())
+
+	// inscribe-transfer only locks the available balance
+	results := parseAndApply(t, p, handler, inscribeTx("03", output(0.00000546, owner)),
+		inscriptionMD(devents.OperateTransfer, `{"p":"brc-20","op":"transfer","tick":"ordi","amt":"400"}`))
+	assert.Equal(t, "03i0", results[0].Inscribe.Sn)
+	_, balance = cache.Balance.Get(types.BRC20Protocol, "ordi", owner)
+	assert.Equal(t, "600", balance.Available.String())
+	assert.Equal(t, "1000", balance.Overall.String())
+
+	ok, utxo := cache.UTXO.Get("03")
+	assert.True(t, ok)
+	assert.Equal(t, owner, utxo.Owner)
+	assert.Equal(t, uint32(0), utxo.Vout)
+
+	utxos := handler.BuildUTXO(results[0])
+	assert.Equal(t, model.UTXOStatusUnspent, int(utxos[devents.DBActionCreate].Status))
+	assert.Equal(t, "03", utxos[devents.DBActionCreate].RootHash)
+
+	// locked balance can't be inscribed again
+	_, err := p.Parse(&xycommon.RpcBlock{Number: big.NewInt(1)}, inscribeTx("04", output(0.00000546, owner)),
+		inscriptionMD(devents.OperateTransfer, `{"p":"brc-20","op":"transfer","tick":"ordi","amt":"700"}`))
+	assert.NotNil(t, err)
+
+	// spending the utxo moves the overall balance
+	spend := &xycommon.RpcTransaction{
+		Hash: "05",
+		To:   receiver,
+		Vin:  []btcjson.Vin{{Txid: "03", Vout: 0}},
+		Vout: []btcjson.Vout{{Value: 0.00000546, ScriptPubKey: btcjson.ScriptPubKeyResult{Address: receiver}}},
+	}
+	md := p.ParseMetaData(model.ChainBTC, spend)
+	assert.NotNil(t, md)
+	results = parseAndApply(t, p, handler, spend, md)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "03", results[0].Transfer.UTXO)
+
+	_, balance = cache.Balance.Get(types.BRC20Protocol, "ordi", owner)
+	assert.Equal(t, "600", balance.Available.String())
+	assert.Equal(t, "600", balance.Overall.String())
+
+	_, balance = cache.Balance.Get(types.BRC20Protocol, "ordi", receiver)
+	assert.Equal(t, "400", balance.Available.String())
+	assert.Equal(t, "400", balance.Overall.String())
+
+	ok, _ = cache.UTXO.Get("03")
+	assert.False(t, ok)
+	assert.Nil(t, p.ParseMetaData(model.ChainBTC, spend))
+
+	utxos = handler.BuildUTXO(results[0])
+	assert.Equal(t, model.UTXOStatusSpent, int(utxos[devents.DBActionUpdate].Status))
+	assert.Equal(t, "05", utxos[devents.DBActionUpdate].TxHash)
+}
+
+func TestSettleSpentAsFee(t *testing.T) {
+	cache := newTestCache()
+	p := NewProtocol(cache)
+	handler := devents.NewTxResultHandler(cache)
+
+	parseAndApply(t, p, handler, &xycommon.RpcTransaction{Hash: "01", To: owner},
+		inscriptionMD(devents.OperateDeploy, `{"p":"brc-20","op":"deploy","tick":"ordi","max":"21000000","lim":"1000"}`))
+	parseAndApply(t, p, handler, &xycommon.RpcTransaction{Hash: "02", To: owner},
+		inscriptionMD(devents.OperateMint, `{"p":"brc-20","op":"mint","tick":"ordi","amt":"1000"}`))
+	parseAndApply(t, p, handler, inscribeTx("03", output(0.00000546, owner)),
+		inscriptionMD(devents.OperateTransfer, `{"p":"brc-20","op":"transfer","tick":"ordi","amt":"400"}`))
+
+	// no output address, returned to the owner
+	spend := &xycommon.RpcTransaction{
+		Hash: "04",
+		Vin:  []btcjson.Vin{{Txid: "03", Vout: 0}},
+	}
+	parseAndApply(t, p, handler, spend, p.ParseMetaData(model.ChainBTC, spend))
+
+	_, balance := cache.Balance.Get(types.BRC20Protocol, "ordi", owner)
+	assert.Equal(t, "1000", balance.Available.String())
+	assert.Equal(t, "1000", balance.Overall.String())
+
+	ok, _ := cache.UTXO.Get("03")
+	assert.False(t, ok)
+}
+
+func TestSettleSatOffset(t *testing.T) {
+	newSpend := func(hash string, vin []btcjson.Vin, vout []btcjson.Vout, prevouts ...*btcjson.Vout) *xycommon.RpcTransaction {
+		return &xycommon.RpcTransaction{Hash: hash, Vin: vin, Vout: vout, Prevouts: prevouts}
+	}
+	prevout := func(value float64) *btcjson.Vout {
+		return &btcjson.Vout{Value: value}
+	}
+
+	tests := []struct {
+		name  string
+		spend *xycommon.RpcTransaction
+		want  string
+	}{
+		{
+			name:  "first output",
+			spend: newSpend("04", []btcjson.Vin{{Txid: "03"}}, []btcjson.Vout{output(0.00000546, receiver), output(0.001, owner)}),
+			want:  receiver,
+		},
+		{
+			name:  "zero value outputs skipped",
+			spend: newSpend("04", []btcjson.Vin{{Txid: "03"}}, []btcjson.Vout{output(0, ""), output(0.00000546, receiver)}),
+			want:  receiver,
+		},
+		{
+			name: "after a dummy input",
+			spend: newSpend("04", []btcjson.Vin{{Txid: "aa"}, {Txid: "03"}}, []btcjson.Vout{output(0.000006, "bc1pdummy"), output(0.00000546, receiver)},
+				prevout(0.000006), prevout(0.00000546)),
+			want: receiver,
+		},
+		{
+			name: "within the first output",
+			spend: newSpend("04", []btcjson.Vin{{Txid: "aa"}, {Txid: "03"}}, []btcjson.Vout{output(0.00001146, receiver)},
+				prevout(0.000006), prevout(0.00000546)),
+			want: receiver,
+		},
+		{
+			name: "spent as fee",
+			spend: newSpend("04", []btcjson.Vin{{Txid: "aa"}, {Txid: "03"}}, []btcjson.Vout{output(0.000006, "bc1pdummy")},
+				prevout(0.000006), prevout(0.00000546)),
+			want: owner,
+		},
+		{
+			name:  "unknown prevout values",
+			spend: newSpend("04", []btcjson.Vin{{Txid: "aa"}, {Txid: "03"}}, []btcjson.Vout{output(0.00000546, receiver)}),
+			want:  owner,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newTestCache()
+			p := NewProtocol(cache)
+			handler := devents.NewTxResultHandler(cache)
+
+			parseAndApply(t, p, handler, &xycommon.RpcTransaction{Hash: "01", To: owner},
+				inscriptionMD(devents.OperateDeploy, `{"p":"brc-20","op":"deploy","tick":"ordi","max":"21000000","lim":"1000"}`))
+			parseAndApply(t, p, handler, &xycommon.RpcTransaction{Hash: "02", To: owner},
+				inscriptionMD(devents.OperateMint, `{"p":"brc-20","op":"mint","tick":"ordi","amt":"1000"}`))
+			parseAndApply(t, p, handler, inscribeTx("03", output(0.00000546, owner)),
+				inscriptionMD(devents.OperateTransfer, `{"p":"brc-20","op":"transfer","tick":"ordi","amt":"400"}`))
+
+			results := parseAndApply(t, p, handler, tt.spend, p.ParseMetaData(model.ChainBTC, tt.spend))
+			assert.Len(t, results, 1)
+			assert.Equal(t, tt.want, results[0].Transfer.Receives[0].Address)
+
+			ok, _ := cache.UTXO.Get("03")
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestInscribeTransferLaterOutput(t *testing.T) {
+	cache := newTestCache()
+	p := NewProtocol(cache)
+	handler := devents.NewTxResultHandler(cache)
+
+	parseAndApply(t, p, handler, &xycommon.RpcTransaction{Hash: "01", To: owner},
+		inscriptionMD(devents.OperateDeploy, `{"p":"brc-20","op":"deploy","tick":"ordi","max":"21000000","lim":"1000"}`))
+	parseAndApply(t, p, handler, &xycommon.RpcTransaction{Hash: "02", To: owner},
+		inscriptionMD(devents.OperateMint, `{"p":"brc-20","op":"mint","tick":"ordi","amt":"1000"}`))
+
+	// the first output carries no sats, the inscription goes to the second one
+	results := parseAndApply(t, p, handler, inscribeTx("03", output(0, ""), output(0.00000546, owner)),
+		inscriptionMD(devents.OperateTransfer, `{"p":"brc-20","op":"transfer","tick":"ordi","amt":"400"}`))
+	assert.Equal(t, owner, results[0].Inscribe.Owner)
+	assert.Equal(t, uint32(1), results[0].Inscribe.Vout)
+	assert.Equal(t, uint32(1), handler.BuildUTXO(results[0])[devents.DBActionCreate].Vout)
+
+	// the other outputs carry no inscription
+	other := &xycommon.RpcTransaction{Hash: "04", Vin: []btcjson.Vin{{Txid: "03", Vout: 0}}, Vout: []btcjson.Vout{output(0.00000546, receiver)}}
+	assert.Nil(t, p.ParseMetaData(model.ChainBTC, other))
+
+	spend := &xycommon.RpcTransaction{Hash: "05", Vin: []btcjson.Vin{{Txid: "03", Vout: 1}}, Vout: []btcjson.Vout{output(0.00000546, receiver)}}
+	results = parseAndApply(t, p, handler, spend, p.ParseMetaData(model.ChainBTC, spend))
+	assert.Len(t, results, 1)
+	assert.Equal(t, receiver, results[0].Transfer.Receives[0].Address)
+}
+
+func TestSettleStoredOffset(t *testing.T) {
+	cache := newTestCache()
+	p := NewProtocol(cache)
+	cache.UTXO.Add(types.BRC20Protocol, "ordi", "03", owner, decimal.NewFromInt(400), "03i0", "", 1, 300)
+
+	// the inscribed sat sits at offset 300 of the spent output
+	spend := &xycommon.RpcTransaction{
+		Hash: "04",
+		Vin:  []btcjson.Vin{{Txid: "03", Vout: 1}},
+		Vout: []btcjson.Vout{output(0.000003, "bc1pother"), output(0.00000546, receiver)},
+	}
+	results := p.Settle(&xycommon.RpcBlock{Number: big.NewInt(1)}, spend, p.ParseMetaData(model.ChainBTC, spend))
+	assert.Len(t, results, 1)
+	assert.Equal(t, receiver, results[0].Transfer.Receives[0].Address)
+}
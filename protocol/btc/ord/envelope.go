@@ -0,0 +1,245 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package ord
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"github.com/btcsuite/btcd/btcjson"
+	"strings"
+)
+
+const (
+	opFalse     byte = 0x00
+	opPushData1 byte = 0x4c
+	opPushData2 byte = 0x4d
+	opPushData4 byte = 0x4e
+	op1Negate   byte = 0x4f
+	op1         byte = 0x51
+	op16        byte = 0x60
+	opIf        byte = 0x63
+	opEndIf     byte = 0x68
+
+	// annex prefix of the last witness element, BIP-341
+	annexTag byte = 0x50
+)
+
+var (
+	protocolID = []byte("ord")
+
+	// OP_FALSE OP_IF OP_PUSHBYTES_3 "ord"
+	envelopeHeader = "0063036f7264"
+
+	tagContentType = []byte{1}
+
+	errScriptTruncated = errors.New("script truncated")
+)
+
+// Envelope an inscription revealed in a taproot script path spend
+/***************************************
+ * OP_FALSE OP_IF "ord"
+ *   <tag> <value> ...   e.g. 1 "text/plain;charset=utf-8"
+ *   OP_0 <body> <body>...
+ * OP_ENDIF
+ ***************************************/
+type Envelope struct {
+	Input       int // index of the tx input
+	ContentType string
+	Body        []byte
+}
+
+type instruction struct {
+	op     byte
+	data   []byte
+	isPush bool
+}
+
+// ContainsEnvelope fast checking whether any input witness may carry an inscription
+func ContainsEnvelope(vin []btcjson.Vin) bool {
+	for _, in := range vin {
+		for _, item := range in.Witness {
+			if strings.Contains(item, envelopeHeader) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ParseEnvelopes parse inscription envelopes of all inputs in order
+func ParseEnvelopes(vin []btcjson.Vin) []*Envelope {
+	envelopes := make([]*Envelope, 0, 1)
+	for idx, in := range vin {
+		script := tapscript(in.Witness)
+		if script == nil {
+			continue
+		}
+
+		for _, envelope := range parseScript(script) {
+			envelope.Input = idx
+			envelopes = append(envelopes, envelope)
+		}
+	}
+	return envelopes
+}
+
+// tapscript the leaf script of a script path spend: [stack..., script, control block, annex?]
+func tapscript(witness []string) []byte {
+	items := make([][]byte, 0, len(witness))
+	for _, item := range witness {
+		data, err := hex.DecodeString(item)
+		if err != nil {
+			return nil
+		}
+		items = append(items, data)
+	}
+
+	if len(items) >= 2 && len(items[len(items)-1]) > 0 && items[len(items)-1][0] == annexTag {
+		items = items[:len(items)-1]
+	}
+
+	// key path spend
+	if len(items) < 2 {
+		return nil
+	}
+	return items[len(items)-2]
+}
+
+func parseScript(script []byte) []*Envelope {
+	// instructions decoded before a truncated push are still checked
+	instructions, _ := decodeScript(script)
+
+	envelopes := make([]*Envelope, 0, 1)
+	for i := 0; i+2 < len(instructions); i++ {
+		if !isPushOf(instructions[i], nil) || instructions[i+1].op != opIf || !isPushOf(instructions[i+2], protocolID) {
+			continue
+		}
+
+		envelope, end := parseEnvelope(instructions[i+3:])
+		if envelope != nil {
+			envelopes = append(envelopes, envelope)
+		}
+		i += 2 + end
+	}
+	return envelopes
+}
+
+// parseEnvelope parse the fields till OP_ENDIF, returns the consumed instructions count
+func parseEnvelope(instructions []instruction) (*Envelope, int) {
+	fields := make([][]byte, 0, 4)
+	body := make([]byte, 0, 128)
+	inBody := false
+	for idx, ins := range instructions {
+		if ins.op == opEndIf && !ins.isPush {
+			return buildEnvelope(fields, body), idx + 1
+		}
+
+		// only data pushes are allowed in envelopes
+		if !ins.isPush {
+			return nil, idx + 1
+		}
+
+		if inBody {
+			body = append(body, ins.data...)
+			continue
+		}
+
+		// OP_0 at the tag position starts the body
+		if len(fields)%2 == 0 && len(ins.data) == 0 {
+			inBody = true
+			continue
+		}
+		fields = append(fields, ins.data)
+	}
+
+	// OP_ENDIF missing
+	return nil, len(instructions)
+}
+
+func buildEnvelope(fields [][]byte, body []byte) *Envelope {
+	envelope := &Envelope{Body: body}
+	for i := 0; i+1 < len(fields); i += 2 {
+		// the first field wins if duplicated
+		if bytes.Equal(fields[i], tagContentType) && envelope.ContentType == "" {
+			envelope.ContentType = string(fields[i+1])
+		}
+	}
+	return envelope
+}
+
+func isPushOf(ins instruction, data []byte) bool {
+	return ins.isPush && bytes.Equal(ins.data, data)
+}
+
+func decodeScript(script []byte) ([]instruction, error) {
+	instructions := make([]instruction, 0, 16)
+	for pos := 0; pos < len(script); {
+		op := script[pos]
+		pos++
+
+		var size int
+		switch {
+		case op == opFalse:
+			instructions = append(instructions, instruction{op: op, data: []byte{}, isPush: true})
+			continue
+		case op < opPushData1:
+			size = int(op)
+		case op == opPushData1:
+			if pos+1 > len(script) {
+				return instructions, errScriptTruncated
+			}
+			size = int(script[pos])
+			pos++
+		case op == opPushData2:
+			if pos+2 > len(script) {
+				return instructions, errScriptTruncated
+			}
+			size = int(binary.LittleEndian.Uint16(script[pos:]))
+			pos += 2
+		case op == opPushData4:
+			if pos+4 > len(script) {
+				return instructions, errScriptTruncated
+			}
+			size = int(binary.LittleEndian.Uint32(script[pos:]))
+			pos += 4
+		case op == op1Negate:
+			instructions = append(instructions, instruction{op: op, data: []byte{0x81}, isPush: true})
+			continue
+		case op >= op1 && op <= op16:
+			instructions = append(instructions, instruction{op: op, data: []byte{op - op1 + 1}, isPush: true})
+			continue
+		default:
+			instructions = append(instructions, instruction{op: op})
+			continue
+		}
+
+		if size < 0 || pos+size > len(script) {
+			return instructions, errScriptTruncated
+		}
+		instructions = append(instructions, instruction{op: op, data: script[pos : pos+size], isPush: true})
+		pos += size
+	}
+	return instructions, nil
+}
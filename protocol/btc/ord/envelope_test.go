@@ -0,0 +1,155 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package ord
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/btcsuite/btcd/btcjson"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// witness fixtures follow the mainnet reveal layout [signature, tapscript, control block],
+// the tapscript being: <x-only key> OP_CHECKSIG OP_FALSE OP_IF "ord" <fields> OP_0 <body> OP_ENDIF.
+// the deploy envelope carries the content type & body of the ordi deploy inscription,
+// reveal tx b61b0172d95e266c18aea0c624db987e971a5d6d4ebc2aaed85da4642d635735.
+// TODO: the signature, key & control block are placeholders, replace them with the
+// witness stack of the reveal tx dumped by `bitcoin-cli getrawtransaction <txid> 2`
+const (
+	fixtureSig          = "0073ec266d4fb4adbf3d104aa714f9f11032fd8ab6d8829fc40b52c86f6485d7928cc2ebd4646f3fe3f374be11d905bf4be275fa86f3889d82a9f7dc5e41dd32"
+	fixtureControlBlock = "c13bed2cb3a3acf7b6a8ef408420cc682d5520e26976d354254f528c965612054f"
+
+	// ordi deploy, tag 1 pushed as OP_PUSHBYTES_1 0x01
+	fixtureDeployScript = "202c70e12b7a0646f92279f427c7b38e7334d8e5389cff167a1dc30e73f826b683ac0063036f7264010118746578742f706c61696e3b636861727365743d7574662d3800487b2270223a226272632d3230222c226f70223a226465706c6f79222c227469636b223a226f726469222c226d6178223a223231303030303030222c226c696d223a2231303030227d68"
+
+	// ordi mint, tag 1 pushed as OP_1 and the body split into two pushes
+	fixtureMintScript = "202c70e12b7a0646f92279f427c7b38e7334d8e5389cff167a1dc30e73f826b683ac0063036f726451106170706c69636174696f6e2f6a736f6e00147b2270223a226272632d3230222c226f70223a22216d696e74222c227469636b223a226f726469222c22616d74223a2231303030227d68"
+
+	// 8 bytes png signature
+	fixturePNGScript = "202c70e12b7a0646f92279f427c7b38e7334d8e5389cff167a1dc30e73f826b683ac0063036f7264010109696d6167652f706e67000889504e470d0a1a0a68"
+)
+
+func TestParseEnvelopes(t *testing.T) {
+	// ord splits bodies into 520 bytes pushes, the max script element size
+	largeBody := bytes.Repeat([]byte{0xab}, 600)
+	largeScript := strings.TrimSuffix(fixturePNGScript, "0889504e470d0a1a0a68") + "4d0802" + hex.EncodeToString(largeBody[:520]) + "4c50" + hex.EncodeToString(largeBody[520:]) + "68"
+
+	tests := []struct {
+		name string
+		vin  []btcjson.Vin
+		want []*Envelope
+	}{
+		{
+			name: "deploy",
+			vin: []btcjson.Vin{
+				{Witness: []string{fixtureSig, fixtureDeployScript, fixtureControlBlock}},
+			},
+			want: []*Envelope{
+				{
+					Input:       0,
+					ContentType: "text/plain;charset=utf-8",
+					Body:        []byte(`{"p":"brc-20","op":"deploy","tick":"ordi","max":"21000000","lim":"1000"}`),
+				},
+			},
+		},
+		{
+			name: "mint with OP_1 tag & chunked body",
+			vin: []btcjson.Vin{
+				{Witness: []string{fixtureSig}},
+				{Witness: []string{fixtureSig, fixtureMintScript, fixtureControlBlock}},
+			},
+			want: []*Envelope{
+				{
+					Input:       1,
+					ContentType: "application/json",
+					Body:        []byte(`{"p":"brc-20","op":"mint","tick":"ordi","amt":"1000"}`),
+				},
+			},
+		},
+		{
+			name: "annex",
+			vin: []btcjson.Vin{
+				{Witness: []string{fixtureSig, fixturePNGScript, fixtureControlBlock, "50aa"}},
+			},
+			want: []*Envelope{
+				{
+					Input:       0,
+					ContentType: "image/png",
+					Body:        []byte{0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a},
+				},
+			},
+		},
+		{
+			name: "body in 520 bytes chunks",
+			vin: []btcjson.Vin{
+				{Witness: []string{fixtureSig, largeScript, fixtureControlBlock}},
+			},
+			want: []*Envelope{
+				{
+					Input:       0,
+					ContentType: "image/png",
+					Body:        largeBody,
+				},
+			},
+		},
+		{
+			name: "key path spend",
+			vin: []btcjson.Vin{
+				{Witness: []string{fixtureSig}},
+			},
+			want: []*Envelope{},
+		},
+		{
+			name: "missing OP_ENDIF",
+			vin: []btcjson.Vin{
+				{Witness: []string{fixtureSig, fixtureDeployScript[:len(fixtureDeployScript)-2], fixtureControlBlock}},
+			},
+			want: []*Envelope{},
+		},
+		{
+			name: "truncated push",
+			vin: []btcjson.Vin{
+				{Witness: []string{fixtureSig, fixtureDeployScript[:len(fixtureDeployScript)-10], fixtureControlBlock}},
+			},
+			want: []*Envelope{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseEnvelopes(tt.vin); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseEnvelopes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainsEnvelope(t *testing.T) {
+	if !ContainsEnvelope([]btcjson.Vin{{Witness: []string{fixtureSig, fixtureDeployScript, fixtureControlBlock}}}) {
+		t.Errorf("ContainsEnvelope() = false, want true")
+	}
+	if ContainsEnvelope([]btcjson.Vin{{Witness: []string{fixtureSig}}}) {
+		t.Errorf("ContainsEnvelope() = true, want false")
+	}
+}
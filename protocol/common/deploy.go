@@ -28,20 +28,19 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/protocol/types"
 	"github.com/uxuycom/indexer/xyerrors"
-	"math"
-	"math/big"
 )
 
 type Deploy struct {
-	Tick      string          `json:"tick"`
-	MaxSupply decimal.Decimal `json:"max"`
-	MintLimit decimal.Decimal `json:"lim"`
-	Decimal   decimal.Decimal `json:"dec"`
+	Tick      string              `json:"tick"`
+	MaxSupply decimal.Decimal     `json:"max"`
+	MintLimit decimal.Decimal     `json:"lim"`
+	Decimal   decimal.NullDecimal `json:"dec"`
 }
 
 func (base *Protocol) Deploy(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
-	d, err := base.verifyDeploy(tx, md)
+	d, err := base.verifyDeploy(block, tx, md)
 	if err != nil {
 		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
 	}
@@ -54,13 +53,13 @@ func (base *Protocol) Deploy(block *xycommon.RpcBlock, tx *xycommon.RpcTransacti
 			Name:      d.Tick,
 			MaxSupply: d.MaxSupply,
 			MintLimit: d.MintLimit,
-			Decimal:   int8(d.Decimal.IntPart()),
+			Decimal:   int8(d.Decimal.Decimal.IntPart()),
 		},
 	}
 	return []*devents.TxResult{result}, nil
 }
 
-func (base *Protocol) verifyDeploy(tx *xycommon.RpcTransaction, md *devents.MetaData) (*Deploy, *xyerrors.InsError) {
+func (base *Protocol) verifyDeploy(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) (*Deploy, *xyerrors.InsError) {
 	// metadata protocol / tick checking
 	if md.Protocol == "" || md.Tick == "" {
 		return nil, xyerrors.NewInsError(-12, fmt.Sprintf("protocol[%s] / tick[%s] nil", md.Protocol, md.Tick))
@@ -92,20 +91,25 @@ func (base *Protocol) verifyDeploy(tx *xycommon.RpcTransaction, md *devents.Meta
 		return nil, xyerrors.NewInsError(-16, "max < limit")
 	}
 
+	// decimals not set
+	rules := types.RulesAt(md.Protocol, md.Chain, block.Number.Uint64())
+	if !deploy.Decimal.Valid {
+		deploy.Decimal = decimal.NewNullDecimal(decimal.NewFromInt(int64(rules.Decimals)))
+	}
+
 	// decimal value only int type is valid
-	if !deploy.Decimal.IsInteger() {
-		return nil, xyerrors.NewInsError(-17, fmt.Sprintf("invalid decimal:%s", deploy.Decimal.String()))
+	if !deploy.Decimal.Decimal.IsInteger() || deploy.Decimal.Decimal.IsNegative() {
+		return nil, xyerrors.NewInsError(-17, fmt.Sprintf("invalid decimal:%s", deploy.Decimal.Decimal.String()))
 	}
 
 	// maximum decimals is 18
-	if deploy.Decimal.IntPart() > 18 {
-		return nil, xyerrors.NewInsError(-18, fmt.Sprintf("decimal[%d] > 18", deploy.Decimal.IntPart()))
+	if deploy.Decimal.Decimal.IntPart() > MaxDecimals {
+		return nil, xyerrors.NewInsError(-18, fmt.Sprintf("decimal[%d] > %d", deploy.Decimal.Decimal.IntPart(), MaxDecimals))
 	}
 
-	// MaxSupply must <= uint64
-	maxUint64Decimal := decimal.NewFromBigInt(new(big.Int).SetUint64(math.MaxUint64), 0)
-	if deploy.MaxSupply.GreaterThan(maxUint64Decimal) {
-		return nil, xyerrors.NewInsError(-19, fmt.Sprintf("max[%s] > max_uint64", deploy.MaxSupply.String()))
+	// MaxSupply must <= the protocol max supply, max_uint64 by default
+	if deploy.MaxSupply.GreaterThan(rules.MaxSupply) {
+		return nil, xyerrors.NewInsError(-19, fmt.Sprintf("max[%s] > %s", deploy.MaxSupply.String(), rules.MaxSupply.String()))
 	}
 	return deploy, nil
 }
@@ -28,7 +28,9 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/protocol/types"
 	"github.com/uxuycom/indexer/xyerrors"
+	"strings"
 )
 
 type Mint struct {
@@ -36,23 +38,33 @@ type Mint struct {
 }
 
 func (base *Protocol) Mint(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
-	m, err := base.verifyMint(tx, md)
+	rules := types.RulesAt(md.Protocol, md.Chain, block.Number.Uint64())
+	m, err := base.verifyMint(rules, tx, md)
 	if err != nil {
 		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
 	}
+
+	minter := tx.To
+	if rules.Minter == types.MinterFrom {
+		minter = tx.From
+	}
 	result := &devents.TxResult{
 		MD:    md,
 		Block: block,
 		Tx:    tx,
 		Mint: &devents.Mint{
-			Minter: tx.To,
+			Minter: minter,
 			Amount: m.Amount,
 		},
 	}
 	return []*devents.TxResult{result}, nil
 }
 
-func (base *Protocol) verifyMint(tx *xycommon.RpcTransaction, md *devents.MetaData) (*Mint, *xyerrors.InsError) {
+func (base *Protocol) verifyMint(rules *types.Rules, tx *xycommon.RpcTransaction, md *devents.MetaData) (*Mint, *xyerrors.InsError) {
+	if rules.SelfMint && !strings.EqualFold(tx.From, tx.To) {
+		return nil, xyerrors.ErrMintNotSelfSent
+	}
+
 	mint := &Mint{}
 	err := json.Unmarshal([]byte(md.Data), mint)
 	if err != nil {
@@ -72,6 +84,13 @@ func (base *Protocol) verifyMint(tx *xycommon.RpcTransaction, md *devents.MetaDa
 		return nil, xyerrors.NewInsError(-15, fmt.Sprintf("inscription not exist, protocol[%s], tick[%s]", protocol, tick))
 	}
 
+	// mint amount precision checking
+	amount, insErr := base.CheckAmount(rules, mint.Amount, inscription.Decimals)
+	if insErr != nil {
+		return nil, insErr
+	}
+	mint.Amount = amount
+
 	// mint amount maximum checking
 	if mint.Amount.GreaterThan(inscription.LimitPerMint) {
 		return nil, xyerrors.NewInsError(-17, "mint amount exceeds limit per mint")
@@ -84,12 +103,15 @@ func (base *Protocol) verifyMint(tx *xycommon.RpcTransaction, md *devents.MetaDa
 	}
 
 	if stats.Minted.GreaterThanOrEqual(inscription.TotalSupply) {
-		return nil, xyerrors.NewInsError(-20, "mint completed")
+		return nil, xyerrors.ErrMintCompleted
 	}
 
-	// final mint = math.Min(Total Supply - Minted)
+	// final mint = math.Min(Total Supply - Minted), rejected if partial mint disabled
 	mintLeft := inscription.TotalSupply.Sub(stats.Minted)
 	if mint.Amount.GreaterThan(mintLeft) {
+		if !rules.PartialMint {
+			return nil, xyerrors.ErrMintExceedsSupply
+		}
 		mint.Amount = mintLeft
 	}
 	return mint, nil
@@ -0,0 +1,106 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package common
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xyerrors"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"testing"
+)
+
+func init() {
+	xylog.InitLog(logrus.DebugLevel, "")
+}
+
+func newTestCache() *dcache.Manager {
+	cache := dcache.NewManager(nil, model.ChainAVAX)
+	cache.Balance = dcache.NewBalance()
+	cache.Inscription = dcache.NewInscription()
+	cache.InscriptionStats = dcache.NewInscriptionStats()
+	return cache
+}
+
+func parseTx(base *Protocol, handler *devents.TxResultHandler, height int64, tx *xycommon.RpcTransaction, op, data string) ([]*devents.TxResult, *xyerrors.InsError) {
+	md := &devents.MetaData{
+		Chain:    model.ChainAVAX,
+		Protocol: types.ASC20Protocol,
+		Operate:  op,
+		Tick:     "dino",
+		Data:     data,
+	}
+	results, err := base.Parse(&xycommon.RpcBlock{Number: big.NewInt(height)}, tx, md)
+	for _, result := range results {
+		handler.UpdateCache(result)
+	}
+	return results, err
+}
+
+func TestProtocol_MintRules(t *testing.T) {
+	types.AddRuleChange(&types.RuleChange{
+		Protocol: types.ASC20Protocol,
+		Height:   100,
+		Apply: func(r *types.Rules) {
+			r.Minter = types.MinterFrom
+			r.SelfMint = true
+			r.PartialMint = false
+		},
+	})
+	t.Cleanup(types.ResetRuleChanges)
+
+	cache := newTestCache()
+	base := NewProtocol(cache)
+	handler := devents.NewTxResultHandler(cache)
+
+	_, err := parseTx(base, handler, 1, &xycommon.RpcTransaction{Hash: "01", From: "0xa", To: "0xa"},
+		devents.OperateDeploy, `{"p":"asc-20","op":"deploy","tick":"dino","max":"2500","lim":"1000"}`)
+	assert.Nil(t, err)
+
+	// before the activation height, minted to tx.to
+	results, err := parseTx(base, handler, 99, &xycommon.RpcTransaction{Hash: "02", From: "0xa", To: "0xb"},
+		devents.OperateMint, `{"p":"asc-20","op":"mint","tick":"dino","amt":"1000"}`)
+	assert.Nil(t, err)
+	assert.Equal(t, "0xb", results[0].Mint.Minter)
+
+	// mints must be self-sent from the activation height
+	_, err = parseTx(base, handler, 100, &xycommon.RpcTransaction{Hash: "03", From: "0xa", To: "0xb"},
+		devents.OperateMint, `{"p":"asc-20","op":"mint","tick":"dino","amt":"1000"}`)
+	assert.Equal(t, xyerrors.ErrMintNotSelfSent, err.Cause(nil))
+
+	results, err = parseTx(base, handler, 100, &xycommon.RpcTransaction{Hash: "04", From: "0xA", To: "0xa"},
+		devents.OperateMint, `{"p":"asc-20","op":"mint","tick":"dino","amt":"1000"}`)
+	assert.Nil(t, err)
+	assert.Equal(t, "0xA", results[0].Mint.Minter)
+
+	// 500 left, partial mint disabled
+	_, err = parseTx(base, handler, 101, &xycommon.RpcTransaction{Hash: "05", From: "0xa", To: "0xa"},
+		devents.OperateMint, `{"p":"asc-20","op":"mint","tick":"dino","amt":"1000"}`)
+	assert.Equal(t, xyerrors.ErrMintExceedsSupply, err.Cause(nil))
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package common
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xyerrors"
+)
+
+// MaxDecimals maximum tick decimals
+const MaxDecimals = 18
+
+// maxAmount amounts must fit the db column decimal(38,18)
+var maxAmount = decimal.New(1, 38-MaxDecimals)
+
+// CheckAmount
+/***************************************
+ * verify the amount against the tick decimals,
+ * returns the amount to be applied, truncated if the precision rule allows
+ ***************************************/
+func (base *Protocol) CheckAmount(rules *types.Rules, amount decimal.Decimal, decimals int8) (decimal.Decimal, *xyerrors.InsError) {
+	if amount.GreaterThanOrEqual(maxAmount) {
+		return amount, xyerrors.ErrAmountOutOfRange
+	}
+
+	// fractional digits as written, e.g. "1.10" has 2
+	if rules.Precision == types.PrecisionUnchecked || -amount.Exponent() <= int32(decimals) {
+		return amount, nil
+	}
+
+	if rules.Precision == types.PrecisionTruncate {
+		if truncated := amount.Truncate(int32(decimals)); truncated.GreaterThan(decimal.Zero) {
+			return truncated, nil
+		}
+	}
+	return amount, xyerrors.ErrAmountPrecision
+}
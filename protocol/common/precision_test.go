@@ -0,0 +1,67 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package common
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xyerrors"
+	"testing"
+)
+
+func TestProtocol_CheckAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     types.PrecisionMode
+		amount   string
+		decimals int8
+		want     string
+		wantErr  *xyerrors.InsError
+	}{
+		{name: "unchecked", mode: types.PrecisionUnchecked, amount: "0.123", decimals: 0, want: "0.123"},
+		{name: "unchecked out of db range", mode: types.PrecisionUnchecked, amount: "100000000000000000000", decimals: 18, wantErr: xyerrors.ErrAmountOutOfRange},
+		{name: "integer", mode: types.PrecisionStrict, amount: "1000", decimals: 0, want: "1000"},
+		{name: "within decimals", mode: types.PrecisionStrict, amount: "0.12", decimals: 2, want: "0.12"},
+		{name: "exceeds decimals", mode: types.PrecisionStrict, amount: "0.123", decimals: 2, wantErr: xyerrors.ErrAmountPrecision},
+		{name: "trailing zeros count", mode: types.PrecisionStrict, amount: "1.0", decimals: 0, wantErr: xyerrors.ErrAmountPrecision},
+		{name: "truncated", mode: types.PrecisionTruncate, amount: "0.129", decimals: 2, want: "0.12"},
+		{name: "truncated to zero", mode: types.PrecisionTruncate, amount: "0.001", decimals: 2, wantErr: xyerrors.ErrAmountPrecision},
+		{name: "max db value", mode: types.PrecisionStrict, amount: "99999999999999999999.999999999999999999", decimals: 18, want: "99999999999999999999.999999999999999999"},
+		{name: "out of db range", mode: types.PrecisionStrict, amount: "100000000000000000000", decimals: 18, wantErr: xyerrors.ErrAmountOutOfRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := types.DefaultRules()
+			rules.Precision = tt.mode
+
+			got, err := NewProtocol(nil).CheckAmount(&rules, decimal.RequireFromString(tt.amount), tt.decimals)
+			if err != tt.wantErr {
+				t.Errorf("CheckAmount() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("CheckAmount() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/protocol/types"
 	"github.com/uxuycom/indexer/xyerrors"
 )
 
@@ -36,7 +37,8 @@ type Transfer struct {
 }
 
 func (base *Protocol) Transfer(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
-	tf, err := base.verifyTransfer(tx, md)
+	rules := types.RulesAt(md.Protocol, md.Chain, block.Number.Uint64())
+	tf, err := base.verifyTransfer(rules, tx, md)
 	if err != nil {
 		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
 	}
@@ -57,7 +59,7 @@ func (base *Protocol) Transfer(block *xycommon.RpcBlock, tx *xycommon.RpcTransac
 	return []*devents.TxResult{result}, nil
 }
 
-func (base *Protocol) verifyTransfer(tx *xycommon.RpcTransaction, md *devents.MetaData) (*Transfer, *xyerrors.InsError) {
+func (base *Protocol) verifyTransfer(rules *types.Rules, tx *xycommon.RpcTransaction, md *devents.MetaData) (*Transfer, *xyerrors.InsError) {
 	tf := &Transfer{}
 	err := json.Unmarshal([]byte(md.Data), tf)
 	if err != nil {
@@ -77,6 +79,13 @@ func (base *Protocol) verifyTransfer(tx *xycommon.RpcTransaction, md *devents.Me
 		return nil, xyerrors.NewInsError(-15, fmt.Sprintf("inscription not exist, protocol[%s]-tick[%s]", protocol, tick))
 	}
 
+	// transfer amount precision checking
+	amount, insErr := base.CheckAmount(rules, tf.Amount, inscription.Decimals)
+	if insErr != nil {
+		return nil, insErr
+	}
+	tf.Amount = amount
+
 	// sender balance checking
 	ok, balance := base.cache.Balance.Get(protocol, tick, tx.From)
 	if !ok {
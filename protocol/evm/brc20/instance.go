@@ -24,7 +24,9 @@ package brc20
 
 import (
 	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/model"
 	"github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/protocol/types"
 )
 
 type Protocol struct {
@@ -36,3 +38,16 @@ func NewProtocol(cache *dcache.Manager) *Protocol {
 		Protocol: common.NewProtocol(cache),
 	}
 }
+
+func init() {
+	// brc-20 compatible inscriptions of evm chains
+	for _, name := range []string{types.BRC20Protocol, types.BSC20Protocol, types.PRC20Protocol} {
+		types.Register(&types.Registration{
+			Name:        name,
+			ChainGroups: []model.ChainGroup{model.EvmChainGroup},
+			New: func(cache *dcache.Manager) types.IProtocol {
+				return NewProtocol(cache)
+			},
+		})
+	}
+}
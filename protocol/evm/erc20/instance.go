@@ -24,7 +24,9 @@ package erc20
 
 import (
 	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/model"
 	"github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/protocol/types"
 )
 
 type Protocol struct {
@@ -36,3 +38,13 @@ func NewProtocol(cache *dcache.Manager) *Protocol {
 		Protocol: common.NewProtocol(cache),
 	}
 }
+
+func init() {
+	types.Register(&types.Registration{
+		Name:        types.ERC20Protocol,
+		ChainGroups: []model.ChainGroup{model.EvmChainGroup},
+		New: func(cache *dcache.Manager) types.IProtocol {
+			return NewProtocol(cache)
+		},
+	})
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package ethscriptions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/xyerrors"
+	"strings"
+)
+
+const (
+	// EventTopicHashCreate ESIP-3 ethscriptions_protocol_CreateEthscription(index_topic_1 address initialOwner, string contentURI)
+	EventTopicHashCreate = "0x665fba0baf3dc33e9943340197893ac16f56482c2defb8de60f944987fee451c"
+)
+
+var stringArguments = abi.Arguments{{Type: mustNewType("string")}}
+
+func mustNewType(t string) abi.Type {
+	typ, err := abi.NewType(t, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
+
+// createByCalldata ethscription created by the data uri calldata, owned by the tx receiver
+func (p *Protocol) createByCalldata(state *txState, tx *xycommon.RpcTransaction, content string) (*devents.Ethscription, *xyerrors.InsError) {
+	if tx.To == "" {
+		return nil, xyerrors.NewInsError(-12, "contract creation tx")
+	}
+	return p.create(state, tx.Hash, tx.From, tx.To, content, -1)
+}
+
+// createByEvent ESIP-3, ethscription created by the contract emitting the event
+func (p *Protocol) createByEvent(state *txState, tx *xycommon.RpcTransaction, idx int, log xycommon.RpcLog) (*devents.Ethscription, *xyerrors.InsError) {
+	if len(log.Topics) != 2 {
+		return nil, xyerrors.NewInsError(-13, "create event topics invalid")
+	}
+
+	values, err := stringArguments.Unpack(log.Data)
+	if err != nil || len(values) != 1 {
+		return nil, xyerrors.NewInsError(-13, fmt.Sprintf("create event data decode err:%v", err))
+	}
+
+	content, _ := values[0].(string)
+	creator := strings.ToLower(log.Address.Hex())
+	owner := strings.ToLower(common.BytesToAddress(log.Topics[1].Bytes()).Hex())
+	return p.create(state, tx.Hash, creator, owner, content, logIndex(idx, log))
+}
+
+func (p *Protocol) create(state *txState, id, creator, owner, content string, logIdx int64) (*devents.Ethscription, *xyerrors.InsError) {
+	mimetype, ok := parseDataURI(content)
+	if !ok {
+		return nil, xyerrors.NewInsError(-13, "content data uri invalid")
+	}
+
+	// one ethscription per tx, the id is the tx hash
+	if _, ok = state.owner(id); ok {
+		return nil, xyerrors.NewInsError(-15, fmt.Sprintf("ethscription[%s] exists", id))
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	sha := hex.EncodeToString(sum[:])
+	if state.contentExists(sha) {
+		return nil, xyerrors.NewInsError(-16, fmt.Sprintf("content sha[%s] ethscribed", sha))
+	}
+
+	return &devents.Ethscription{
+		Id:         strings.ToLower(id),
+		From:       creator,
+		To:         owner,
+		ContentUri: content,
+		ContentSha: sha,
+		Mimetype:   mimetype,
+		LogIndex:   logIdx,
+	}, nil
+}
+
+// logIndex index of the log in the block, or in the tx if not filled by the node
+func logIndex(idx int, log xycommon.RpcLog) int64 {
+	if log.Index == nil {
+		return int64(idx)
+	}
+	return log.Index.ToInt().Int64()
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package ethscriptions
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	dataURIPrefix = "data:"
+
+	// max mimetype length of the db column
+	maxMimetypeLength = 255
+
+	defaultMimetype = "text/plain"
+)
+
+// calldataContent the utf-8 content of the tx input, "0x" prefixed hex
+func calldataContent(input string) (string, bool) {
+	if !strings.HasPrefix(input, "0x") {
+		return "", false
+	}
+
+	data, err := hex.DecodeString(input[2:])
+	if err != nil || !utf8.Valid(data) {
+		return "", false
+	}
+	return string(data), true
+}
+
+// parseDataURI
+/***************************************
+ * validate the data uri, returns the mimetype
+ * data:[<mediatype>][;<param>=<value>...][;base64],<data>
+ ***************************************/
+func parseDataURI(uri string) (string, bool) {
+	if !strings.HasPrefix(uri, dataURIPrefix) {
+		return "", false
+	}
+
+	sep := strings.Index(uri, ",")
+	if sep < 0 {
+		return "", false
+	}
+
+	params := strings.Split(uri[len(dataURIPrefix):sep], ";")
+	mimetype := strings.TrimSpace(params[0])
+	if mimetype == "" {
+		mimetype = defaultMimetype
+	}
+
+	if !strings.Contains(mimetype, "/") || len(mimetype) > maxMimetypeLength {
+		return "", false
+	}
+
+	if len(params) > 1 && params[len(params)-1] == "base64" {
+		if _, err := base64.StdEncoding.DecodeString(uri[sep+1:]); err != nil {
+			return "", false
+		}
+	}
+	return mimetype, true
+}
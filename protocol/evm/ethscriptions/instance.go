@@ -0,0 +1,203 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package ethscriptions
+
+import (
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xyerrors"
+	"strings"
+)
+
+// Protocol
+/***************************************
+ * ethscriptions, https://docs.ethscriptions.com
+ * created by data uri calldata or ESIP-3 events, transferred by
+ * the 32-byte ethscription id calldata or ESIP-1 events.
+ * the content of an ethscription must be unique per chain
+ ***************************************/
+type Protocol struct {
+	cache *dcache.Manager
+}
+
+func NewProtocol(cache *dcache.Manager) *Protocol {
+	return &Protocol{
+		cache: cache,
+	}
+}
+
+// Parse
+/***************************************
+ * calldata ops come first, then the event logs in order.
+ * the tx is rejected only if no op is valid
+ ***************************************/
+func (p *Protocol) Parse(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	state := newTxState(p.cache)
+	results := make([]*devents.TxResult, 0, 1)
+	var firstErr *xyerrors.InsError
+
+	accept := func(operate string, e *devents.Ethscription, err *xyerrors.InsError) {
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return
+		}
+
+		// not an ethscription op
+		if e == nil {
+			return
+		}
+		state.apply(operate, e)
+
+		rmd := md.Copy()
+		rmd.Operate = operate
+		results = append(results, &devents.TxResult{
+			MD:           rmd,
+			Block:        block,
+			Tx:           tx,
+			Ethscription: e,
+		})
+	}
+
+	if content, ok := calldataContent(tx.Input); ok && strings.HasPrefix(content, dataURIPrefix) {
+		e, err := p.createByCalldata(state, tx, content)
+		accept(devents.OperateCreate, e, err)
+	} else if IsTransferCalldata(tx.Input) {
+		e, err := p.transferByCalldata(state, tx)
+		accept(devents.OperateTransfer, e, err)
+	}
+
+	for idx, log := range tx.Events {
+		if len(log.Topics) < 1 {
+			continue
+		}
+
+		switch log.Topics[0].String() {
+		case EventTopicHashCreate:
+			e, err := p.createByEvent(state, tx, idx, log)
+			accept(devents.OperateCreate, e, err)
+
+		case EventTopicHashTransfer:
+			e, err := p.transferByEvent(state, idx, log)
+			accept(devents.OperateTransfer, e, err)
+		}
+	}
+
+	if len(results) < 1 && firstErr != nil {
+		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(firstErr)
+	}
+	return results, nil
+}
+
+// ParseMetaData
+/***************************************
+ * data uri calldata, 32-byte id calldata or ethscriptions events.
+ * the cache is not checked, ethscriptions may be created & transferred in the same block
+ ***************************************/
+func (p *Protocol) ParseMetaData(chain string, tx *xycommon.RpcTransaction) *devents.MetaData {
+	md := &devents.MetaData{
+		Chain:    chain,
+		Protocol: types.EthscriptionsProtocol,
+	}
+
+	if content, ok := calldataContent(tx.Input); ok && strings.HasPrefix(content, dataURIPrefix) {
+		md.Operate = devents.OperateCreate
+		return md
+	}
+
+	if IsTransferCalldata(tx.Input) {
+		md.Operate = devents.OperateTransfer
+		return md
+	}
+
+	for _, log := range tx.Events {
+		if len(log.Topics) < 1 {
+			continue
+		}
+
+		switch log.Topics[0].String() {
+		case EventTopicHashCreate:
+			md.Operate = devents.OperateCreate
+			return md
+		case EventTopicHashTransfer:
+			md.Operate = devents.OperateTransfer
+			return md
+		}
+	}
+	return nil
+}
+
+// txState ethscriptions changed by the previous ops of the tx, not synced into the cache yet
+type txState struct {
+	cache  *dcache.Manager
+	owners map[string]string
+	shas   map[string]struct{}
+}
+
+func newTxState(cache *dcache.Manager) *txState {
+	return &txState{
+		cache:  cache,
+		owners: make(map[string]string, 1),
+		shas:   make(map[string]struct{}, 1),
+	}
+}
+
+func (s *txState) owner(id string) (string, bool) {
+	if owner, ok := s.owners[strings.ToLower(id)]; ok {
+		return owner, true
+	}
+
+	ok, item := s.cache.Ethscription.Get(id)
+	if !ok {
+		return "", false
+	}
+	return item.Owner, true
+}
+
+func (s *txState) contentExists(sha string) bool {
+	if _, ok := s.shas[sha]; ok {
+		return true
+	}
+	return s.cache.Ethscription.ContentExists(sha)
+}
+
+func (s *txState) apply(operate string, e *devents.Ethscription) {
+	if operate == devents.OperateCreate {
+		s.shas[e.ContentSha] = struct{}{}
+	}
+	s.owners[strings.ToLower(e.Id)] = e.To
+}
+
+func init() {
+	types.Register(&types.Registration{
+		Name:        types.EthscriptionsProtocol,
+		ChainGroups: []model.ChainGroup{model.EvmChainGroup},
+		New: func(cache *dcache.Manager) types.IProtocol {
+			return NewProtocol(cache)
+		},
+	})
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package ethscriptions
+
+import (
+	"encoding/hex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"testing"
+)
+
+func init() {
+	xylog.InitLog(logrus.DebugLevel, "")
+}
+
+const (
+	alice    = "0x00000000000000000000000000000000000000a1"
+	bob      = "0x00000000000000000000000000000000000000b0"
+	contract = "0x00000000000000000000000000000000000000c0"
+
+	createHash = "0x1111111111111111111111111111111111111111111111111111111111111111"
+)
+
+func calldata(content string) string {
+	return "0x" + hex.EncodeToString([]byte(content))
+}
+
+func parseAndApply(t *testing.T, p *Protocol, handler *devents.TxResultHandler, tx *xycommon.RpcTransaction) []*devents.TxResult {
+	md := p.ParseMetaData(model.ChainAVAX, tx)
+	if md == nil {
+		t.Fatalf("tx[%s] metadata nil", tx.Hash)
+	}
+
+	results, err := p.Parse(&xycommon.RpcBlock{Number: big.NewInt(1), Time: 1}, tx, md)
+	if err != nil {
+		t.Fatalf("parse tx[%s] err:%v", tx.Hash, err)
+	}
+	for _, result := range results {
+		handler.UpdateCache(result)
+	}
+	return results
+}
+
+func transferLog(from, to, id string) xycommon.RpcLog {
+	return xycommon.RpcLog{
+		Address: common.HexToAddress(from),
+		Topics: []common.Hash{
+			common.HexToHash(EventTopicHashTransfer),
+			common.BytesToHash(common.HexToAddress(to).Bytes()),
+			common.HexToHash(id),
+		},
+	}
+}
+
+func TestParseDataURI(t *testing.T) {
+	tests := []struct {
+		uri      string
+		mimetype string
+		valid    bool
+	}{
+		{uri: "data:,hello", mimetype: "text/plain", valid: true},
+		{uri: "data:image/png;base64,iVBORw0KGgo=", mimetype: "image/png", valid: true},
+		{uri: "data:text/plain;charset=utf-8,hi", mimetype: "text/plain", valid: true},
+		{uri: "data:image/png;base64,!!", valid: false},
+		{uri: "data:hello", valid: false},
+		{uri: "data:png,hello", valid: false},
+		{uri: "hello", valid: false},
+	}
+	for _, tt := range tests {
+		mimetype, ok := parseDataURI(tt.uri)
+		assert.Equal(t, tt.valid, ok, tt.uri)
+		assert.Equal(t, tt.mimetype, mimetype, tt.uri)
+	}
+}
+
+func TestCalldataCreateAndTransfer(t *testing.T) {
+	cache := dcache.NewManager(nil, model.ChainAVAX)
+	cache.Ethscription = dcache.NewEthscription()
+	p := NewProtocol(cache)
+	handler := devents.NewTxResultHandler(cache)
+
+	results := parseAndApply(t, p, handler, &xycommon.RpcTransaction{Hash: createHash, From: alice, To: alice, Input: calldata("data:,hello")})
+	assert.Equal(t, devents.OperateCreate, results[0].MD.Operate)
+	assert.Equal(t, types.EthscriptionsProtocol, results[0].MD.Protocol)
+	assert.Equal(t, "text/plain", results[0].Ethscription.Mimetype)
+
+	// duplicated content
+	dup := &xycommon.RpcTransaction{Hash: "0x22", From: bob, To: bob, Input: calldata("data:,hello")}
+	_, err := p.Parse(&xycommon.RpcBlock{Number: big.NewInt(1)}, dup, p.ParseMetaData(model.ChainAVAX, dup))
+	assert.NotNil(t, err)
+
+	// only the owner can transfer
+	steal := &xycommon.RpcTransaction{Hash: "0x33", From: bob, To: bob, Input: createHash}
+	_, err = p.Parse(&xycommon.RpcBlock{Number: big.NewInt(1)}, steal, p.ParseMetaData(model.ChainAVAX, steal))
+	assert.NotNil(t, err)
+
+	results = parseAndApply(t, p, handler, &xycommon.RpcTransaction{Hash: "0x44", From: alice, To: contract, Input: createHash})
+	assert.Equal(t, devents.OperateTransfer, results[0].MD.Operate)
+	_, item := cache.Ethscription.Get(createHash)
+	assert.Equal(t, contract, item.Owner)
+
+	// ESIP-1, transferred by the contract owning it
+	results = parseAndApply(t, p, handler, &xycommon.RpcTransaction{Hash: "0x55", From: bob, To: contract, Input: "0x",
+		Events: []xycommon.RpcLog{transferLog(contract, bob, createHash)}})
+	assert.Equal(t, contract, results[0].Ethscription.From)
+	_, item = cache.Ethscription.Get(createHash)
+	assert.Equal(t, bob, item.Owner)
+
+	// unknown ids are not ethscription transfers
+	unknown := &xycommon.RpcTransaction{Hash: "0x66", From: bob, To: alice, Input: "0x" + hex.EncodeToString(make([]byte, 32))}
+	results, err = p.Parse(&xycommon.RpcBlock{Number: big.NewInt(1)}, unknown, p.ParseMetaData(model.ChainAVAX, unknown))
+	assert.Nil(t, err)
+	assert.Len(t, results, 0)
+}
+
+func TestEventCreateAndTransferInOneTx(t *testing.T) {
+	cache := dcache.NewManager(nil, model.ChainAVAX)
+	cache.Ethscription = dcache.NewEthscription()
+	p := NewProtocol(cache)
+	handler := devents.NewTxResultHandler(cache)
+
+	data, err := stringArguments.Pack("data:,minted by contract")
+	assert.Nil(t, err)
+
+	// ESIP-3 created to the contract, then transferred by it
+	tx := &xycommon.RpcTransaction{Hash: createHash, From: alice, To: contract, Input: "0x", Events: []xycommon.RpcLog{
+		{
+			Address: common.HexToAddress(contract),
+			Topics:  []common.Hash{common.HexToHash(EventTopicHashCreate), common.BytesToHash(common.HexToAddress(contract).Bytes())},
+			Data:    data,
+		},
+		transferLog(contract, alice, createHash),
+	}}
+	results := parseAndApply(t, p, handler, tx)
+	assert.Len(t, results, 2)
+	assert.Equal(t, contract, results[0].Ethscription.From)
+	assert.Equal(t, "data:,minted by contract", results[0].Ethscription.ContentUri)
+	assert.Equal(t, int64(1), results[1].Ethscription.LogIndex)
+
+	_, item := cache.Ethscription.Get(createHash)
+	assert.Equal(t, alice, item.Owner)
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package ethscriptions
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/xyerrors"
+	"strings"
+)
+
+const (
+	// EventTopicHashTransfer ESIP-1 ethscriptions_protocol_TransferEthscription(index_topic_1 address recipient, index_topic_2 bytes32 ethscriptionId)
+	EventTopicHashTransfer = "0xf30861289185032f511ff94a8127e470f3d0e6230be4925cb6fad33f3436dffb"
+
+	// "0x" + 32 bytes hex
+	transferCalldataLength = 66
+)
+
+// IsTransferCalldata whether the tx input may be an ethscription id
+func IsTransferCalldata(input string) bool {
+	if len(input) != transferCalldataLength || !strings.HasPrefix(input, "0x") {
+		return false
+	}
+	_, err := hex.DecodeString(input[2:])
+	return err == nil
+}
+
+// transferByCalldata transferred to the tx receiver by the owner. unknown ids are not ethscription ops
+func (p *Protocol) transferByCalldata(state *txState, tx *xycommon.RpcTransaction) (*devents.Ethscription, *xyerrors.InsError) {
+	id := strings.ToLower(tx.Input)
+	owner, ok := state.owner(id)
+	if !ok {
+		return nil, nil
+	}
+
+	if tx.To == "" {
+		return nil, xyerrors.NewInsError(-12, "contract creation tx")
+	}
+	return p.transfer(id, owner, tx.From, tx.To, -1)
+}
+
+// transferByEvent ESIP-1, transferred by the contract owning the ethscription
+func (p *Protocol) transferByEvent(state *txState, idx int, log xycommon.RpcLog) (*devents.Ethscription, *xyerrors.InsError) {
+	if len(log.Topics) != 3 {
+		return nil, xyerrors.NewInsError(-13, "transfer event topics invalid")
+	}
+
+	id := strings.ToLower(log.Topics[2].Hex())
+	owner, ok := state.owner(id)
+	if !ok {
+		return nil, xyerrors.NewInsError(-15, fmt.Sprintf("ethscription[%s] not exist", id))
+	}
+
+	sender := strings.ToLower(log.Address.Hex())
+	receiver := strings.ToLower(common.BytesToAddress(log.Topics[1].Bytes()).Hex())
+	return p.transfer(id, owner, sender, receiver, logIndex(idx, log))
+}
+
+func (p *Protocol) transfer(id, owner, sender, receiver string, logIdx int64) (*devents.Ethscription, *xyerrors.InsError) {
+	if !strings.EqualFold(owner, sender) {
+		return nil, xyerrors.NewInsError(-17, fmt.Sprintf("sender[%s] is not the owner[%s] of ethscription[%s]", sender, owner, id))
+	}
+
+	return &devents.Ethscription{
+		Id:       id,
+		From:     sender,
+		To:       receiver,
+		LogIndex: logIdx,
+	}, nil
+}
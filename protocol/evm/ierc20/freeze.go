@@ -0,0 +1,175 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package ierc20
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xyerrors"
+	"strings"
+)
+
+type FreezeSell struct {
+	Platform string          `json:"platform"`
+	Amount   decimal.Decimal `json:"amt"`
+}
+
+type Unfreeze struct {
+	Freeze string `json:"freeze"` // hash of the freeze_sell tx
+	To     string `json:"to"`     // the buyer, proxy_transfer only
+}
+
+// FreezeSell
+/***************************************
+ * lock the seller's available balance into a utxo keyed by the tx hash,
+ * the platform is allowed to spend it besides the seller
+ ***************************************/
+func (p *Protocol) FreezeSell(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	rules := types.RulesAt(md.Protocol, md.Chain, block.Number.Uint64())
+	fs, err := p.verifyFreezeSell(rules, tx, md)
+	if err != nil {
+		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
+	}
+	result := &devents.TxResult{
+		MD:    md,
+		Block: block,
+		Tx:    tx,
+		Inscribe: &devents.Inscribe{
+			Owner:   tx.From,
+			Amount:  fs.Amount,
+			Sn:      tx.Hash,
+			Spender: fs.Platform,
+		},
+	}
+	return []*devents.TxResult{result}, nil
+}
+
+func (p *Protocol) verifyFreezeSell(rules *types.Rules, tx *xycommon.RpcTransaction, md *devents.MetaData) (*FreezeSell, *xyerrors.InsError) {
+	fs := &FreezeSell{}
+	err := json.Unmarshal([]byte(md.Data), fs)
+	if err != nil {
+		return nil, xyerrors.NewInsError(-13, fmt.Sprintf("data json deocde err:%v, data[%s]", err, md.Data))
+	}
+
+	if !common.IsHexAddress(fs.Platform) {
+		return nil, xyerrors.NewInsError(-14, fmt.Sprintf("freeze platform[%s] invalid", fs.Platform))
+	}
+	fs.Platform = strings.ToLower(fs.Platform)
+
+	if fs.Amount.LessThanOrEqual(decimal.Zero) {
+		return nil, xyerrors.NewInsError(-14, "freeze amount <= 0")
+	}
+
+	decimals, insErr := p.tickDecimals(md)
+	if insErr != nil {
+		return nil, insErr
+	}
+
+	// freeze amount precision checking
+	amount, insErr := p.Protocol.CheckAmount(rules, fs.Amount, decimals)
+	if insErr != nil {
+		return nil, insErr
+	}
+	fs.Amount = amount
+
+	if insErr = p.verifyAvailable(md, tx.From, fs.Amount); insErr != nil {
+		return nil, insErr
+	}
+	return fs, nil
+}
+
+// ProxyTransfer
+/***************************************
+ * the platform moves the whole frozen amount from the seller to the buyer,
+ * e.g. {"p":"ierc-20","op":"proxy_transfer","tick":"ethpi","freeze":"0x..","to":"0x.."}
+ ***************************************/
+func (p *Protocol) ProxyTransfer(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	uf, item, err := p.verifyUnfreeze(tx, md)
+	if err != nil {
+		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
+	}
+
+	// only the platform sells the frozen balance
+	if !strings.EqualFold(tx.From, item.Spender) {
+		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(ErrFreezeNotSpendable)
+	}
+
+	if !common.IsHexAddress(uf.To) {
+		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(xyerrors.NewInsError(-14, fmt.Sprintf("proxy transfer receiver[%s] invalid", uf.To)))
+	}
+	return p.spendFreeze(block, tx, md, uf.Freeze, item, strings.ToLower(uf.To)), nil
+}
+
+// UnfreezeSell return the frozen amount to the seller, sent by the seller or the platform
+func (p *Protocol) UnfreezeSell(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	uf, item, err := p.verifyUnfreeze(tx, md)
+	if err != nil {
+		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
+	}
+
+	if !strings.EqualFold(tx.From, item.Owner) && !strings.EqualFold(tx.From, item.Spender) {
+		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(ErrFreezeNotSpendable)
+	}
+	return p.spendFreeze(block, tx, md, uf.Freeze, item, item.Owner), nil
+}
+
+func (p *Protocol) verifyUnfreeze(tx *xycommon.RpcTransaction, md *devents.MetaData) (*Unfreeze, *dcache.UTXOItem, *xyerrors.InsError) {
+	uf := &Unfreeze{}
+	err := json.Unmarshal([]byte(md.Data), uf)
+	if err != nil {
+		return nil, nil, xyerrors.NewInsError(-13, fmt.Sprintf("data json deocde err:%v, data[%s]", err, md.Data))
+	}
+	uf.Freeze = strings.ToLower(strings.TrimSpace(uf.Freeze))
+
+	ok, item := p.cache.UTXO.Get(uf.Freeze)
+	if !ok || item.Protocol != md.Protocol || item.Tick != md.Tick {
+		return nil, nil, ErrFreezeNotExist
+	}
+	return uf, item, nil
+}
+
+// spendFreeze the owner's overall balance is moved, the available one has been locked at freezing
+func (p *Protocol) spendFreeze(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData, freeze string, item *dcache.UTXOItem, receiver string) []*devents.TxResult {
+	result := &devents.TxResult{
+		MD:    md,
+		Block: block,
+		Tx:    tx,
+		Transfer: &devents.Transfer{
+			Sender: item.Owner,
+			Receives: []*devents.Receive{
+				{
+					Address: receiver,
+					Amount:  item.Amount,
+				},
+			},
+			UTXO: freeze,
+		},
+	}
+	return []*devents.TxResult{result}
+}
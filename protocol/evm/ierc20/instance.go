@@ -0,0 +1,187 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package ierc20
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xyerrors"
+	"strings"
+)
+
+var (
+	ErrWorkcInvalid          = xyerrors.NewInsError(-40, "workc invalid")
+	ErrNonceMissing          = xyerrors.NewInsError(-41, "nonce missing")
+	ErrHashPrefixMismatch    = xyerrors.NewInsError(-42, "tx hash does not match the workc prefix")
+	ErrFreezeNotExist        = xyerrors.NewInsError(-43, "freeze order not exist")
+	ErrFreezeNotSpendable    = xyerrors.NewInsError(-44, "sender is not allowed to spend the freeze order")
+	ErrAvailableInsufficient = xyerrors.NewInsError(-45, "available balance insufficient")
+)
+
+// maxWorkcLength 0x prefixed 32 bytes hash
+const maxWorkcLength = 66
+
+// Protocol
+/*****************************************************
+ * ierc-20 proof-of-work tokens:
+ * 1. mints are sent to the zero address, the tx hash must start with the workc of the deploy
+ * 2. freeze_sell locks the seller's available balance for a platform,
+ *    which moves it to the buyer by proxy_transfer, or returns it by unfreeze_sell
+ ****************************************************/
+type Protocol struct {
+	*common.Protocol
+	cache *dcache.Manager
+}
+
+func NewProtocol(cache *dcache.Manager) *Protocol {
+	return &Protocol{
+		Protocol: common.NewProtocol(cache),
+		cache:    cache,
+	}
+}
+
+type Deploy struct {
+	Workc string `json:"workc"`
+}
+
+type Mint struct {
+	Nonce json.Number `json:"nonce"`
+}
+
+func (p *Protocol) Parse(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	switch md.Operate {
+	case devents.OperateDeploy:
+		return p.Deploy(block, tx, md)
+	case devents.OperateMint:
+		return p.Mint(block, tx, md)
+	case devents.OperateTransfer:
+		return p.Transfer(block, tx, md)
+	case devents.OperateFreezeSell:
+		return p.FreezeSell(block, tx, md)
+	case devents.OperateUnfreezeSell:
+		return p.UnfreezeSell(block, tx, md)
+	case devents.OperateProxyTransfer:
+		return p.ProxyTransfer(block, tx, md)
+	}
+	return nil, nil
+}
+
+func (p *Protocol) Deploy(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	results, err := p.Protocol.Deploy(block, tx, md)
+	if err != nil {
+		return nil, err
+	}
+
+	workc, err := parseWorkc(md)
+	if err != nil {
+		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
+	}
+
+	for _, result := range results {
+		result.Deploy.TransferType = model.TransferTypeHash
+		result.Deploy.Workc = workc
+	}
+	return results, nil
+}
+
+// parseWorkc the optional 0x prefixed hex difficulty, no pow checking if empty
+func parseWorkc(md *devents.MetaData) (string, *xyerrors.InsError) {
+	d := &Deploy{}
+	if err := json.Unmarshal([]byte(md.Data), d); err != nil {
+		return "", xyerrors.NewInsError(-13, fmt.Sprintf("json decode err:%v", err))
+	}
+
+	workc := strings.ToLower(strings.TrimSpace(d.Workc))
+	if workc == "" {
+		return "", nil
+	}
+
+	if !strings.HasPrefix(workc, "0x") || len(workc) > maxWorkcLength {
+		return "", ErrWorkcInvalid
+	}
+
+	// odd length prefixes are allowed, e.g. 0x000
+	digits := workc[2:]
+	if len(digits)%2 == 1 {
+		digits += "0"
+	}
+	if _, err := hex.DecodeString(digits); err != nil {
+		return "", ErrWorkcInvalid
+	}
+	return workc, nil
+}
+
+func (p *Protocol) Mint(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	if err := p.verifyWork(tx, md); err != nil {
+		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
+	}
+	return p.Protocol.Mint(block, tx, md)
+}
+
+// verifyWork the mint tx hash must start with the workc declared at deploy
+func (p *Protocol) verifyWork(tx *xycommon.RpcTransaction, md *devents.MetaData) *xyerrors.InsError {
+	m := &Mint{}
+	if err := json.Unmarshal([]byte(md.Data), m); err != nil {
+		return xyerrors.NewInsError(-13, fmt.Sprintf("data json deocde err:%v, data[%s]", err, md.Data))
+	}
+
+	if m.Nonce.String() == "" {
+		return ErrNonceMissing
+	}
+
+	ok, inscription := p.cache.Inscription.Get(md.Protocol, md.Tick)
+	if !ok || inscription == nil {
+		return xyerrors.NewInsError(-15, fmt.Sprintf("inscription not exist, protocol[%s], tick[%s]", md.Protocol, md.Tick))
+	}
+
+	if inscription.Workc != "" && !strings.HasPrefix(strings.ToLower(tx.Hash), inscription.Workc) {
+		return ErrHashPrefixMismatch
+	}
+	return nil
+}
+
+func init() {
+	rules := types.DefaultRules()
+
+	// mints are sent to the zero address
+	rules.Minter = types.MinterFrom
+
+	// multi-receiver transfers exceed the default limit
+	rules.MaxDataLength = 1024
+
+	types.Register(&types.Registration{
+		Name:        types.IERC20Protocol,
+		ChainGroups: []model.ChainGroup{model.EvmChainGroup},
+		Rules:       &rules,
+		New: func(cache *dcache.Manager) types.IProtocol {
+			return NewProtocol(cache)
+		},
+	})
+}
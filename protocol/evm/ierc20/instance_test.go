@@ -0,0 +1,233 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package ierc20
+
+import (
+	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xyerrors"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"testing"
+)
+
+func init() {
+	xylog.InitLog(logrus.DebugLevel, "")
+}
+
+const (
+	zero     = "0x0000000000000000000000000000000000000000"
+	seller   = "0x00000000000000000000000000000000000000a1"
+	buyer    = "0x00000000000000000000000000000000000000b0"
+	platform = "0x00000000000000000000000000000000000000c0"
+
+	freezeHash = "0x1111111111111111111111111111111111111111111111111111111111111111"
+)
+
+func newTestCache() *dcache.Manager {
+	cache := dcache.NewManager(nil, "eth")
+	cache.Balance = dcache.NewBalance()
+	cache.UTXO = dcache.NewUTXO()
+	cache.Inscription = dcache.NewInscription()
+	cache.InscriptionStats = dcache.NewInscriptionStats()
+	return cache
+}
+
+func parse(p *Protocol, handler *devents.TxResultHandler, tx *xycommon.RpcTransaction, op, data string) ([]*devents.TxResult, *xyerrors.InsError) {
+	md := &devents.MetaData{
+		Chain:    "eth",
+		Protocol: types.IERC20Protocol,
+		Operate:  op,
+		Tick:     "ethpi",
+		Data:     data,
+	}
+	results, err := p.Parse(&xycommon.RpcBlock{Number: big.NewInt(1), Time: 1}, tx, md)
+	for _, result := range results {
+		handler.UpdateCache(result)
+	}
+	return results, err
+}
+
+func causeOf(err *xyerrors.InsError) error {
+	if err == nil {
+		return nil
+	}
+	return err.Cause(nil)
+}
+
+func assertBalance(t *testing.T, cache *dcache.Manager, address string, available, overall int64) {
+	ok, balance := cache.Balance.Get(types.IERC20Protocol, "ethpi", address)
+	assert.True(t, ok, address)
+	assert.True(t, balance.Available.Equal(decimal.NewFromInt(available)), "%s available %v", address, balance.Available)
+	assert.True(t, balance.Overall.Equal(decimal.NewFromInt(overall)), "%s overall %v", address, balance.Overall)
+}
+
+func deployAndMint(t *testing.T) (*Protocol, *dcache.Manager, *devents.TxResultHandler) {
+	cache := newTestCache()
+	p := NewProtocol(cache)
+	handler := devents.NewTxResultHandler(cache)
+
+	deploy := &xycommon.RpcTransaction{Hash: "0xd1", From: seller, To: zero}
+	_, err := parse(p, handler, deploy, devents.OperateDeploy, `{"p":"ierc-20","op":"deploy","tick":"ethpi","max":"21000000","lim":"1000","workc":"0x00","nonce":"1"}`)
+	assert.Nil(t, err)
+
+	ok, tick := cache.Inscription.Get(types.IERC20Protocol, "ethpi")
+	assert.True(t, ok)
+	assert.Equal(t, "0x00", tick.Workc)
+	assert.Equal(t, int8(model.TransferTypeHash), tick.TransferType)
+
+	mint := &xycommon.RpcTransaction{Hash: "0x00ab", From: seller, To: zero}
+	_, err = parse(p, handler, mint, devents.OperateMint, `{"p":"ierc-20","op":"mint","tick":"ethpi","amt":"1000","nonce":"7"}`)
+	assert.Nil(t, err)
+	assertBalance(t, cache, seller, 1000, 1000)
+	return p, cache, handler
+}
+
+func TestProtocol_Deploy(t *testing.T) {
+	cache := newTestCache()
+	p := NewProtocol(cache)
+	handler := devents.NewTxResultHandler(cache)
+
+	cases := []string{
+		`{"p":"ierc-20","op":"deploy","tick":"ethpi","max":"21000000","lim":"1000","workc":"0000"}`,
+		`{"p":"ierc-20","op":"deploy","tick":"ethpi","max":"21000000","lim":"1000","workc":"0xzz"}`,
+		`{"p":"ierc-20","op":"deploy","tick":"ethpi","max":"21000000","lim":"1000","workc":"0x00000000000000000000000000000000000000000000000000000000000000000"}`,
+	}
+	for _, data := range cases {
+		tx := &xycommon.RpcTransaction{Hash: "0xd1", From: seller, To: zero}
+		_, err := parse(p, handler, tx, devents.OperateDeploy, data)
+		assert.True(t, errors.Is(causeOf(err), ErrWorkcInvalid), data)
+	}
+
+	// odd length workc
+	tx := &xycommon.RpcTransaction{Hash: "0xd1", From: seller, To: zero}
+	_, err := parse(p, handler, tx, devents.OperateDeploy, `{"p":"ierc-20","op":"deploy","tick":"ethpi","max":"21000000","lim":"1000","workc":"0x000"}`)
+	assert.Nil(t, err)
+}
+
+func TestProtocol_Mint(t *testing.T) {
+	p, cache, handler := deployAndMint(t)
+
+	cases := []struct {
+		name string
+		hash string
+		data string
+		err  error
+	}{
+		{
+			name: "nonce missing",
+			hash: "0x00cd",
+			data: `{"p":"ierc-20","op":"mint","tick":"ethpi","amt":"1000"}`,
+			err:  ErrNonceMissing,
+		},
+		{
+			name: "hash prefix mismatch",
+			hash: "0x01cd",
+			data: `{"p":"ierc-20","op":"mint","tick":"ethpi","amt":"1000","nonce":"8"}`,
+			err:  ErrHashPrefixMismatch,
+		},
+		{
+			name: "limit exceeded",
+			hash: "0x00cd",
+			data: `{"p":"ierc-20","op":"mint","tick":"ethpi","amt":"1001","nonce":"8"}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tx := &xycommon.RpcTransaction{Hash: c.hash, From: seller, To: zero}
+			_, err := parse(p, handler, tx, devents.OperateMint, c.data)
+			assert.NotNil(t, err)
+			if c.err != nil {
+				assert.True(t, errors.Is(causeOf(err), c.err), err)
+			}
+		})
+	}
+	assertBalance(t, cache, seller, 1000, 1000)
+
+	// upper case tx hash & numeric nonce
+	tx := &xycommon.RpcTransaction{Hash: "0x00CD", From: seller, To: zero}
+	_, err := parse(p, handler, tx, devents.OperateMint, `{"p":"ierc-20","op":"mint","tick":"ethpi","amt":"10","nonce":8}`)
+	assert.Nil(t, err)
+	assertBalance(t, cache, seller, 1010, 1010)
+}
+
+func TestProtocol_FreezeAndProxyTransfer(t *testing.T) {
+	p, cache, handler := deployAndMint(t)
+
+	freeze := &xycommon.RpcTransaction{Hash: freezeHash, From: seller, To: zero}
+	_, err := parse(p, handler, freeze, devents.OperateFreezeSell, `{"p":"ierc-20","op":"freeze_sell","tick":"ethpi","platform":"`+platform+`","amt":"600"}`)
+	assert.Nil(t, err)
+	assertBalance(t, cache, seller, 400, 1000)
+
+	// the frozen balance is not transferable
+	tf := &xycommon.RpcTransaction{Hash: "0xe1", From: seller, To: zero}
+	_, err = parse(p, handler, tf, devents.OperateTransfer, `{"p":"ierc-20","op":"transfer","tick":"ethpi","to":[{"recv":"`+buyer+`","amt":"300"},{"recv":"`+platform+`","amt":"200"}]}`)
+	assert.True(t, errors.Is(causeOf(err), ErrAvailableInsufficient), err)
+
+	// only the platform sells
+	proxy := `{"p":"ierc-20","op":"proxy_transfer","tick":"ethpi","freeze":"` + freezeHash + `","to":"` + buyer + `"}`
+	_, err = parse(p, handler, &xycommon.RpcTransaction{Hash: "0xe2", From: buyer, To: zero}, devents.OperateProxyTransfer, proxy)
+	assert.True(t, errors.Is(causeOf(err), ErrFreezeNotSpendable), err)
+
+	results, err := parse(p, handler, &xycommon.RpcTransaction{Hash: "0xe3", From: platform, To: zero}, devents.OperateProxyTransfer, proxy)
+	assert.Nil(t, err)
+	assert.Equal(t, freezeHash, results[0].Transfer.UTXO)
+	assertBalance(t, cache, seller, 400, 400)
+	assertBalance(t, cache, buyer, 600, 600)
+
+	// spent freeze orders can not be sold twice
+	_, err = parse(p, handler, &xycommon.RpcTransaction{Hash: "0xe4", From: platform, To: zero}, devents.OperateProxyTransfer, proxy)
+	assert.True(t, errors.Is(causeOf(err), ErrFreezeNotExist), err)
+
+	_, err = parse(p, handler, tf, devents.OperateTransfer, `{"p":"ierc-20","op":"transfer","tick":"ethpi","to":[{"recv":"`+buyer+`","amt":"300"},{"recv":"`+platform+`","amt":"100"}]}`)
+	assert.Nil(t, err)
+	assertBalance(t, cache, seller, 0, 0)
+	assertBalance(t, cache, buyer, 900, 900)
+	assertBalance(t, cache, platform, 100, 100)
+}
+
+func TestProtocol_UnfreezeSell(t *testing.T) {
+	p, cache, handler := deployAndMint(t)
+
+	freeze := &xycommon.RpcTransaction{Hash: freezeHash, From: seller, To: zero}
+	_, err := parse(p, handler, freeze, devents.OperateFreezeSell, `{"p":"ierc-20","op":"freeze_sell","tick":"ethpi","platform":"`+platform+`","amt":"600"}`)
+	assert.Nil(t, err)
+
+	unfreeze := `{"p":"ierc-20","op":"unfreeze_sell","tick":"ethpi","freeze":"` + freezeHash + `"}`
+	_, err = parse(p, handler, &xycommon.RpcTransaction{Hash: "0xe1", From: buyer, To: zero}, devents.OperateUnfreezeSell, unfreeze)
+	assert.True(t, errors.Is(causeOf(err), ErrFreezeNotSpendable), err)
+
+	_, err = parse(p, handler, &xycommon.RpcTransaction{Hash: "0xe2", From: seller, To: zero}, devents.OperateUnfreezeSell, unfreeze)
+	assert.Nil(t, err)
+	assertBalance(t, cache, seller, 1000, 1000)
+
+	ok, _ := cache.UTXO.Get(freezeHash)
+	assert.False(t, ok)
+}
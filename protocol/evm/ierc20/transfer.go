@@ -0,0 +1,139 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package ierc20
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xyerrors"
+	"strings"
+)
+
+type Receive struct {
+	Address string          `json:"recv"`
+	Amount  decimal.Decimal `json:"amt"`
+}
+
+type Transfer struct {
+	Receives []*Receive `json:"to"`
+}
+
+// Transfer
+/***************************************
+ * move the sender's available balance to the receivers,
+ * e.g. {"p":"ierc-20","op":"transfer","tick":"ethpi","to":[{"recv":"0x..","amt":"10"}]}
+ ***************************************/
+func (p *Protocol) Transfer(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	rules := types.RulesAt(md.Protocol, md.Chain, block.Number.Uint64())
+	tf, err := p.verifyTransfer(rules, tx, md)
+	if err != nil {
+		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
+	}
+
+	receives := make([]*devents.Receive, 0, len(tf.Receives))
+	for _, item := range tf.Receives {
+		receives = append(receives, &devents.Receive{
+			Address: item.Address,
+			Amount:  item.Amount,
+		})
+	}
+	result := &devents.TxResult{
+		MD:    md,
+		Block: block,
+		Tx:    tx,
+		Transfer: &devents.Transfer{
+			Sender:   tx.From,
+			Receives: receives,
+		},
+	}
+	return []*devents.TxResult{result}, nil
+}
+
+func (p *Protocol) verifyTransfer(rules *types.Rules, tx *xycommon.RpcTransaction, md *devents.MetaData) (*Transfer, *xyerrors.InsError) {
+	tf := &Transfer{}
+	err := json.Unmarshal([]byte(md.Data), tf)
+	if err != nil {
+		return nil, xyerrors.NewInsError(-13, fmt.Sprintf("data json deocde err:%v, data[%s]", err, md.Data))
+	}
+
+	if len(tf.Receives) < 1 {
+		return nil, xyerrors.NewInsError(-14, "transfer receivers empty")
+	}
+
+	decimals, insErr := p.tickDecimals(md)
+	if insErr != nil {
+		return nil, insErr
+	}
+
+	total := decimal.Zero
+	for _, item := range tf.Receives {
+		if !common.IsHexAddress(item.Address) {
+			return nil, xyerrors.NewInsError(-14, fmt.Sprintf("transfer receiver[%s] invalid", item.Address))
+		}
+		item.Address = strings.ToLower(item.Address)
+
+		if item.Amount.LessThanOrEqual(decimal.Zero) {
+			return nil, xyerrors.NewInsError(-14, "transfer amount <= 0")
+		}
+
+		// transfer amount precision checking
+		amount, insErr := p.Protocol.CheckAmount(rules, item.Amount, decimals)
+		if insErr != nil {
+			return nil, insErr
+		}
+		item.Amount = amount
+		total = total.Add(amount)
+	}
+
+	if insErr = p.verifyAvailable(md, tx.From, total); insErr != nil {
+		return nil, insErr
+	}
+	return tf, nil
+}
+
+// tickDecimals decimals of the deployed tick
+func (p *Protocol) tickDecimals(md *devents.MetaData) (int8, *xyerrors.InsError) {
+	ok, inscription := p.cache.Inscription.Get(md.Protocol, md.Tick)
+	if !ok || inscription == nil {
+		return 0, xyerrors.NewInsError(-15, fmt.Sprintf("inscription not exist, protocol[%s]-tick[%s]", md.Protocol, md.Tick))
+	}
+	return inscription.Decimals, nil
+}
+
+// verifyAvailable the frozen balance of the address can not be transferred
+func (p *Protocol) verifyAvailable(md *devents.MetaData, address string, amount decimal.Decimal) *xyerrors.InsError {
+	ok, balance := p.cache.Balance.Get(md.Protocol, md.Tick, address)
+	if !ok {
+		return xyerrors.NewInsError(-16, fmt.Sprintf("sender balance record not exist, tick[%s-%s], address[%s]", md.Protocol, md.Tick, address))
+	}
+
+	if balance.Available.LessThan(amount) {
+		return ErrAvailableInsufficient
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package market
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/devents"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var eventSignatureRegexp = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*\((.*)\)\s*$`)
+
+// Market a configured marketplace event
+type Market struct {
+	Chain     string
+	Protocol  string
+	Operate   string
+	Event     abi.Event
+	Fields    config.MarketFields
+	contracts map[common.Address]struct{}
+}
+
+// Order a transfer decoded from the market event log
+type Order struct {
+	From   string
+	To     string
+	Tick   string // tick name, or the keccak256 hash of indexed strings
+	Hashed bool
+	Amount decimal.Decimal
+}
+
+func NewMarket(cfg *config.MarketConfig) (*Market, error) {
+	protocol := strings.ToLower(strings.TrimSpace(cfg.Protocol))
+	if cfg.Chain == "" || protocol == "" {
+		return nil, fmt.Errorf("chain[%s] / protocol[%s] empty", cfg.Chain, cfg.Protocol)
+	}
+
+	event, err := ParseEventSignature(cfg.Event)
+	if err != nil {
+		return nil, err
+	}
+
+	// mapped fields must be event arguments
+	fields := map[string]string{"from": cfg.Fields.From, "to": cfg.Fields.To, "tick": cfg.Fields.Tick, "amount": cfg.Fields.Amount}
+	for key, name := range fields {
+		if (key == "from" || key == "to") && name == config.MarketFieldContract {
+			continue
+		}
+
+		if !hasArgument(event, name) {
+			return nil, fmt.Errorf("field %s[%s] is not an argument of event[%s]", key, name, cfg.Event)
+		}
+	}
+
+	if cfg.Fields.AmountDecimals < 0 {
+		return nil, fmt.Errorf("amount_decimals[%d] invalid", cfg.Fields.AmountDecimals)
+	}
+
+	// any contract may emit the event, it can only move its own balance
+	if len(cfg.Contracts) < 1 && cfg.Fields.From != config.MarketFieldContract {
+		return nil, fmt.Errorf("contracts empty, field from[%s] must be %s to match any contract", cfg.Fields.From, config.MarketFieldContract)
+	}
+
+	contracts := make(map[common.Address]struct{}, len(cfg.Contracts))
+	for _, item := range cfg.Contracts {
+		if !common.IsHexAddress(item) {
+			return nil, fmt.Errorf("contract[%s] invalid", item)
+		}
+		contracts[common.HexToAddress(item)] = struct{}{}
+	}
+
+	operate := strings.ToLower(strings.TrimSpace(cfg.Operate))
+	if operate == "" {
+		operate = devents.OperateExchange
+	}
+	return &Market{
+		Chain:     cfg.Chain,
+		Protocol:  protocol,
+		Operate:   operate,
+		Event:     event,
+		Fields:    cfg.Fields,
+		contracts: contracts,
+	}, nil
+}
+
+// Topic the event topic hash
+func (m *Market) Topic() string {
+	return m.Event.ID.String()
+}
+
+// Match whether the log is the market event
+func (m *Market) Match(log *xycommon.RpcLog) bool {
+	if len(log.Topics) < 1 || log.Topics[0] != m.Event.ID {
+		return false
+	}
+
+	if len(m.contracts) < 1 {
+		return true
+	}
+	_, ok := m.contracts[log.Address]
+	return ok
+}
+
+// Decode decode the order of the matched log
+func (m *Market) Decode(log *xycommon.RpcLog) (*Order, error) {
+	indexed := make(abi.Arguments, 0, len(m.Event.Inputs))
+	for _, arg := range m.Event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if len(log.Topics) != len(indexed)+1 {
+		return nil, fmt.Errorf("topics count[%d] mismatched, indexed arguments[%d]", len(log.Topics), len(indexed))
+	}
+
+	values := make(map[string]interface{}, len(m.Event.Inputs))
+	if err := abi.ParseTopicsIntoMap(values, indexed, log.Topics[1:]); err != nil {
+		return nil, fmt.Errorf("topics decode err:%v", err)
+	}
+
+	if err := m.Event.Inputs.NonIndexed().UnpackIntoMap(values, log.Data); err != nil {
+		return nil, fmt.Errorf("data decode err:%v", err)
+	}
+
+	order := &Order{}
+	var err error
+	if order.From, err = m.address(log, values, m.Fields.From); err != nil {
+		return nil, err
+	}
+
+	if order.To, err = m.address(log, values, m.Fields.To); err != nil {
+		return nil, err
+	}
+
+	switch v := values[m.Fields.Tick].(type) {
+	case string:
+		order.Tick = strings.ToLower(strings.TrimSpace(v))
+	case common.Hash:
+		order.Tick, order.Hashed = v.Hex(), true
+	case [32]byte:
+		order.Tick, order.Hashed = common.Hash(v).Hex(), true
+	default:
+		return nil, fmt.Errorf("tick[%s] type %T not supported", m.Fields.Tick, v)
+	}
+
+	amount, ok := values[m.Fields.Amount].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("amount[%s] type %T not supported", m.Fields.Amount, values[m.Fields.Amount])
+	}
+	order.Amount = decimal.NewFromBigInt(amount, -m.Fields.AmountDecimals)
+	return order, nil
+}
+
+func (m *Market) address(log *xycommon.RpcLog, values map[string]interface{}, name string) (string, error) {
+	if name == config.MarketFieldContract {
+		return strings.ToLower(log.Address.Hex()), nil
+	}
+
+	v, ok := values[name].(common.Address)
+	if !ok {
+		return "", fmt.Errorf("address[%s] type %T not supported", name, values[name])
+	}
+	return strings.ToLower(v.Hex()), nil
+}
+
+// ParseEventSignature
+/***************************************
+ * parse the event of a signature with argument names, e.g.
+ * Sold(address indexed seller,address indexed buyer,string tick,uint256 amount)
+ ***************************************/
+func ParseEventSignature(signature string) (abi.Event, error) {
+	matches := eventSignatureRegexp.FindStringSubmatch(signature)
+	if matches == nil {
+		return abi.Event{}, fmt.Errorf("event signature[%s] invalid", signature)
+	}
+
+	name, params := matches[1], strings.TrimSpace(matches[2])
+	args := make(abi.Arguments, 0, 4)
+	if params != "" {
+		for _, param := range strings.Split(params, ",") {
+			parts := strings.Fields(param)
+			if len(parts) != 2 && !(len(parts) == 3 && parts[1] == "indexed") {
+				return abi.Event{}, fmt.Errorf("event argument[%s] invalid, type [indexed] name expected", param)
+			}
+
+			typ, err := abi.NewType(parts[0], "", nil)
+			if err != nil {
+				return abi.Event{}, fmt.Errorf("event argument[%s] type invalid, err:%v", param, err)
+			}
+			args = append(args, abi.Argument{
+				Name:    parts[len(parts)-1],
+				Type:    typ,
+				Indexed: len(parts) == 3,
+			})
+		}
+	}
+	return abi.NewEvent(name, name, false, args), nil
+}
+
+func hasArgument(event abi.Event, name string) bool {
+	for _, arg := range event.Inputs {
+		if arg.Name == name {
+			return true
+		}
+	}
+	return false
+}
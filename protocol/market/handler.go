@@ -0,0 +1,162 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package market
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/xyerrors"
+	"github.com/uxuycom/indexer/xylog"
+)
+
+// Handler
+/*****************************************************
+ * turns the configured marketplace event logs of a chain into
+ * transfers of the market protocol, e.g. the listed amount moved
+ * from the market contract to the buyer
+ ****************************************************/
+type Handler struct {
+	cache   *dcache.Manager
+	markets []*Market
+}
+
+func NewHandler(cache *dcache.Manager, markets []*Market) *Handler {
+	return &Handler{
+		cache:   cache,
+		markets: markets,
+	}
+}
+
+// Topics the event topics to be filtered
+func (h *Handler) Topics() []string {
+	topics := make([]string, 0, len(h.markets))
+	for _, m := range h.markets {
+		topics = append(topics, m.Topic())
+	}
+	return topics
+}
+
+// ParseMetaData metadata of the first market event log of the tx, returns nil if none
+func (h *Handler) ParseMetaData(chain string, tx *xycommon.RpcTransaction) *devents.MetaData {
+	for idx := range tx.Events {
+		for _, m := range h.markets {
+			if m.Chain != chain || !m.Match(&tx.Events[idx]) {
+				continue
+			}
+			return &devents.MetaData{
+				Chain:    chain,
+				Protocol: m.Protocol,
+				Operate:  m.Operate,
+			}
+		}
+	}
+	return nil
+}
+
+func (h *Handler) Parse(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, omd *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	// amounts sent by the earlier logs of the tx, not applied to the cache yet
+	spent := make(map[string]decimal.Decimal, 2)
+
+	items := make([]*devents.TxResult, 0, 1)
+	for idx := range tx.Events {
+		for _, m := range h.markets {
+			log := &tx.Events[idx]
+			if m.Chain != omd.Chain || !m.Match(log) {
+				continue
+			}
+
+			order, err := m.Decode(log)
+			if err != nil {
+				xylog.Logger.Infof("tx[%s] - market event decode err:%v", tx.Hash, err)
+				continue
+			}
+
+			md := omd.Copy()
+			md.Protocol = m.Protocol
+			md.Operate = m.Operate
+			md.Tick = order.Tick
+			if order.Hashed {
+				ok, tick := h.cache.Inscription.GetNameByIdx(order.Tick)
+				if !ok {
+					xylog.Logger.Infof("tx[%s] - market event tick not found, idx[%s]", tx.Hash, order.Tick)
+					continue
+				}
+				md.Tick = tick
+			}
+
+			key := fmt.Sprintf("%s_%s_%s", md.Protocol, md.Tick, order.From)
+			if insErr := h.verify(md, order, spent[key]); insErr != nil {
+				xylog.Logger.Infof("tx[%s] - market order verified failed, err:%v, order:%v", tx.Hash, insErr, order)
+				continue
+			}
+			spent[key] = spent[key].Add(order.Amount)
+
+			items = append(items, &devents.TxResult{
+				MD:    md,
+				Block: block,
+				Tx:    tx,
+				Transfer: &devents.Transfer{
+					Sender: order.From,
+					Receives: []*devents.Receive{
+						{
+							Address: order.To,
+							Amount:  order.Amount,
+						},
+					},
+				},
+			})
+		}
+	}
+	return items, nil
+}
+
+func (h *Handler) verify(md *devents.MetaData, order *Order, spent decimal.Decimal) *xyerrors.InsError {
+	if order.Amount.LessThanOrEqual(decimal.Zero) {
+		return xyerrors.NewInsError(-14, "transfer amount <= 0")
+	}
+
+	ok, inscription := h.cache.Inscription.Get(md.Protocol, md.Tick)
+	if !ok || inscription == nil {
+		return xyerrors.NewInsError(-15, fmt.Sprintf("inscription not exist, protocol[%s]-tick[%s]", md.Protocol, md.Tick))
+	}
+
+	ok, balance := h.cache.Balance.Get(md.Protocol, md.Tick, order.From)
+	if !ok {
+		return xyerrors.NewInsError(-16, fmt.Sprintf("sender balance record not exist, tick[%s-%s], address[%s]", md.Protocol, md.Tick, order.From))
+	}
+
+	// the locked balance of lockable ticks can not be sent
+	left := balance.Overall
+	if inscription.TransferType == model.TransferTypeHash {
+		left = balance.Available
+	}
+
+	if left.Sub(spent).LessThan(order.Amount) {
+		return xyerrors.NewInsError(-17, fmt.Sprintf("sender balance[%v] < transfer amount[%v]", left.Sub(spent), order.Amount))
+	}
+	return nil
+}
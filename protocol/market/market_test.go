@@ -0,0 +1,186 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package market
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"testing"
+)
+
+func init() {
+	xylog.InitLog(logrus.DebugLevel, "")
+}
+
+const (
+	contract = "0x00000000000000000000000000000000000000c0"
+	seller   = "0x00000000000000000000000000000000000000a1"
+	buyer    = "0x00000000000000000000000000000000000000b0"
+
+	soldEvent = "Sold(address indexed seller, address indexed buyer, string indexed tick, uint256 amount)"
+)
+
+func newTestMarket(t *testing.T) *Market {
+	m, err := NewMarket(&config.MarketConfig{
+		Chain:     "avalanche",
+		Protocol:  "ASC-20",
+		Contracts: []string{contract},
+		Event:     soldEvent,
+		Fields: config.MarketFields{
+			From:   config.MarketFieldContract,
+			To:     "buyer",
+			Tick:   "tick",
+			Amount: "amount",
+		},
+	})
+	assert.NoError(t, err)
+	return m
+}
+
+func soldLog(t *testing.T, address, tick string, amount int64) xycommon.RpcLog {
+	data, err := abi.Arguments{{Type: abi.Type{T: abi.UintTy, Size: 256}}}.Pack(big.NewInt(amount))
+	assert.NoError(t, err)
+
+	return xycommon.RpcLog{
+		Address: common.HexToAddress(address),
+		Topics: []common.Hash{
+			crypto.Keccak256Hash([]byte("Sold(address,address,string,uint256)")),
+			common.HexToHash(seller),
+			common.HexToHash(buyer),
+			crypto.Keccak256Hash([]byte(tick)),
+		},
+		Data: data,
+	}
+}
+
+func TestParseEventSignature(t *testing.T) {
+	event, err := ParseEventSignature(soldEvent)
+	assert.NoError(t, err)
+	assert.Equal(t, "Sold", event.Name)
+	assert.Equal(t, crypto.Keccak256Hash([]byte("Sold(address,address,string,uint256)")), event.ID)
+	assert.Equal(t, 4, len(event.Inputs))
+	assert.True(t, event.Inputs[2].Indexed)
+	assert.False(t, event.Inputs[3].Indexed)
+
+	for _, sig := range []string{"", "Sold", "Sold(address)", "Sold(foo seller)"} {
+		_, err = ParseEventSignature(sig)
+		assert.Error(t, err, sig)
+	}
+}
+
+func TestNewMarket(t *testing.T) {
+	m := newTestMarket(t)
+	assert.Equal(t, "asc-20", m.Protocol)
+	assert.Equal(t, devents.OperateExchange, m.Operate)
+
+	_, err := NewMarket(&config.MarketConfig{
+		Chain:    "avalanche",
+		Protocol: "asc-20",
+		Event:    soldEvent,
+		Fields:   config.MarketFields{From: "seller", To: "buyer", Tick: "ticker", Amount: "amount"},
+	})
+	assert.Error(t, err)
+
+	// any contract could move the sellers' balances
+	_, err = NewMarket(&config.MarketConfig{
+		Chain:    "avalanche",
+		Protocol: "asc-20",
+		Event:    soldEvent,
+		Fields:   config.MarketFields{From: "seller", To: "buyer", Tick: "tick", Amount: "amount"},
+	})
+	assert.Error(t, err)
+
+	// the emitting contract moves its own balance
+	m, err = NewMarket(&config.MarketConfig{
+		Chain:    "avalanche",
+		Protocol: "asc-20",
+		Event:    soldEvent,
+		Fields:   config.MarketFields{From: config.MarketFieldContract, To: "buyer", Tick: "tick", Amount: "amount"},
+	})
+	assert.NoError(t, err)
+
+	log := soldLog(t, seller, "avav", 100)
+	assert.True(t, m.Match(&log))
+}
+
+func TestMarket_Decode(t *testing.T) {
+	m := newTestMarket(t)
+
+	log := soldLog(t, contract, "avav", 100)
+	assert.True(t, m.Match(&log))
+
+	other := soldLog(t, seller, "avav", 100)
+	assert.False(t, m.Match(&other))
+
+	order, err := m.Decode(&log)
+	assert.NoError(t, err)
+	assert.Equal(t, contract, order.From)
+	assert.Equal(t, buyer, order.To)
+	assert.True(t, order.Hashed)
+	assert.Equal(t, crypto.Keccak256Hash([]byte("avav")).Hex(), order.Tick)
+	assert.True(t, order.Amount.Equal(decimal.NewFromInt(100)))
+}
+
+func TestHandler_Parse(t *testing.T) {
+	cache := dcache.NewManager(nil, "avalanche")
+	cache.Balance = dcache.NewBalance()
+	cache.Inscription = dcache.NewInscription()
+	cache.Inscription.Create("asc-20", "avav", &dcache.Tick{})
+	cache.Balance.Create("asc-20", "avav", contract, &dcache.BalanceItem{
+		Available: decimal.NewFromInt(150),
+		Overall:   decimal.NewFromInt(150),
+	})
+
+	h := NewHandler(cache, []*Market{newTestMarket(t)})
+	tx := &xycommon.RpcTransaction{
+		Hash: "0x01",
+		Events: []xycommon.RpcLog{
+			soldLog(t, contract, "avav", 100),
+			soldLog(t, contract, "avav", 100), // exceeds the balance left
+			soldLog(t, contract, "unknown", 1),
+		},
+	}
+
+	md := h.ParseMetaData("avalanche", tx)
+	assert.NotNil(t, md)
+	assert.Equal(t, "asc-20", md.Protocol)
+	assert.Nil(t, h.ParseMetaData("eth", tx))
+
+	results, err := h.Parse(&xycommon.RpcBlock{Number: big.NewInt(1)}, tx, md)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "avav", results[0].MD.Tick)
+	assert.Equal(t, contract, results[0].Transfer.Sender)
+	assert.Equal(t, buyer, results[0].Transfer.Receives[0].Address)
+	assert.True(t, results[0].Transfer.Receives[0].Amount.Equal(decimal.NewFromInt(100)))
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package protocol
+
+import (
+	"fmt"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/protocol/market"
+	"sync"
+)
+
+var markets = struct {
+	sync.RWMutex
+	items []*market.Market
+}{}
+
+// LoadMarkets add the configured marketplace events, used by the protocols of chains created afterwards
+func LoadMarkets(items []*config.MarketConfig) error {
+	loaded := make([]*market.Market, 0, len(items))
+	for _, item := range items {
+		m, err := market.NewMarket(item)
+		if err != nil {
+			return fmt.Errorf("market[%s-%s] event[%s] invalid, err:%v", item.Chain, item.Protocol, item.Event, err)
+		}
+		loaded = append(loaded, m)
+	}
+
+	markets.Lock()
+	defer markets.Unlock()
+	markets.items = append(markets.items, loaded...)
+	return nil
+}
+
+// ResetMarkets remove all marketplace events
+func ResetMarkets() {
+	markets.Lock()
+	defer markets.Unlock()
+	markets.items = nil
+}
+
+func marketsOf(chain string) []*market.Market {
+	markets.RLock()
+	defer markets.RUnlock()
+
+	items := make([]*market.Market, 0, len(markets.items))
+	for _, item := range markets.items {
+		if item.Chain == chain {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -29,7 +29,7 @@ import (
 	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/devents"
 	"github.com/uxuycom/indexer/model"
-	"github.com/uxuycom/indexer/protocol/avax/asc20"
+	"github.com/uxuycom/indexer/protocol/btc/ord"
 	"github.com/uxuycom/indexer/protocol/types"
 	"strings"
 )
@@ -40,20 +40,29 @@ var EVMValidContentTypes = map[string]struct{}{
 	"application/json": {},
 }
 
-func ParseMetaData(chainName string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
-	switch chainName {
-	case model.ChainBTC:
-		return ParseBTCMetaData(chainName, tx)
+var BTCValidContentTypes = map[string]struct{}{
+	"text/plain":       {},
+	"application/json": {},
+}
+
+// ParseMetaData parse the generic inscription formats of the chain group
+func ParseMetaData(group model.ChainGroup, chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
+	switch group {
+	case model.BtcChainGroup:
+		return ParseBTCMetaData(chain, tx)
 	}
+	return ParseEVMMetaData(chain, txHeight(tx), tx.Input)
+}
 
-	// MethodID: 0xd9b3d6d0
-	if chainName == model.ChainAVAX && len(tx.Events) > 0 {
-		return asc20.ParseMetaDataByEventLogs(chainName, tx)
+// txHeight the block height of the tx for the active protocol rules
+func txHeight(tx *xycommon.RpcTransaction) uint64 {
+	if tx.BlockNumber == nil {
+		return 0
 	}
-	return ParseEVMMetaData(chainName, tx.Input)
+	return tx.BlockNumber.Uint64()
 }
 
-func ParseEVMMetaData(chain string, inputData string) (*devents.MetaData, error) {
+func ParseEVMMetaData(chain string, height uint64, inputData string) (*devents.MetaData, error) {
 	// 0x prefix checking
 	if !strings.HasPrefix(inputData, "0x") {
 		return nil, fmt.Errorf("input 0x prefix checking failed")
@@ -81,7 +90,11 @@ func ParseEVMMetaData(chain string, inputData string) (*devents.MetaData, error)
 		return nil, fmt.Errorf("tx content-type invalid & filtered, ct:%s", contentType)
 	}
 
-	data := input[dataPrefixIdx+1:]
+	return parseJSONMetaData(chain, height, input[dataPrefixIdx+1:], len(input))
+}
+
+// parseJSONMetaData parse the json payload of an inscription, size is the raw content length for max length limit
+func parseJSONMetaData(chain string, height uint64, data string, size int) (*devents.MetaData, error) {
 	proto := &devents.MetaData{}
 	if err := json.Unmarshal([]byte(data), proto); err != nil {
 		return nil, fmt.Errorf("tx input data parsed failed, data[%s], err[%v]", data, err)
@@ -90,14 +103,11 @@ func ParseEVMMetaData(chain string, inputData string) (*devents.MetaData, error)
 	// trim prefix / suffix spaces & case insensitive
 	proto.Protocol = strings.ToLower(strings.TrimSpace(proto.Protocol))
 
-	maxDataLength := types.DefaultMaxDataLength
-	if value, ok := types.DefaultMaxDataLengthMap[proto.Protocol]; ok {
-		maxDataLength = value
-	}
+	maxDataLength := types.RulesAt(proto.Protocol, chain, height).MaxDataLength
 
 	// max length limit
-	if len(input) > maxDataLength {
-		return nil, fmt.Errorf("data character size[%d] > 256", len(input))
+	if size > maxDataLength {
+		return nil, fmt.Errorf("data character size[%d] > %d", size, maxDataLength)
 	}
 
 	proto.Operate = strings.ToLower(strings.TrimSpace(proto.Operate))
@@ -113,5 +123,18 @@ func ParseEVMMetaData(chain string, inputData string) (*devents.MetaData, error)
 }
 
 func ParseBTCMetaData(chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
-	return nil, nil
+	envelopes := ord.ParseEnvelopes(tx.Vin)
+	if len(envelopes) < 1 {
+		return nil, fmt.Errorf("inscription envelope not found")
+	}
+
+	// only the first inscription of a tx is indexed
+	envelope := envelopes[0]
+
+	// content-type params are ignored, e.g. text/plain;charset=utf-8
+	contentType := strings.ToLower(strings.TrimSpace(strings.Split(envelope.ContentType, ";")[0]))
+	if _, ok := BTCValidContentTypes[contentType]; !ok {
+		return nil, fmt.Errorf("inscription content-type invalid & filtered, ct:%s", envelope.ContentType)
+	}
+	return parseJSONMetaData(chain, txHeight(tx), string(envelope.Body), len(envelope.Body))
 }
@@ -24,6 +24,8 @@ package protocol
 
 import (
 	"encoding/hex"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/devents"
 	"github.com/uxuycom/indexer/model"
 	"reflect"
@@ -77,7 +79,7 @@ func TestParseEVMMetaData(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := ParseEVMMetaData(tt.args.chain, tt.args.inputData)
+			got, err := ParseEVMMetaData(tt.args.chain, 0, tt.args.inputData)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ParseEVMMetaData() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -88,3 +90,54 @@ func TestParseEVMMetaData(t *testing.T) {
 		})
 	}
 }
+
+func TestParseBTCMetaData(t *testing.T) {
+	sig := "0073ec266d4fb4adbf3d104aa714f9f11032fd8ab6d8829fc40b52c86f6485d7928cc2ebd4646f3fe3f374be11d905bf4be275fa86f3889d82a9f7dc5e41dd32"
+	controlBlock := "c13bed2cb3a3acf7b6a8ef408420cc682d5520e26976d354254f528c965612054f"
+	tests := []struct {
+		name    string
+		script  string
+		want    *devents.MetaData
+		wantErr bool
+	}{
+		{
+			name:   "text/plain deploy",
+			script: "202c70e12b7a0646f92279f427c7b38e7334d8e5389cff167a1dc30e73f826b683ac0063036f7264010118746578742f706c61696e3b636861727365743d7574662d3800487b2270223a226272632d3230222c226f70223a226465706c6f79222c227469636b223a226f726469222c226d6178223a223231303030303030222c226c696d223a2231303030227d68",
+			want: &devents.MetaData{
+				Chain:    model.ChainBTC,
+				Operate:  "deploy",
+				Protocol: "brc-20",
+				Tick:     "ordi",
+				Data:     "{\"p\":\"brc-20\",\"op\":\"deploy\",\"tick\":\"ordi\",\"max\":\"21000000\",\"lim\":\"1000\"}",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "image/png filtered",
+			script:  "202c70e12b7a0646f92279f427c7b38e7334d8e5389cff167a1dc30e73f826b683ac0063036f7264010109696d6167652f706e67000889504e470d0a1a0a68",
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "envelope not found",
+			script:  "202c70e12b7a0646f92279f427c7b38e7334d8e5389cff167a1dc30e73f826b683ac",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &xycommon.RpcTransaction{
+				Vin: []btcjson.Vin{{Witness: []string{sig, tt.script, controlBlock}}},
+			}
+			got, err := ParseBTCMetaData(model.ChainBTC, tx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseBTCMetaData() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseBTCMetaData() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -28,57 +28,97 @@ import (
 	"github.com/uxuycom/indexer/dcache"
 	"github.com/uxuycom/indexer/devents"
 	"github.com/uxuycom/indexer/model"
-	"github.com/uxuycom/indexer/protocol/avax/asc20"
-	btcBrc20 "github.com/uxuycom/indexer/protocol/btc/brc20"
-	"github.com/uxuycom/indexer/protocol/evm/brc20"
-	"github.com/uxuycom/indexer/protocol/evm/erc20"
+	"github.com/uxuycom/indexer/protocol/market"
 	"github.com/uxuycom/indexer/protocol/types"
 	"github.com/uxuycom/indexer/storage"
 	"github.com/uxuycom/indexer/xylog"
+	"math"
 )
 
-var (
-	BTCBrc20Protocol *btcBrc20.Protocol
-	EvmAsc20Protocol *asc20.Protocol
-	EvmBrc20Protocol *brc20.Protocol
-	EvmErc20Protocol *erc20.Protocol
-)
+// Protocols the registered protocol instances supported by one chain
+type Protocols struct {
+	chain     string
+	group     model.ChainGroup
+	instances map[string]types.IProtocol
+	parsers   []types.IMetaDataParser
+	markets   *market.Handler // configured marketplace events, nil if none
+}
+
+func NewProtocols(chain *config.ChainConfig, cache *dcache.Manager) *Protocols {
+	// evm by default
+	group := chain.ChainGroup
+	if group == "" {
+		group = model.EvmChainGroup
+	}
+
+	p := &Protocols{
+		chain:     chain.ChainName,
+		group:     group,
+		instances: make(map[string]types.IProtocol, 8),
+		parsers:   make([]types.IMetaDataParser, 0, 2),
+	}
+	for _, r := range types.Registrations() {
+		if !r.Supports(group, chain.ChainName) {
+			continue
+		}
+
+		instance := r.New(cache)
+		p.instances[r.Name] = instance
+		if parser, ok := instance.(types.IMetaDataParser); ok {
+			p.parsers = append(p.parsers, parser)
+		}
+	}
+
+	if items := marketsOf(chain.ChainName); len(items) > 0 {
+		p.markets = market.NewHandler(cache, items)
+	}
+	return p
+}
 
-func InitProtocols(cache *dcache.Manager) {
-	BTCBrc20Protocol = btcBrc20.NewProtocol(cache)
-	EvmBrc20Protocol = brc20.NewProtocol(cache)
-	EvmAsc20Protocol = asc20.NewProtocol(cache)
-	EvmErc20Protocol = erc20.NewProtocol(cache)
+// EventTopics the topics of the configured marketplace events
+func (p *Protocols) EventTopics() []string {
+	if p.markets == nil {
+		return nil
+	}
+	return p.markets.Topics()
 }
 
-func GetProtocol(cfg *config.Config, tx *xycommon.RpcTransaction) (types.IProtocol, *devents.MetaData) {
-	md, err := ParseMetaData(cfg.Chain.ChainName, tx)
-	if md == nil {
-		xylog.Logger.Infof("metadata parsed failed, block:%d-tx:%s, err:%v", tx.BlockNumber, tx.Hash, err)
-		return nil, nil
+// GetProtocol
+/***************************************
+ * parse the generic inscription formats of the chain group first,
+ * then the protocol specific tx formats, e.g. event logs,
+ * and the configured marketplace events at last.
+ * txs of unregistered protocols are dropped
+ ***************************************/
+func (p *Protocols) GetProtocol(tx *xycommon.RpcTransaction) (types.IProtocol, *devents.MetaData) {
+	md, err := ParseMetaData(p.group, p.chain, tx)
+	if md != nil {
+		if instance, ok := p.instances[md.Protocol]; ok {
+			return instance, md
+		}
+		xylog.Logger.Infof("protocol[%s] not registered for chain[%s] & dropped, tx:%s", md.Protocol, p.chain, tx.Hash)
 	}
 
-	// btc types protocols
-	if cfg.Chain.ChainGroup == model.BtcChainGroup {
-		switch md.Protocol {
-		case types.BRC20Protocol:
-			return BTCBrc20Protocol, md
+	for _, parser := range p.parsers {
+		if pmd := parser.ParseMetaData(p.chain, tx); pmd != nil {
+			return p.instances[pmd.Protocol], pmd
 		}
-		return nil, nil
 	}
 
-	// default protocols: evm
-	switch md.Protocol {
-	case types.ASC20Protocol:
-		return EvmAsc20Protocol, md
-	case types.ERC20Protocol:
-		return EvmErc20Protocol, md
-	default:
-		return EvmBrc20Protocol, md
+	if p.markets != nil {
+		if pmd := p.markets.ParseMetaData(p.chain, tx); pmd != nil {
+			return p.markets, pmd
+		}
+	}
+
+	if md == nil {
+		xylog.Logger.Infof("metadata parsed failed, block:%d-tx:%s, err:%v", tx.BlockNumber, tx.Hash, err)
 	}
+	return nil, nil
 }
 
+// GetOperateByTxInput parse the tx input by the latest protocol rules
 func GetOperateByTxInput(chain, inputData string, db *storage.DBClient) *devents.MetaData {
-	md, _ := ParseMetaData(chain, &xycommon.RpcTransaction{Input: inputData})
+	md, _ := ParseEVMMetaData(chain, math.MaxUint64, inputData)
 	return md
 }
@@ -0,0 +1,126 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package protocol
+
+import (
+	"encoding/hex"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xylog"
+	"testing"
+)
+
+func init() {
+	xylog.InitLog(logrus.DebugLevel, "")
+}
+
+func evmInput(data string) string {
+	return "0x" + hex.EncodeToString([]byte("data:,"+data))
+}
+
+func TestGetProtocol(t *testing.T) {
+	cache := dcache.NewManager(nil, model.ChainBTC)
+	cache.UTXO = dcache.NewUTXO()
+
+	evm := NewProtocols(&config.ChainConfig{ChainName: model.ChainAVAX}, cache)
+	btc := NewProtocols(&config.ChainConfig{ChainName: model.ChainBTC, ChainGroup: model.BtcChainGroup}, cache)
+
+	tests := []struct {
+		name      string
+		protocols *Protocols
+		input     string
+		protocol  string
+	}{
+		{
+			name:      "brc-20 on evm",
+			protocols: evm,
+			input:     evmInput(`{"p":"brc-20","op":"mint","tick":"ordi","amt":"1000"}`),
+			protocol:  types.BRC20Protocol,
+		},
+		{
+			name:      "bsc-20 routed explicitly",
+			protocols: evm,
+			input:     evmInput(`{"p":"bsc-20","op":"mint","tick":"bnbs","amt":"1000"}`),
+			protocol:  types.BSC20Protocol,
+		},
+		{
+			name:      "asc-20 on evm",
+			protocols: evm,
+			input:     evmInput(`{"p":"asc-20","op":"mint","tick":"dino","amt":"1000"}`),
+			protocol:  types.ASC20Protocol,
+		},
+		{
+			name:      "unknown protocol ethscribed",
+			protocols: evm,
+			input:     evmInput(`{"p":"xyz-20","op":"mint","tick":"abcd","amt":"1000"}`),
+			protocol:  types.EthscriptionsProtocol,
+		},
+		{
+			name:      "plain data uri ethscribed",
+			protocols: evm,
+			input:     "0x" + hex.EncodeToString([]byte("data:image/png;base64,iVBORw0KGgo=")),
+			protocol:  types.EthscriptionsProtocol,
+		},
+		{
+			name:      "unknown protocol dropped by btc",
+			protocols: btc,
+			input:     evmInput(`{"p":"xyz-20","op":"mint","tick":"abcd","amt":"1000"}`),
+		},
+		{
+			name:      "evm protocol not supported by btc",
+			protocols: btc,
+			input:     evmInput(`{"p":"asc-20","op":"mint","tick":"dino","amt":"1000"}`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pt, md := tt.protocols.GetProtocol(&xycommon.RpcTransaction{Input: tt.input})
+			if tt.protocol == "" {
+				assert.Nil(t, pt)
+				assert.Nil(t, md)
+				return
+			}
+			assert.NotNil(t, pt)
+			assert.Equal(t, tt.protocol, md.Protocol)
+			assert.Same(t, tt.protocols.instances[tt.protocol], pt)
+		})
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	assert.Panics(t, func() {
+		types.Register(&types.Registration{
+			Name:        types.BRC20Protocol,
+			ChainGroups: []model.ChainGroup{model.EvmChainGroup},
+			Chains:      []string{model.ChainAVAX},
+			New: func(cache *dcache.Manager) types.IProtocol {
+				return nil
+			},
+		})
+	})
+}
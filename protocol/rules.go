@@ -0,0 +1,117 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package protocol
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/protocol/types"
+	"strings"
+)
+
+// LoadRules
+/***************************************
+ * add the configured rule changes, the same config must be used
+ * by indexing & reindexing to get deterministic results
+ ***************************************/
+func LoadRules(items []*config.RuleConfig) error {
+	for _, item := range items {
+		change, err := buildRuleChange(item)
+		if err != nil {
+			return fmt.Errorf("protocol[%s] rule at height[%d] invalid, err:%v", item.Protocol, item.Height, err)
+		}
+		types.AddRuleChange(change)
+	}
+	return nil
+}
+
+func buildRuleChange(item *config.RuleConfig) (*types.RuleChange, error) {
+	protocol := strings.ToLower(strings.TrimSpace(item.Protocol))
+	if protocol == "" {
+		return nil, fmt.Errorf("protocol empty")
+	}
+
+	var maxSupply *decimal.Decimal
+	if item.MaxSupply != nil {
+		value, err := decimal.NewFromString(*item.MaxSupply)
+		if err != nil || value.LessThanOrEqual(decimal.Zero) {
+			return nil, fmt.Errorf("max_supply[%s] invalid", *item.MaxSupply)
+		}
+		maxSupply = &value
+	}
+
+	if item.Minter != nil {
+		switch types.MinterRule(*item.Minter) {
+		case types.MinterTo, types.MinterFrom:
+		default:
+			return nil, fmt.Errorf("minter[%s] invalid", *item.Minter)
+		}
+	}
+
+	if item.Precision != nil {
+		switch types.PrecisionMode(*item.Precision) {
+		case types.PrecisionUnchecked, types.PrecisionStrict, types.PrecisionTruncate:
+		default:
+			return nil, fmt.Errorf("precision[%s] invalid", *item.Precision)
+		}
+	}
+
+	if item.Decimals != nil && (*item.Decimals < 0 || *item.Decimals > common.MaxDecimals) {
+		return nil, fmt.Errorf("decimals[%d] invalid", *item.Decimals)
+	}
+
+	if item.MaxDataLength != nil && *item.MaxDataLength <= 0 {
+		return nil, fmt.Errorf("max_data_length[%d] invalid", *item.MaxDataLength)
+	}
+
+	return &types.RuleChange{
+		Protocol: protocol,
+		Chains:   item.Chains,
+		Height:   item.Height,
+		Apply: func(r *types.Rules) {
+			if item.MaxDataLength != nil {
+				r.MaxDataLength = *item.MaxDataLength
+			}
+			if maxSupply != nil {
+				r.MaxSupply = *maxSupply
+			}
+			if item.Minter != nil {
+				r.Minter = types.MinterRule(*item.Minter)
+			}
+			if item.SelfMint != nil {
+				r.SelfMint = *item.SelfMint
+			}
+			if item.PartialMint != nil {
+				r.PartialMint = *item.PartialMint
+			}
+			if item.Precision != nil {
+				r.Precision = types.PrecisionMode(*item.Precision)
+			}
+			if item.Decimals != nil {
+				r.Decimals = int8(*item.Decimals)
+			}
+		},
+	}, nil
+}
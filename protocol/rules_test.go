@@ -0,0 +1,66 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package protocol
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/types"
+	"testing"
+)
+
+func TestLoadRules(t *testing.T) {
+	t.Cleanup(types.ResetRuleChanges)
+
+	length, supply, minter, precision, decimals := 512, "21000000", "from", "truncate", 18
+	err := LoadRules([]*config.RuleConfig{
+		{Protocol: "BSC-20", Chains: []string{"bsc"}, Height: 100, MaxDataLength: &length},
+		{Protocol: "bsc-20", Height: 200, MaxSupply: &supply, Minter: &minter, Precision: &precision, Decimals: &decimals},
+	})
+	assert.Nil(t, err)
+
+	rules := types.RulesAt(types.BSC20Protocol, "bsc", 99)
+	assert.Equal(t, types.DefaultRules(), *rules)
+	assert.Equal(t, types.PrecisionUnchecked, rules.Precision)
+	assert.Equal(t, int8(0), rules.Decimals)
+
+	rules = types.RulesAt(types.BSC20Protocol, "bsc", 200)
+	assert.Equal(t, 512, rules.MaxDataLength)
+	assert.Equal(t, "21000000", rules.MaxSupply.String())
+	assert.Equal(t, types.MinterFrom, rules.Minter)
+	assert.Equal(t, types.PrecisionTruncate, rules.Precision)
+	assert.Equal(t, int8(18), rules.Decimals)
+
+	// chain limited change
+	rules = types.RulesAt(types.BSC20Protocol, model.ChainAVAX, 200)
+	assert.Equal(t, types.DefaultMaxDataLength, rules.MaxDataLength)
+	assert.Equal(t, types.MinterFrom, rules.Minter)
+
+	invalid := "sender"
+	assert.NotNil(t, LoadRules([]*config.RuleConfig{{Protocol: "bsc-20", Minter: &invalid}}))
+	assert.NotNil(t, LoadRules([]*config.RuleConfig{{Protocol: "bsc-20", Precision: &invalid}}))
+
+	decimals = 19
+	assert.NotNil(t, LoadRules([]*config.RuleConfig{{Protocol: "bsc-20", Decimals: &decimals}}))
+}
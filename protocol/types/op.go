@@ -33,20 +33,14 @@ type IProtocol interface {
 }
 
 const (
-	BRC20Protocol = "brc-20"
-	ASC20Protocol = "asc-20"
-	BSC20Protocol = "bsc-20"
-	PRC20Protocol = "prc-20"
-	ERC20Protocol = "erc-20"
+	BRC20Protocol  = "brc-20"
+	ASC20Protocol  = "asc-20"
+	BSC20Protocol  = "bsc-20"
+	PRC20Protocol  = "prc-20"
+	ERC20Protocol  = "erc-20"
+	IERC20Protocol = "ierc-20"
+
+	EthscriptionsProtocol = "ethscriptions"
 
 	DefaultMaxDataLength = 256
 )
-
-// DefaultMaxDataLengthMap max data length config
-var DefaultMaxDataLengthMap = map[string]int{
-	BRC20Protocol: 256,
-	ASC20Protocol: 256,
-	BSC20Protocol: 256,
-	PRC20Protocol: 256,
-	ERC20Protocol: 256,
-}
@@ -0,0 +1,145 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package types
+
+import (
+	"fmt"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"sort"
+	"sync"
+)
+
+// Registration
+/*****************************************************
+ * protocol implementation registered by the protocol package, e.g.
+ *
+ *	func init() {
+ *		types.Register(&types.Registration{
+ *			Name:        types.BRC20Protocol,
+ *			ChainGroups: []model.ChainGroup{model.EvmChainGroup},
+ *			New:         func(cache *dcache.Manager) types.IProtocol { return NewProtocol(cache) },
+ *		})
+ *	}
+ ****************************************************/
+type Registration struct {
+	Name        string             // protocol name, the "p" field of the inscription data
+	ChainGroups []model.ChainGroup // supported chain groups
+	Chains      []string           // supported chain names, all chains of the groups if empty
+	Rules       *Rules             // default rules, DefaultRules() if nil
+	New         func(cache *dcache.Manager) IProtocol
+}
+
+// IMetaDataParser
+/***************************************
+ * optional, implemented by protocols recognizing txs beyond the generic
+ * inscription formats, e.g. marketplace event logs. returns nil if not recognized
+ ***************************************/
+type IMetaDataParser interface {
+	ParseMetaData(chain string, tx *xycommon.RpcTransaction) *devents.MetaData
+}
+
+var registry = struct {
+	sync.RWMutex
+	items []*Registration
+}{}
+
+// Register register the protocol implementation, panics if the protocol is registered for the same chains twice
+func Register(r *Registration) {
+	if r.Name == "" || r.New == nil || len(r.ChainGroups) < 1 {
+		panic(fmt.Sprintf("protocol registration[%s] invalid", r.Name))
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	for _, item := range registry.items {
+		if item.Name == r.Name && item.overlaps(r) {
+			panic(fmt.Sprintf("protocol[%s] registered twice", r.Name))
+		}
+	}
+	registry.items = append(registry.items, r)
+}
+
+// Registrations all registered protocols sorted by name
+func Registrations() []*Registration {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	items := make([]*Registration, len(registry.items))
+	copy(items, registry.items)
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+	return items
+}
+
+// defaultRules default rules of the protocol
+func defaultRules(protocol string) Rules {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	for _, item := range registry.items {
+		if item.Name == protocol && item.Rules != nil {
+			return *item.Rules
+		}
+	}
+	return DefaultRules()
+}
+
+// Supports whether the protocol is supported by the chain
+func (r *Registration) Supports(group model.ChainGroup, chain string) bool {
+	if !contains(r.ChainGroups, group) {
+		return false
+	}
+	return len(r.Chains) < 1 || contains(r.Chains, chain)
+}
+
+func (r *Registration) overlaps(other *Registration) bool {
+	for _, group := range other.ChainGroups {
+		if !contains(r.ChainGroups, group) {
+			continue
+		}
+
+		if len(r.Chains) < 1 || len(other.Chains) < 1 {
+			return true
+		}
+
+		for _, chain := range other.Chains {
+			if contains(r.Chains, chain) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func contains[T comparable](items []T, v T) bool {
+	for _, item := range items {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package types
+
+import (
+	"github.com/shopspring/decimal"
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+)
+
+// MinterRule the address receiving the minted amount
+type MinterRule string
+
+const (
+	MinterTo   MinterRule = "to"
+	MinterFrom MinterRule = "from"
+)
+
+// PrecisionMode how amounts with more fractional digits than the tick decimals are handled
+type PrecisionMode string
+
+const (
+	PrecisionUnchecked PrecisionMode = "unchecked" // kept as written, as indexed before the precision rules
+	PrecisionStrict    PrecisionMode = "strict"    // rejected, as brc-20 indexers do
+	PrecisionTruncate  PrecisionMode = "truncate"  // truncated to the tick decimals
+)
+
+// Rules
+/*****************************************************
+ * protocol rules active at a block height, the defaults of a protocol are
+ * set by its registration & changed by RuleChange from activation heights
+ ****************************************************/
+type Rules struct {
+	MaxDataLength int             // max inscription data length
+	MaxSupply     decimal.Decimal // deploy max supply upper limit
+	Minter        MinterRule      // the address receiving the minted amount
+	SelfMint      bool            // mints must be self-sent, tx from == to
+	PartialMint   bool            // the last mint is clamped to the supply left, rejected if false
+	Precision     PrecisionMode   // amounts exceeding the tick decimals
+	Decimals      int8            // tick decimals of deploys without dec
+}
+
+// DefaultRules rules of protocols registered without ones
+func DefaultRules() Rules {
+	return Rules{
+		MaxDataLength: DefaultMaxDataLength,
+		MaxSupply:     decimal.NewFromBigInt(new(big.Int).SetUint64(math.MaxUint64), 0),
+		Minter:        MinterTo,
+		SelfMint:      false,
+		PartialMint:   true,
+		Precision:     PrecisionUnchecked,
+		Decimals:      0,
+	}
+}
+
+// RuleChange a community-agreed rule change of the protocol, applied to blocks >= Height
+type RuleChange struct {
+	Protocol string
+	Chains   []string // all chains if empty
+	Height   uint64
+	Apply    func(r *Rules)
+}
+
+var ruleChanges = struct {
+	sync.RWMutex
+	items []*RuleChange
+}{}
+
+// AddRuleChange add the rule change, changes of the same height are applied in adding order
+func AddRuleChange(c *RuleChange) {
+	ruleChanges.Lock()
+	defer ruleChanges.Unlock()
+
+	ruleChanges.items = append(ruleChanges.items, c)
+	sort.SliceStable(ruleChanges.items, func(i, j int) bool {
+		return ruleChanges.items[i].Height < ruleChanges.items[j].Height
+	})
+}
+
+// ResetRuleChanges remove all rule changes
+func ResetRuleChanges() {
+	ruleChanges.Lock()
+	defer ruleChanges.Unlock()
+	ruleChanges.items = nil
+}
+
+// RulesAt the protocol rules of the chain active at the block height
+func RulesAt(protocol, chain string, height uint64) *Rules {
+	rules := defaultRules(protocol)
+
+	ruleChanges.RLock()
+	defer ruleChanges.RUnlock()
+	for _, c := range ruleChanges.items {
+		if c.Height > height {
+			break
+		}
+
+		if c.Protocol != protocol || (len(c.Chains) > 0 && !contains(c.Chains, chain)) {
+			continue
+		}
+		c.Apply(&rules)
+	}
+	return &rules
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/uxuycom/indexer/model"
 	"github.com/uxuycom/indexer/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 	"math/big"
 	"reflect"
@@ -38,8 +39,9 @@ import (
 )
 
 const (
-	DatabaseTypeSqlite3 = "sqlite3"
-	DatabaseTypeMysql   = "mysql"
+	DatabaseTypeSqlite3  = "sqlite3"
+	DatabaseTypeMysql    = "mysql"
+	DatabaseTypePostgres = "postgres"
 )
 
 const DBSessionLockKey = "db_session_global_lock_tx"
@@ -58,7 +60,16 @@ const (
 )
 
 type DBClient struct {
-	SqlDB *gorm.DB
+	SqlDB  *gorm.DB
+	locker Locker
+}
+
+// WrapGormDB wrap the opened db, the sink lock is chosen by the db dialect
+func WrapGormDB(db *gorm.DB) *DBClient {
+	return &DBClient{
+		SqlDB:  db,
+		locker: newLocker(db),
+	}
 }
 
 // NewDbClient creates a new database client instance.
@@ -72,8 +83,11 @@ func NewDbClient(cfg *config.DatabaseConfig) (*DBClient, error) {
 		return NewSqliteClient(cfg, gormCfg)
 	case DatabaseTypeMysql:
 		return NewMysqlClient(cfg, gormCfg)
+	case DatabaseTypePostgres:
+		// the postgres driver is not linked, open the db with gorm.io/driver/postgres & wrap it by WrapGormDB
+		return nil, fmt.Errorf("database type[%s] is not built in", cfg.Type)
 	}
-	return nil, nil
+	return nil, fmt.Errorf("unsupported database type[%s]", cfg.Type)
 }
 
 func (conn *DBClient) CreateInBatches(dbTx *gorm.DB, value interface{}, batchSize int) error {
@@ -104,6 +118,16 @@ func (conn *DBClient) SaveLastBlock(tx *gorm.DB, status *model.BlockStatus) erro
 	if tx == nil {
 		return errors.New("gorm db is not valid")
 	}
+
+	// never rewind the synced block here, see RollbackLastBlock
+	var cnt int64
+	err := tx.Model(&model.BlockStatus{}).Where("chain = ? AND block_number > ?", status.Chain, status.BlockNumber).Count(&cnt).Error
+	if err != nil {
+		return err
+	}
+	if cnt > 0 {
+		return nil
+	}
 	return tx.Where("chain = ?", status.Chain).Save(status).Error
 }
 
@@ -122,26 +146,37 @@ func (conn *DBClient) QueryLastBlock(chain string) (*big.Int, error) {
 	return blockNumber, nil
 }
 
-func (conn *DBClient) GetLock() (ok bool, err error) {
-	locked := int64(0)
-	err = conn.SqlDB.Table(model.BlockStatus{}.TableName()).Raw("SELECT GET_LOCK(?, 0)", DBSessionLockKey).Scan(&locked).Error
+// QueryLastBlockStatus query the last synced block status of the chain
+func (conn *DBClient) QueryLastBlockStatus(chain string) (*model.BlockStatus, error) {
+	status := &model.BlockStatus{}
+	err := conn.SqlDB.First(status, "chain = ?", chain).Error
 	if err != nil {
-		return false, err
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
 	}
-	return locked > 0, nil
+	return status, nil
 }
 
-type CountResult struct {
-	Count int64 `gorm:"column:cnt"`
+// RollbackLastBlock move the last synced block status back to the given block
+func (conn *DBClient) RollbackLastBlock(dbTx *gorm.DB, status *model.BlockStatus) error {
+	updates := map[string]interface{}{
+		"block_hash":   status.BlockHash,
+		"block_number": status.BlockNumber,
+		"block_time":   status.BlockTime,
+	}
+	return dbTx.Model(&model.BlockStatus{}).Where("chain = ? AND block_number > ?", status.Chain, status.BlockNumber).Updates(updates).Error
 }
 
-func (conn *DBClient) ReleaseLock() (cnt int64, err error) {
-	ret := &CountResult{}
-	err = conn.SqlDB.Table(model.BlockStatus{}.TableName()).Raw("SELECT RELEASE_LOCK(?) AS cnt", DBSessionLockKey).Take(ret).Error
-	if err != nil {
-		return 0, err
-	}
-	return ret.Count, nil
+// GetLock try to get the global db lock without waiting
+func (conn *DBClient) GetLock() (ok bool, err error) {
+	return conn.locker.TryLock()
+}
+
+// ReleaseLock release the global db lock got by GetLock
+func (conn *DBClient) ReleaseLock() error {
+	return conn.locker.Unlock()
 }
 
 func (conn *DBClient) BatchAddInscription(dbTx *gorm.DB, ins []*model.Inscriptions) error {
@@ -337,7 +372,7 @@ func (conn *DBClient) FindUserBalanceByTick(chain, protocol, tick, addr string)
 
 func (conn *DBClient) FindTransaction(chain string, hash common.Hash) (*model.Transaction, error) {
 	txn := &model.Transaction{}
-	err := conn.SqlDB.First(txn, "chain = ? AND tx_hash = ?", chain, hash).Error
+	err := conn.SqlDB.Order("sub_index asc").First(txn, "chain = ? AND tx_hash = ?", chain, hash).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -347,6 +382,16 @@ func (conn *DBClient) FindTransaction(chain string, hash common.Hash) (*model.Tr
 	return txn, nil
 }
 
+// FindTransactions all ops of the tx, in op order
+func (conn *DBClient) FindTransactions(chain string, hash common.Hash) ([]*model.Transaction, error) {
+	txs := make([]*model.Transaction, 0, 1)
+	err := conn.SqlDB.Where("chain = ? AND tx_hash = ?", chain, hash).Order("sub_index asc").Find(&txs).Error
+	if err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
 func (conn *DBClient) GetInscriptions(limit, offset int, chain, protocol, tick, deployBy string, sort int, sortMode int) (
 	[]*model.InscriptionOverView, int64, error) {
 
@@ -620,9 +665,9 @@ func (conn *DBClient) GetAddressInscriptions(limit, offset int, address, chain,
 	}
 
 	query = query.Count(&total)
-	orderBy := "`b`.balance DESC"
+	orderBy := conn.numeric("`b`.balance") + " DESC"
 	if sort == OrderByModeAsc {
-		orderBy = "`b`.balance ASC"
+		orderBy = conn.numeric("`b`.balance") + " ASC"
 	}
 
 	result := query.Order(orderBy).Limit(limit).Offset(offset).Find(&data)
@@ -639,7 +684,7 @@ func (conn *DBClient) GetBalancesChainByAddress(limit, offset int, address, chai
 	var balances []*model.BalanceChain
 	var total int64
 
-	query := conn.SqlDB.Select("chain,address,SUM(balance) as balance").Table("balances").Where("`address` = ?", address)
+	query := conn.SqlDB.Table("balances").Where("`address` = ?", address)
 	if chain != "" {
 		query = query.Where("`chain` = ?", chain)
 	}
@@ -649,7 +694,11 @@ func (conn *DBClient) GetBalancesChainByAddress(limit, offset int, address, chai
 	if tick != "" {
 		query = query.Where("`tick` = ?", tick)
 	}
-	query = query.Count(&total)
+	if conn.isSqlite() {
+		return sumSqliteBalancesByChain(query, limit, offset)
+	}
+
+	query = query.Select("chain,address,SUM(balance) as balance").Count(&total)
 	orderBy := "balance DESC"
 	groupBy := "chain"
 	err := query.Group(groupBy).Order(orderBy).Limit(limit).Offset(offset).Find(&balances).Error
@@ -665,9 +714,9 @@ func (conn *DBClient) GetHoldersByTick(limit, offset int, chain, protocol, tick
 	query := conn.SqlDB.Model(&model.Balances{}).
 		Where("balance > 0 and chain = ? and protocol = ? and tick = ?", chain, protocol, tick)
 	query = query.Count(&total)
-	orderBy := "balance desc,"
+	orderBy := conn.numeric("balance") + " desc,"
 	if sortMode == OrderByModeAsc {
-		orderBy = "balance asc,"
+		orderBy = conn.numeric("balance") + " asc,"
 	}
 
 	result := query.Order(orderBy + " id asc").Limit(limit).Offset(offset).Find(&holders)
@@ -697,9 +746,19 @@ func (conn *DBClient) GetBalancesByIdLimit(chain string, start uint64, limit int
 	return balances, nil
 }
 
-func (conn *DBClient) GetUTXOsByIdLimit(start uint64, limit int) ([]model.UTXO, error) {
+// MaxBalanceSid the max balance sid of the chain, 0 if none
+func (conn *DBClient) MaxBalanceSid(chain string) (uint64, error) {
+	var sid uint64
+	err := conn.SqlDB.Model(&model.Balances{}).Select("COALESCE(MAX(sid), 0)").Where("chain = ?", chain).Scan(&sid).Error
+	if err != nil {
+		return 0, err
+	}
+	return sid, nil
+}
+
+func (conn *DBClient) GetUTXOsByIdLimit(chain string, start uint64, limit int) ([]model.UTXO, error) {
 	utxos := make([]model.UTXO, 0, limit)
-	err := conn.SqlDB.Where("id > ? ", start).Where("status = ? ", model.UTXOStatusUnspent).Order("id asc").Limit(limit).Find(&utxos).Error
+	err := conn.SqlDB.Where("chain = ?", chain).Where("id > ? ", start).Where("status = ? ", model.UTXOStatusUnspent).Order("id asc").Limit(limit).Find(&utxos).Error
 	if err != nil {
 		return nil, err
 	}
@@ -717,17 +776,14 @@ func (conn *DBClient) GetUtxosByAddress(address, chain, protocol, tick string) (
 	return utxos, nil
 }
 
-func (conn *DBClient) FindAddressTxByHash(chain string, hash common.Hash) (*model.AddressTxs, error) {
-	tx := &model.AddressTxs{}
-	err := conn.SqlDB.First(tx, "chain = ? and tx_hash = ? ", chain, hash).Error
+// FindAddressTxsByHash find the address txs of all ops of the tx, sorted by the op position
+func (conn *DBClient) FindAddressTxsByHash(chain string, hash common.Hash) ([]*model.AddressTxs, error) {
+	txs := make([]*model.AddressTxs, 0, 2)
+	err := conn.SqlDB.Where("chain = ? AND tx_hash = ?", chain, hash).Order("sub_index asc, id asc").Find(&txs).Error
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
 		return nil, err
 	}
-
-	return tx, nil
+	return txs, nil
 }
 
 func (conn *DBClient) FindBalanceByTxHash(hash string) ([]*model.BalanceTxn, error) {
@@ -905,3 +961,537 @@ func (conn *DBClient) CountTickByChain(chain string) int64 {
 	conn.SqlDB.Model(model.Inscriptions{}).Where("chain = ?", chain).Count(&total)
 	return total
 }
+
+// tickScope limit the query to the tick if not empty
+func tickScope(tick string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if tick == "" {
+			return db
+		}
+		return db.Where("tick = ?", tick)
+	}
+}
+
+// FindTxsFromBlock find all txs at or above the given block height, of all ticks if tick is empty
+func (conn *DBClient) FindTxsFromBlock(chain string, blockNum uint64, tick string) ([]*model.Transaction, error) {
+	txs := make([]*model.Transaction, 0)
+	err := conn.SqlDB.Scopes(tickScope(tick)).Where("chain = ? AND block_height >= ?", chain, blockNum).Order("id asc").Find(&txs).Error
+	if err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+// FindBalanceTxnsByHashes find balance txn records by tx hashes, ordered by id
+func (conn *DBClient) FindBalanceTxnsByHashes(chain string, hashes []common.Hash, tick string) ([]*model.BalanceTxn, error) {
+	txns := make([]*model.BalanceTxn, 0)
+	if len(hashes) < 1 {
+		return txns, nil
+	}
+
+	err := conn.SqlDB.Scopes(tickScope(tick)).Where("chain = ? AND tx_hash in ?", chain, hashes).Order("id asc").Find(&txns).Error
+	if err != nil {
+		return nil, err
+	}
+	return txns, nil
+}
+
+// FindLastBalanceTxnsBeforeId load the latest balance txn of every address before the txn id
+func (conn *DBClient) FindLastBalanceTxnsBeforeId(chain, protocol, tick string, addrs []string, id uint64) ([]*model.BalanceTxn, error) {
+	txns := make([]*model.BalanceTxn, 0, len(addrs))
+	if len(addrs) < 1 {
+		return txns, nil
+	}
+
+	lastIds := conn.SqlDB.Model(&model.BalanceTxn{}).Select("MAX(id)").
+		Where("chain = ? AND protocol = ? AND tick = ? AND address IN ? AND id < ?", chain, protocol, tick, addrs, id).
+		Group("address")
+	err := conn.SqlDB.Where("id IN (?)", lastIds).Find(&txns).Error
+	if err != nil {
+		return nil, err
+	}
+	return txns, nil
+}
+
+// FindUserBalancesByTick load the balances of the addresses for the tick
+func (conn *DBClient) FindUserBalancesByTick(chain, protocol, tick string, addrs []string) ([]*model.Balances, error) {
+	balances := make([]*model.Balances, 0, len(addrs))
+	if len(addrs) < 1 {
+		return balances, nil
+	}
+
+	err := conn.SqlDB.Where("chain = ? AND protocol = ? AND tick = ? AND address IN ?", chain, protocol, tick, addrs).Find(&balances).Error
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// CountHoldersByTick count addresses holding a positive balance of the tick
+func (conn *DBClient) CountHoldersByTick(dbTx *gorm.DB, chain, protocol, tick string) (int64, error) {
+	var total int64
+	err := dbTx.Model(&model.Balances{}).Where("chain = ? AND protocol = ? AND tick = ? AND balance > 0", chain, protocol, tick).Count(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (conn *DBClient) DeleteTxsFromBlock(dbTx *gorm.DB, chain string, blockNum uint64, tick string) error {
+	return dbTx.Scopes(tickScope(tick)).Where("chain = ? AND block_height >= ?", chain, blockNum).Delete(&model.Transaction{}).Error
+}
+
+func (conn *DBClient) DeleteAddressTxsByHashes(dbTx *gorm.DB, chain string, hashes []common.Hash, tick string) error {
+	if len(hashes) < 1 {
+		return nil
+	}
+	return dbTx.Scopes(tickScope(tick)).Where("chain = ? AND tx_hash in ?", chain, hashes).Delete(&model.AddressTxs{}).Error
+}
+
+func (conn *DBClient) DeleteBalanceTxnsByHashes(dbTx *gorm.DB, chain string, hashes []common.Hash, tick string) error {
+	if len(hashes) < 1 {
+		return nil
+	}
+	return dbTx.Scopes(tickScope(tick)).Where("chain = ? AND tx_hash in ?", chain, hashes).Delete(&model.BalanceTxn{}).Error
+}
+
+func (conn *DBClient) DeleteBalancesBySIDs(dbTx *gorm.DB, chain string, sids []uint64) error {
+	if len(sids) < 1 {
+		return nil
+	}
+	return dbTx.Where("chain = ? AND sid in ?", chain, sids).Delete(&model.Balances{}).Error
+}
+
+// DeleteInscriptionByTick delete the tick with its stats and balances
+func (conn *DBClient) DeleteInscriptionByTick(dbTx *gorm.DB, chain, protocol, tick string) error {
+	err := dbTx.Where("chain = ? AND protocol = ? AND tick = ?", chain, protocol, tick).Delete(&model.Balances{}).Error
+	if err != nil {
+		return err
+	}
+
+	err = dbTx.Where("chain = ? AND protocol = ? AND tick = ?", chain, protocol, tick).Delete(&model.InscriptionsStats{}).Error
+	if err != nil {
+		return err
+	}
+	return dbTx.Where("chain = ? AND protocol = ? AND tick = ?", chain, protocol, tick).Delete(&model.Inscriptions{}).Error
+}
+
+// BatchAddBlockGas insert block gas records, blocks already recorded are kept
+func (conn *DBClient) BatchAddBlockGas(dbTx *gorm.DB, items []*model.BlockGas) error {
+	if len(items) < 1 {
+		return nil
+	}
+	return dbTx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(items, 1000).Error
+}
+
+// BatchAddTickGas insert tick gas records, records already existed are kept
+func (conn *DBClient) BatchAddTickGas(dbTx *gorm.DB, items []*model.TickGas) error {
+	if len(items) < 1 {
+		return nil
+	}
+	return dbTx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(items, 1000).Error
+}
+
+func (conn *DBClient) DeleteBlockGasFromBlock(dbTx *gorm.DB, chain string, blockNum uint64) error {
+	return dbTx.Where("chain = ? AND block_height >= ?", chain, blockNum).Delete(&model.BlockGas{}).Error
+}
+
+func (conn *DBClient) DeleteTickGasFromBlock(dbTx *gorm.DB, chain string, blockNum uint64, tick string) error {
+	return dbTx.Scopes(tickScope(tick)).Where("chain = ? AND block_height >= ?", chain, blockNum).Delete(&model.TickGas{}).Error
+}
+
+func (conn *DBClient) FindBlockGas(chain string, blockNum uint64) (*model.BlockGas, error) {
+	item := &model.BlockGas{}
+	err := conn.SqlDB.Where("chain = ? AND block_height = ?", chain, blockNum).First(item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+func (conn *DBClient) FindTickGasByBlock(chain string, blockNum uint64) ([]*model.TickGas, error) {
+	items := make([]*model.TickGas, 0)
+	err := conn.SqlDB.Where("chain = ? AND block_height = ?", chain, blockNum).Order("id asc").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SumTickGas sum the gas spent by the tick, grouped by op. only the op is summed if not empty
+func (conn *DBClient) SumTickGas(chain, protocol, tick, op string) ([]*model.TickGasTotal, error) {
+	query := conn.SqlDB.Model(&model.TickGas{}).
+		Where("chain = ? AND protocol = ? AND tick = ?", chain, protocol, tick)
+	if op != "" {
+		query = query.Where("op = ?", op)
+	}
+	if conn.isSqlite() {
+		return sumSqliteTickGas(query)
+	}
+
+	items := make([]*model.TickGasTotal, 0)
+	err := query.Select("chain, protocol, tick, op, SUM(tx_cnt) AS tx_cnt, SUM(gas_used) AS gas_used, SUM(fee) AS fee").
+		Group("chain, protocol, tick, op").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (conn *DBClient) BatchAddRejectedTxs(dbTx *gorm.DB, items []*model.RejectedTx) error {
+	if len(items) < 1 {
+		return nil
+	}
+	return conn.CreateInBatches(dbTx, items, 5000)
+}
+
+func (conn *DBClient) DeleteRejectedTxsFromBlock(dbTx *gorm.DB, chain string, blockNum uint64, tick string) error {
+	return dbTx.Scopes(tickScope(tick)).Where("chain = ? AND block_height >= ?", chain, blockNum).Delete(&model.RejectedTx{}).Error
+}
+
+func (conn *DBClient) FindRejectedTxsByHash(chain string, hash common.Hash) ([]*model.RejectedTx, error) {
+	items := make([]*model.RejectedTx, 0)
+	err := conn.SqlDB.Where("chain = ? AND tx_hash = ?", chain, hash).Order("id asc").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (conn *DBClient) GetRejectedTxsByAddress(limit, offset int, chain, address string) ([]*model.RejectedTx, int64, error) {
+	var total int64
+	items := make([]*model.RejectedTx, 0)
+	query := conn.SqlDB.Model(&model.RejectedTx{}).Where("chain = ? AND address = ?", chain, address)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("id desc").Limit(limit).Offset(offset).Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (conn *DBClient) BatchAddUTXOs(dbTx *gorm.DB, items []*model.UTXO) error {
+	if len(items) < 1 {
+		return nil
+	}
+	return conn.CreateInBatches(dbTx, items, 2000)
+}
+
+// SpendUTXOs mark the unspent utxos spent by the txs
+func (conn *DBClient) SpendUTXOs(dbTx *gorm.DB, chain string, items []*model.UTXO) error {
+	for _, item := range items {
+		updates := map[string]interface{}{
+			"tx_hash": item.TxHash,
+			"status":  model.UTXOStatusSpent,
+		}
+		err := dbTx.Model(&model.UTXO{}).Where("chain = ? AND root_hash = ? AND status = ?", chain, item.RootHash, model.UTXOStatusUnspent).Updates(updates).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindUTXOsByHashes find utxos created or spent by the txs
+func (conn *DBClient) FindUTXOsByHashes(chain string, hashes []string, tick string) ([]*model.UTXO, error) {
+	items := make([]*model.UTXO, 0)
+	if len(hashes) < 1 {
+		return items, nil
+	}
+
+	err := conn.SqlDB.Scopes(tickScope(tick)).Where("chain = ? AND (root_hash in ? OR tx_hash in ?)", chain, hashes, hashes).Order("id asc").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (conn *DBClient) DeleteUTXOsByRootHashes(dbTx *gorm.DB, chain string, hashes []string, tick string) error {
+	if len(hashes) < 1 {
+		return nil
+	}
+	return dbTx.Scopes(tickScope(tick)).Where("chain = ? AND root_hash in ?", chain, hashes).Delete(&model.UTXO{}).Error
+}
+
+// RestoreUTXOsBySpentHashes mark the utxos spent by the txs unspent again
+func (conn *DBClient) RestoreUTXOsBySpentHashes(dbTx *gorm.DB, chain string, hashes []string, tick string) error {
+	if len(hashes) < 1 {
+		return nil
+	}
+
+	updates := map[string]interface{}{
+		"tx_hash": gorm.Expr("root_hash"),
+		"status":  model.UTXOStatusUnspent,
+	}
+	return dbTx.Model(&model.UTXO{}).Scopes(tickScope(tick)).Where("chain = ? AND tx_hash in ? AND status = ?", chain, hashes, model.UTXOStatusSpent).Updates(updates).Error
+}
+
+// GetEthscriptionsByIdLimit load the ethscription owners & content shas for caching, the content is not loaded
+func (conn *DBClient) GetEthscriptionsByIdLimit(chain string, start uint64, limit int) ([]model.Ethscription, error) {
+	items := make([]model.Ethscription, 0, limit)
+	err := conn.SqlDB.Select("id, ethscription_id, owner, content_sha").Where("chain = ? AND id > ?", chain, start).Order("id asc").Limit(limit).Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (conn *DBClient) BatchAddEthscriptions(dbTx *gorm.DB, items []*model.Ethscription) error {
+	if len(items) < 1 {
+		return nil
+	}
+	return conn.CreateInBatches(dbTx, items, 500)
+}
+
+func (conn *DBClient) BatchAddEthscriptionTransfers(dbTx *gorm.DB, items []*model.EthscriptionTransfer) error {
+	if len(items) < 1 {
+		return nil
+	}
+	return conn.CreateInBatches(dbTx, items, 2000)
+}
+
+// UpdateEthscriptionOwners update the owners in order, an ethscription may be transferred several times in a batch
+func (conn *DBClient) UpdateEthscriptionOwners(dbTx *gorm.DB, chain string, items []*model.Ethscription) error {
+	for _, item := range items {
+		updates := map[string]interface{}{
+			"owner":          item.Owner,
+			"previous_owner": item.PreviousOwner,
+		}
+		err := dbTx.Model(&model.Ethscription{}).Where("chain = ? AND ethscription_id = ?", chain, item.EthscriptionId).Updates(updates).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (conn *DBClient) FindEthscriptionById(chain, id string) (*model.Ethscription, error) {
+	item := &model.Ethscription{}
+	err := conn.SqlDB.Where("chain = ? AND ethscription_id = ?", chain, id).First(item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+// GetEthscriptionsByOwner ethscriptions owned by the address, the content is not loaded
+func (conn *DBClient) GetEthscriptionsByOwner(limit, offset int, chain, owner string) ([]*model.Ethscription, int64, error) {
+	var total int64
+	items := make([]*model.Ethscription, 0)
+	query := conn.SqlDB.Model(&model.Ethscription{}).Where("chain = ? AND owner = ?", chain, owner)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Omit("content_uri").Order("id desc").Limit(limit).Offset(offset).Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (conn *DBClient) GetEthscriptionTransfers(limit, offset int, chain, id string) ([]*model.EthscriptionTransfer, int64, error) {
+	var total int64
+	items := make([]*model.EthscriptionTransfer, 0)
+	query := conn.SqlDB.Model(&model.EthscriptionTransfer{}).Where("chain = ? AND ethscription_id = ?", chain, id)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("id asc").Limit(limit).Offset(offset).Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// FindEthscriptionsFromBlock ethscriptions created at or above the given block height, the content is not loaded
+func (conn *DBClient) FindEthscriptionsFromBlock(chain string, blockNum uint64) ([]*model.Ethscription, error) {
+	items := make([]*model.Ethscription, 0)
+	err := conn.SqlDB.Omit("content_uri").Where("chain = ? AND block_height >= ?", chain, blockNum).Order("id asc").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (conn *DBClient) FindEthscriptionTransfersFromBlock(chain string, blockNum uint64) ([]*model.EthscriptionTransfer, error) {
+	items := make([]*model.EthscriptionTransfer, 0)
+	err := conn.SqlDB.Where("chain = ? AND block_height >= ?", chain, blockNum).Order("id asc").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// FindLastEthscriptionTransferBeforeBlock the latest transfer of the ethscription below the given block height
+func (conn *DBClient) FindLastEthscriptionTransferBeforeBlock(chain, id string, blockNum uint64) (*model.EthscriptionTransfer, error) {
+	item := &model.EthscriptionTransfer{}
+	err := conn.SqlDB.Where("chain = ? AND ethscription_id = ? AND block_height < ?", chain, id, blockNum).Order("id desc").First(item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+func (conn *DBClient) DeleteEthscriptionsFromBlock(dbTx *gorm.DB, chain string, blockNum uint64) error {
+	return dbTx.Where("chain = ? AND block_height >= ?", chain, blockNum).Delete(&model.Ethscription{}).Error
+}
+
+func (conn *DBClient) DeleteEthscriptionTransfersFromBlock(dbTx *gorm.DB, chain string, blockNum uint64) error {
+	return dbTx.Where("chain = ? AND block_height >= ?", chain, blockNum).Delete(&model.EthscriptionTransfer{}).Error
+}
+
+func (conn *DBClient) BatchAddListings(dbTx *gorm.DB, items []*model.Listing) error {
+	if len(items) < 1 {
+		return nil
+	}
+	return conn.CreateInBatches(dbTx, items, 500)
+}
+
+// CloseListings mark the open listings filled or cancelled, listings unknown to the indexer are ignored
+func (conn *DBClient) CloseListings(dbTx *gorm.DB, chain string, items []*model.Listing) error {
+	for _, item := range items {
+		updates := map[string]interface{}{
+			"status":             item.Status,
+			"buyer":              item.Buyer,
+			"price":              item.Price,
+			"unit_price":         item.UnitPrice,
+			"close_tx_hash":      item.CloseTxHash,
+			"close_block_height": item.CloseBlockHeight,
+		}
+		err := dbTx.Model(&model.Listing{}).Where("chain = ? AND list_id = ? AND status = ?", chain, item.ListId, model.ListingStatusOpen).Updates(updates).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetOpenListingsByTick open listings of the tick, the latest first
+func (conn *DBClient) GetOpenListingsByTick(limit, offset int, chain, protocol, tick string) ([]*model.Listing, int64, error) {
+	var total int64
+	items := make([]*model.Listing, 0)
+	query := conn.SqlDB.Model(&model.Listing{}).Where("chain = ? AND protocol = ? AND tick = ? AND status = ?", chain, protocol, tick, model.ListingStatusOpen)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("id desc").Limit(limit).Offset(offset).Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// GetOpenListingsBySeller open listings of the seller
+func (conn *DBClient) GetOpenListingsBySeller(limit, offset int, chain, seller string) ([]*model.Listing, int64, error) {
+	var total int64
+	items := make([]*model.Listing, 0)
+	query := conn.SqlDB.Model(&model.Listing{}).Where("seller = ? AND chain = ? AND status = ?", seller, chain, model.ListingStatusOpen)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("id desc").Limit(limit).Offset(offset).Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (conn *DBClient) DeleteListingsFromBlock(dbTx *gorm.DB, chain string, blockNum uint64, tick string) error {
+	return dbTx.Scopes(tickScope(tick)).Where("chain = ? AND block_height >= ?", chain, blockNum).Delete(&model.Listing{}).Error
+}
+
+// ReopenListingsFromBlock reopen the listings closed at or above the given block height
+func (conn *DBClient) ReopenListingsFromBlock(dbTx *gorm.DB, chain string, blockNum uint64, tick string) error {
+	updates := map[string]interface{}{
+		"status":             model.ListingStatusOpen,
+		"buyer":              "",
+		"price":              0,
+		"unit_price":         0,
+		"close_tx_hash":      "",
+		"close_block_height": 0,
+	}
+	return dbTx.Model(&model.Listing{}).Scopes(tickScope(tick)).Where("chain = ? AND close_block_height >= ? AND status <> ?", chain, blockNum, model.ListingStatusOpen).Updates(updates).Error
+}
+
+func (conn *DBClient) BatchAddTrades(dbTx *gorm.DB, items []*model.Trade) error {
+	if len(items) < 1 {
+		return nil
+	}
+	return conn.CreateInBatches(dbTx, items, 2000)
+}
+
+func (conn *DBClient) GetTradesByTick(limit, offset int, chain, protocol, tick string) ([]*model.Trade, int64, error) {
+	var total int64
+	items := make([]*model.Trade, 0)
+	query := conn.SqlDB.Model(&model.Trade{}).Where("chain = ? AND protocol = ? AND tick = ?", chain, protocol, tick)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("id desc").Limit(limit).Offset(offset).Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// FindTradesByTime trades of the tick in [start, end), in order
+func (conn *DBClient) FindTradesByTime(chain, protocol, tick string, start, end time.Time) ([]*model.Trade, error) {
+	items := make([]*model.Trade, 0)
+	err := conn.SqlDB.Where("chain = ? AND protocol = ? AND tick = ? AND block_time >= ? AND block_time < ?", chain, protocol, tick, start, end).
+		Order("block_time asc, id asc").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SumTradesSince trade volume & the lowest unit price of the tick since the given time
+func (conn *DBClient) SumTradesSince(chain, protocol, tick string, since time.Time) (*model.TradeStats, error) {
+	query := conn.SqlDB.Model(&model.Trade{}).
+		Where("chain = ? AND protocol = ? AND tick = ? AND block_time >= ?", chain, protocol, tick, since)
+	if conn.isSqlite() {
+		return sumSqliteTrades(query)
+	}
+
+	item := &model.TradeStats{}
+	err := query.Select("COALESCE(SUM(price), 0) AS volume, COALESCE(SUM(amount), 0) AS amount, COUNT(*) AS trades, COALESCE(MIN(unit_price), 0) AS low_24h").
+		Scan(item).Error
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// FindLastTrade the latest trade of the tick
+func (conn *DBClient) FindLastTrade(chain, protocol, tick string) (*model.Trade, error) {
+	item := &model.Trade{}
+	err := conn.SqlDB.Where("chain = ? AND protocol = ? AND tick = ?", chain, protocol, tick).Order("id desc").First(item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+func (conn *DBClient) DeleteTradesFromBlock(dbTx *gorm.DB, chain string, blockNum uint64, tick string) error {
+	return dbTx.Scopes(tickScope(tick)).Where("chain = ? AND block_height >= ?", chain, blockNum).Delete(&model.Trade{}).Error
+}
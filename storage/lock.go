@@ -0,0 +1,179 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"gorm.io/gorm"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+)
+
+// Locker the global lock serializing the db writes of the indexers sharing the same db
+type Locker interface {
+	// TryLock acquire the lock without waiting, false if it is held by others
+	TryLock() (bool, error)
+
+	// Unlock release the lock acquired by TryLock
+	Unlock() error
+}
+
+// newLocker pick the lock implementation by the dialect of the db
+func newLocker(db *gorm.DB) Locker {
+	switch db.Dialector.Name() {
+	case DatabaseTypeMysql:
+		return NewMysqlLocker(db, DBSessionLockKey)
+	case DatabaseTypePostgres:
+		return NewPostgresLocker(db, DBSessionLockKey)
+	}
+
+	// sqlite locks the whole db file on writing, the indexers in one process are serialized here
+	return NewProcessLocker()
+}
+
+// processLocker lock shared by the indexers running in the same process
+type processLocker struct {
+	locked atomic.Bool
+}
+
+func NewProcessLocker() Locker {
+	return &processLocker{}
+}
+
+func (l *processLocker) TryLock() (bool, error) {
+	return l.locked.CompareAndSwap(false, true), nil
+}
+
+func (l *processLocker) Unlock() error {
+	l.locked.Store(false)
+	return nil
+}
+
+// sessionLocker
+/***************************************
+ * advisory locks are owned by the db session,
+ * so the connection acquiring the lock is taken out of the pool
+ * and kept till the lock released on the same connection.
+ ***************************************/
+type sessionLocker struct {
+	db        *gorm.DB
+	lockSql   string
+	unlockSql string
+	key       interface{}
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// NewMysqlLocker lock by mysql GET_LOCK / RELEASE_LOCK
+func NewMysqlLocker(db *gorm.DB, key string) Locker {
+	return &sessionLocker{
+		db:        db,
+		lockSql:   "SELECT GET_LOCK(?, 0)",
+		unlockSql: "SELECT RELEASE_LOCK(?)",
+		key:       key,
+	}
+}
+
+// NewPostgresLocker lock by postgres session level advisory lock, the key is hashed into the bigint lock id
+func NewPostgresLocker(db *gorm.DB, key string) Locker {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return &sessionLocker{
+		db:        db,
+		lockSql:   "SELECT CASE WHEN pg_try_advisory_lock($1) THEN 1 ELSE 0 END",
+		unlockSql: "SELECT CASE WHEN pg_advisory_unlock($1) THEN 1 ELSE 0 END",
+		key:       int64(h.Sum64()),
+	}
+}
+
+func (l *sessionLocker) TryLock() (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// held by another indexer of this process
+	if l.conn != nil {
+		return false, nil
+	}
+
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return false, err
+	}
+
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var locked sql.NullInt64
+	if err = conn.QueryRowContext(ctx, l.lockSql, l.key).Scan(&locked); err != nil {
+		l.discard(conn)
+		return false, err
+	}
+
+	if locked.Int64 < 1 {
+		_ = conn.Close()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+func (l *sessionLocker) Unlock() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+
+	conn := l.conn
+	l.conn = nil
+
+	var released sql.NullInt64
+	if err := conn.QueryRowContext(context.Background(), l.unlockSql, l.key).Scan(&released); err != nil {
+		// the lock is released by the db once the session closed
+		l.discard(conn)
+		return err
+	}
+
+	if released.Int64 < 1 {
+		l.discard(conn)
+		return errors.New("db lock is not held by the session")
+	}
+	return conn.Close()
+}
+
+// discard close the session instead of putting it back to the pool
+func (l *sessionLocker) discard(conn *sql.Conn) {
+	_ = conn.Raw(func(interface{}) error {
+		return driver.ErrBadConn
+	})
+	_ = conn.Close()
+}
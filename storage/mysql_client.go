@@ -35,8 +35,5 @@ func NewMysqlClient(cfg *config.DatabaseConfig, gormCfg *gorm.Config) (*DBClient
 		log.Error("connect to mysql failed", "err", err)
 		return nil, err
 	}
-	conn := &DBClient{
-		SqlDB: db,
-	}
-	return conn, nil
+	return WrapGormDB(db), nil
 }
@@ -27,10 +27,138 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/model"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+	"sort"
+	"strings"
 )
 
+// sqliteDialect dialect name of the gorm sqlite driver
+const sqliteDialect = "sqlite"
+
+// sqliteDialector
+/***************************************
+ * sqlite converts the decimal values into float64 & loses the precision,
+ * the decimal columns are created as text keeping the exact values.
+ ***************************************/
+type sqliteDialector struct {
+	sqlite.Dialector
+}
+
+func (d sqliteDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return sqlite.Migrator{Migrator: migrator.Migrator{Config: migrator.Config{
+		DB:                          db,
+		Dialector:                   d,
+		CreateIndexAfterCreateTable: true,
+	}}}
+}
+
+func (d sqliteDialector) DataTypeOf(field *schema.Field) string {
+	if strings.HasPrefix(strings.ToLower(string(field.DataType)), "decimal") {
+		return "text"
+	}
+	return d.Dialector.DataTypeOf(field)
+}
+
+func (conn *DBClient) isSqlite() bool {
+	return conn.SqlDB.Dialector.Name() == sqliteDialect
+}
+
+// numeric
+/***************************************
+ * the decimal column used in ORDER BY, the text decimals of sqlite
+ * are compared as strings, e.g. "9" > "10", they are cast to float64 there
+ ***************************************/
+func (conn *DBClient) numeric(column string) string {
+	if !conn.isSqlite() {
+		return column
+	}
+	return "CAST(" + column + " AS REAL)"
+}
+
+// sumSqliteBalancesByChain
+/***************************************
+ * sqlite sums the text decimals as float64, the balances of the address
+ * are added up by chain here, sorted by the sum like GROUP BY chain
+ ***************************************/
+func sumSqliteBalancesByChain(query *gorm.DB, limit, offset int) ([]*model.BalanceChain, int64, error) {
+	rows := make([]*model.Balances, 0)
+	if err := query.Order("id asc").Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]*model.BalanceChain, 0)
+	chains := make(map[string]*model.BalanceChain)
+	for _, row := range rows {
+		item, ok := chains[row.Chain]
+		if !ok {
+			item = &model.BalanceChain{Chain: row.Chain, Address: row.Address}
+			chains[row.Chain] = item
+			items = append(items, item)
+		}
+		item.Balance = item.Balance.Add(row.Balance)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Balance.GreaterThan(items[j].Balance)
+	})
+
+	// the matched balance rows are counted, as Count before GROUP BY
+	total := int64(len(rows))
+	if offset >= len(items) {
+		return []*model.BalanceChain{}, total, nil
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items, total, nil
+}
+
+// sumSqliteTickGas sqlite sums the text decimals as float64, the fees are added up by op here
+func sumSqliteTickGas(query *gorm.DB) ([]*model.TickGasTotal, error) {
+	rows := make([]*model.TickGas, 0)
+	if err := query.Order("id asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	items := make([]*model.TickGasTotal, 0)
+	ops := make(map[string]*model.TickGasTotal)
+	for _, row := range rows {
+		item, ok := ops[row.Op]
+		if !ok {
+			item = &model.TickGasTotal{Chain: row.Chain, Protocol: row.Protocol, Tick: row.Tick, Op: row.Op}
+			ops[row.Op] = item
+			items = append(items, item)
+		}
+		item.TxCnt += row.TxCnt
+		item.GasUsed += row.GasUsed
+		item.Fee = item.Fee.Add(row.Fee)
+	}
+	return items, nil
+}
+
+// sumSqliteTrades sqlite sums the text decimals as float64, the trades are added up here
+func sumSqliteTrades(query *gorm.DB) (*model.TradeStats, error) {
+	rows := make([]*model.Trade, 0)
+	if err := query.Order("id asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	item := &model.TradeStats{}
+	for _, row := range rows {
+		item.Volume = item.Volume.Add(row.Price)
+		item.Amount = item.Amount.Add(row.Amount)
+		if item.Trades == 0 || row.UnitPrice.LessThan(item.Low24h) {
+			item.Low24h = row.UnitPrice
+		}
+		item.Trades++
+	}
+	return item, nil
+}
+
 func NewSqliteClient(cfg *config.DatabaseConfig, gormCfg *gorm.Config) (*DBClient, error) {
 	if cfg == nil {
 		return nil, errors.New("invalid configuration file")
@@ -38,16 +166,80 @@ func NewSqliteClient(cfg *config.DatabaseConfig, gormCfg *gorm.Config) (*DBClien
 	if gormCfg == nil {
 		return nil, errors.New("invalid configuration file")
 	}
-	db, err := gorm.Open(sqlite.Open(cfg.Dsn), gormCfg)
+	db, err := gorm.Open(sqliteDialector{Dialector: sqlite.Dialector{DSN: cfg.Dsn}}, gormCfg)
 	if err != nil {
 		log.Error("connect to sqlite failed", "err", err)
 		return nil, err
 	}
 
-	conn := &DBClient{
-		SqlDB: db,
+	// sqlite allows only one writer, & every connection of an in-memory db opens a new empty db
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	if err = migrateSqlite(db); err != nil {
+		log.Error("create sqlite tables failed", "err", err)
+		return nil, err
 	}
 
 	log.Info("connect to sqlite success")
-	return conn, nil
+	return WrapGormDB(db), nil
+}
+
+// sqliteUniqueIndexes the unique keys of the mysql schema the writes rely on
+var sqliteUniqueIndexes = []string{
+	"CREATE UNIQUE INDEX IF NOT EXISTS uqx_inscriptions_chain_protocol_tick ON inscriptions (chain, protocol, tick)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS uqx_inscriptions_chain_sid ON inscriptions (chain, sid)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS uqx_inscriptions_stats_chain_protocol_tick ON inscriptions_stats (chain, protocol, tick)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS uqx_inscriptions_stats_chain_sid ON inscriptions_stats (chain, sid)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS uqx_txs_chain_tx_hash_sub_index ON txs (chain, tx_hash, sub_index)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS uqx_balances_address_chain_protocol_tick ON balances (address, chain, protocol, tick)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS uqx_balances_chain_sid ON balances (chain, sid)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS uqx_block_chain ON block (chain)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS uqx_ethscriptions_chain_ethscription_id ON ethscriptions (chain, ethscription_id)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS uqx_ethscriptions_chain_content_sha ON ethscriptions (chain, content_sha)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS uqx_listings_chain_list_id ON listings (chain, list_id)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS uqx_block_gas_chain_block ON block_gas (chain, block_height)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS uqx_tick_gas_chain_block_tick_op ON tick_gas (chain, block_height, protocol, tick, op)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS uqx_chain_stats_hour_chain_date_hour ON chain_stats_hour (chain, date_hour)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS uqx_chain_info_chain_id_chain_name ON chain_info (chain_id, chain, name)",
+}
+
+// migrateSqlite
+/***************************************
+ * create the tables from the models for local development & tests,
+ * the mysql schema is maintained by the sql files under db/
+ ***************************************/
+func migrateSqlite(db *gorm.DB) error {
+	err := db.AutoMigrate(
+		&model.Inscriptions{},
+		&model.InscriptionsStats{},
+		&model.Transaction{},
+		&model.Balances{},
+		&model.AddressTxs{},
+		&model.BalanceTxn{},
+		&model.UTXO{},
+		&model.BlockStatus{},
+		&model.Ethscription{},
+		&model.EthscriptionTransfer{},
+		&model.Listing{},
+		&model.Trade{},
+		&model.RejectedTx{},
+		&model.BlockGas{},
+		&model.TickGas{},
+		&model.ChainStatHour{},
+		&model.ChainInfo{},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range sqliteUniqueIndexes {
+		if err = db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,144 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package storage
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/model"
+	"testing"
+	"time"
+)
+
+func TestSqliteDecimalOrder(t *testing.T) {
+	db, err := NewDbClient(&config.DatabaseConfig{Type: DatabaseTypeSqlite3, Dsn: "file::memory:"})
+	assert.NoError(t, err)
+
+	// "9" < "10" only if compared as numbers
+	now := time.Now()
+	trades := make([]*model.Trade, 0, 2)
+	for idx, price := range []string{"10", "9"} {
+		trades = append(trades, &model.Trade{
+			Chain:     "avalanche",
+			Protocol:  "asc-20",
+			Tick:      "avav",
+			ListId:    price,
+			Amount:    decimal.NewFromInt(1),
+			Price:     decimal.RequireFromString(price),
+			UnitPrice: decimal.RequireFromString(price),
+			BlockTime: now.Add(time.Duration(idx) * time.Second),
+		})
+	}
+	assert.NoError(t, db.BatchAddTrades(db.SqlDB, trades))
+
+	stats, err := db.SumTradesSince("avalanche", "asc-20", "avav", now.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), stats.Trades)
+	assert.Equal(t, "19", stats.Volume.String())
+	assert.Equal(t, "9", stats.Low24h.String())
+
+	balances := []*model.Balances{
+		{Chain: "avalanche", Protocol: "asc-20", Tick: "avav", Address: "0xa1", SID: 1, Balance: decimal.NewFromInt(9), Available: decimal.NewFromInt(9)},
+		{Chain: "avalanche", Protocol: "asc-20", Tick: "avav", Address: "0xa2", SID: 2, Balance: decimal.NewFromInt(10), Available: decimal.NewFromInt(10)},
+	}
+	assert.NoError(t, db.SqlDB.Create(balances).Error)
+
+	holders, total, err := db.GetHoldersByTick(10, 0, "avalanche", "asc-20", "avav", OrderByModeDesc)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "0xa2", holders[0].Address)
+}
+
+func TestFindAddressTxsByHash(t *testing.T) {
+	db, err := NewDbClient(&config.DatabaseConfig{Type: DatabaseTypeSqlite3, Dsn: "file::memory:"})
+	assert.NoError(t, err)
+
+	hash := common.HexToHash("0x01")
+	txs := []*model.AddressTxs{
+		{Chain: "avalanche", TxHash: hash.Bytes(), SubIndex: 1, Address: "0xb1", Amount: decimal.NewFromInt(2)},
+		{Chain: "avalanche", TxHash: hash.Bytes(), SubIndex: 0, Address: "0xa1", Amount: decimal.NewFromInt(1)},
+		{Chain: "avalanche", TxHash: common.HexToHash("0x02").Bytes(), Address: "0xc1", Amount: decimal.NewFromInt(3)},
+	}
+	assert.NoError(t, db.SqlDB.Create(txs).Error)
+
+	items, err := db.FindAddressTxsByHash("avalanche", hash)
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+	assert.Equal(t, "0xa1", items[0].Address)
+	assert.Equal(t, "0xb1", items[1].Address)
+}
+
+func TestSqliteDecimalSum(t *testing.T) {
+	db, err := NewDbClient(&config.DatabaseConfig{Type: DatabaseTypeSqlite3, Dsn: "file::memory:"})
+	assert.NoError(t, err)
+
+	// 0.1 + 0.2 is 0.30000000000000004 if summed as floats
+	now := time.Now()
+	trades := make([]*model.Trade, 0, 2)
+	for _, price := range []string{"0.1", "0.2"} {
+		trades = append(trades, &model.Trade{
+			Chain:     "avalanche",
+			Protocol:  "asc-20",
+			Tick:      "avav",
+			ListId:    price,
+			Amount:    decimal.RequireFromString(price),
+			Price:     decimal.RequireFromString(price),
+			UnitPrice: decimal.NewFromInt(1),
+			BlockTime: now,
+		})
+	}
+	assert.NoError(t, db.BatchAddTrades(db.SqlDB, trades))
+
+	stats, err := db.SumTradesSince("avalanche", "asc-20", "avav", now.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, "0.3", stats.Volume.String())
+	assert.Equal(t, "0.3", stats.Amount.String())
+
+	gas := []*model.TickGas{
+		{Chain: "avalanche", Protocol: "asc-20", Tick: "avav", Op: "mint", BlockHeight: 1, TxCnt: 1, GasUsed: 10, Fee: decimal.RequireFromString("0.1")},
+		{Chain: "avalanche", Protocol: "asc-20", Tick: "avav", Op: "mint", BlockHeight: 2, TxCnt: 2, GasUsed: 20, Fee: decimal.RequireFromString("0.2")},
+	}
+	assert.NoError(t, db.SqlDB.Create(gas).Error)
+
+	totals, err := db.SumTickGas("avalanche", "asc-20", "avav", "")
+	assert.NoError(t, err)
+	assert.Len(t, totals, 1)
+	assert.Equal(t, uint64(3), totals[0].TxCnt)
+	assert.Equal(t, "0.3", totals[0].Fee.String())
+
+	balances := []*model.Balances{
+		{Chain: "avalanche", Protocol: "asc-20", Tick: "avav", Address: "0xa1", SID: 1, Balance: decimal.RequireFromString("0.1")},
+		{Chain: "avalanche", Protocol: "asc-20", Tick: "avax", Address: "0xa1", SID: 2, Balance: decimal.RequireFromString("0.2")},
+		{Chain: "eth", Protocol: "erc-20", Tick: "eths", Address: "0xa1", SID: 3, Balance: decimal.NewFromInt(1)},
+	}
+	assert.NoError(t, db.SqlDB.Create(balances).Error)
+
+	chains, total, err := db.GetBalancesChainByAddress(10, 0, "0xa1", "", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Len(t, chains, 2)
+	assert.Equal(t, "eth", chains[0].Chain)
+	assert.Equal(t, "0.3", chains[1].Balance.String())
+}
@@ -31,6 +31,11 @@ var (
 	ErrInvalidData        = NewInsError(-100, "invalid data")
 	ErrDataVerifiedFailed = NewInsError(-102, "data verified failed")
 	ErrInternal           = NewInsError(-500, "internal error")
+	ErrMintCompleted      = NewInsError(-20, "mint completed")
+	ErrAmountPrecision    = NewInsError(-21, "amount precision exceeds tick decimals")
+	ErrAmountOutOfRange   = NewInsError(-22, "amount out of range")
+	ErrMintNotSelfSent    = NewInsError(-23, "mint tx is not self-sent")
+	ErrMintExceedsSupply  = NewInsError(-24, "mint amount exceeds supply left")
 )
 
 type InsError struct {